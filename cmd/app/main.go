@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"hr-server/config"
 	"hr-server/internal/app"
+	"os"
+	_ "time/tzdata" // export and recipient timezones in the alpine image
 
 	"github.com/sirupsen/logrus"
 )
@@ -15,15 +17,35 @@ func main() {
 }
 
 func run() error {
-	cfg, err := config.LoadConfig()
+	// "server migrate ..." manages the database schema and "server apikey create ..." creates an API key,
+	// e.g. the first one with api_keys:manage, instead of running the service
+	command := config.CommandServe
+	if len(os.Args) > 1 && (os.Args[1] == config.CommandMigrate || os.Args[1] == config.CommandAPIKey) {
+		command = os.Args[1]
+	}
+
+	cfg, err := config.LoadConfig(command)
 	if err != nil {
 		logrus.Error(err)
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	if err := app.Run(cfg); err != nil {
-		logrus.Error(err)
-		return fmt.Errorf("failed to run app: %w", err)
+	switch command {
+	case config.CommandMigrate:
+		if err := app.Migrate(cfg, os.Args[2:]); err != nil {
+			logrus.Error(err)
+			return fmt.Errorf("failed to migrate: %w", err)
+		}
+	case config.CommandAPIKey:
+		if err := app.APIKey(cfg, os.Args[2:]); err != nil {
+			logrus.Error(err)
+			return fmt.Errorf("failed to manage API keys: %w", err)
+		}
+	default:
+		if err := app.Run(cfg); err != nil {
+			logrus.Error(err)
+			return fmt.Errorf("failed to run app: %w", err)
+		}
 	}
 
 	return nil
@@ -1,52 +1,92 @@
 package config
 
-import (
-	"os"
+import "time"
+
+// Commands of the server binary, the settings they don't use aren't required
+const (
+	CommandServe   = "serve"
+	CommandMigrate = "migrate"
+	CommandAPIKey  = "apikey"
 )
 
+// Config is loaded from defaults, an optional YAML file and environment variables, in this order.
+//
+// Field tags:
+//   - env: environment variable, also read from the file named by <env>_FILE for Docker secrets
+//   - yaml: key in the CONFIG_FILE file
+//   - default: value used when neither the file nor the environment sets the field
+//   - required: the field must not be empty, "true" for every command or the commands needing it, e.g. "serve"
+//   - secret: the value is masked in the config dump
 type Config struct {
-	Environment string
+	Environment string `env:"ENVIRONMENT" yaml:"environment" default:"development"`
 
-	AuthToken string
+	AuthToken string `env:"AUTH_TOKEN" yaml:"auth_token" secret:"true"`
 
-	TgBot struct {
-		Token string
-		URL   string
-	}
+	Admin struct {
+		JWTSecret       string        `env:"ADMIN_JWT_SECRET" yaml:"jwt_secret" secret:"true"`
+		AccessTokenTTL  time.Duration `env:"ADMIN_ACCESS_TOKEN_TTL" yaml:"access_token_ttl" default:"15m"`
+		RefreshTokenTTL time.Duration `env:"ADMIN_REFRESH_TOKEN_TTL" yaml:"refresh_token_ttl" default:"720h"`
+	} `yaml:"admin"`
 
-	Postgres struct {
-		HOST, PORT, USER, PASSWORD, DB, SSLMODE string
-	}
+	TgBot struct {
+		Token string `env:"TG_BOT_TOKEN" yaml:"token" required:"serve" secret:"true"`
+		URL   string `env:"TG_BOT_URL" yaml:"url" required:"serve"` // bot link the channel codes are appended to, e.g. https://t.me/hr_bot
 
-	Http struct {
-		Port string
-	}
+		CodeExpiredMessage string `env:"TG_BOT_CODE_EXPIRED_MESSAGE" yaml:"code_expired_message"`
+		CodeFullMessage    string `env:"TG_BOT_CODE_FULL_MESSAGE" yaml:"code_full_message"`
+		WelcomeMessage     string `env:"TG_BOT_WELCOME_MESSAGE" yaml:"welcome_message"`
+		WelcomeBackMessage string `env:"TG_BOT_WELCOME_BACK_MESSAGE" yaml:"welcome_back_message"`
+	} `yaml:"tg_bot"`
 
-	Logger struct {
-		LOGLVL string
-	}
-}
+	Postgres struct {
+		HOST     string `env:"POSTGRES_HOST" yaml:"host" default:"localhost"`
+		PORT     string `env:"POSTGRES_PORT" yaml:"port" default:"5432"`
+		USER     string `env:"POSTGRES_USER" yaml:"user" required:"true"`
+		PASSWORD string `env:"POSTGRES_PASSWORD" yaml:"password" required:"true" secret:"true"`
+		DB       string `env:"POSTGRES_DB" yaml:"db" default:"hr_server"`
+		SSLMODE  string `env:"POSTGRES_SSLMODE" yaml:"sslmode" default:"prefer"`
 
-func LoadConfig() (*Config, error) {
-	cfg := Config{}
+		MigrateOnStart bool          `env:"MIGRATE_ON_START" yaml:"migrate_on_start"`                 // apply pending migrations on start instead of failing
+		QueryTimeout   time.Duration `env:"POSTGRES_QUERY_TIMEOUT" yaml:"query_timeout" default:"5s"` // limit of each repository call of requests and bot updates
+	} `yaml:"postgres"`
 
-	cfg.Postgres.HOST = os.Getenv("POSTGRES_HOST")
-	cfg.Postgres.PORT = os.Getenv("POSTGRES_PORT")
-	cfg.Postgres.USER = os.Getenv("POSTGRES_USER")
-	cfg.Postgres.PASSWORD = os.Getenv("POSTGRES_PASSWORD")
-	cfg.Postgres.DB = os.Getenv("POSTGRES_DB")
-	cfg.Postgres.SSLMODE = os.Getenv("POSTGRES_SSLMODE")
+	Notifications struct {
+		WorkerCount     int           `env:"NOTIFICATIONS_WORKER_COUNT" yaml:"worker_count" default:"5"`             // workers shared by all broadcasts
+		BatchSize       int           `env:"NOTIFICATIONS_BATCH_SIZE" yaml:"batch_size" default:"20"`                // users loaded at once, also the capacity of each priority queue
+		MessageInterval time.Duration `env:"NOTIFICATIONS_MESSAGE_INTERVAL" yaml:"message_interval" default:"100ms"` // pause of each worker after a message
+	} `yaml:"notifications"`
 
-	cfg.Logger.LOGLVL = os.Getenv("LOGL")
+	Drip struct {
+		PollInterval time.Duration `env:"DRIP_POLL_INTERVAL" yaml:"poll_interval" default:"1m"` // how often the due drip steps are sent
+		BatchSize    int           `env:"DRIP_BATCH_SIZE" yaml:"batch_size" default:"100"`      // due enrollments loaded at once
+	} `yaml:"drip"`
 
-	cfg.Http.Port = os.Getenv("HTTP_PORT")
+	// Quiet hours in the local time of the recipients, the broadcasts with the local_window delivery are held during them
+	QuietHours struct {
+		Start           string `env:"QUIET_HOURS_START" yaml:"start" default:"21:00"`                   // HH:MM
+		End             string `env:"QUIET_HOURS_END" yaml:"end" default:"09:00"`                       // HH:MM, equal to the start disables the quiet hours
+		DefaultTimezone string `env:"DEFAULT_TIMEZONE" yaml:"default_timezone" default:"Europe/Moscow"` // of the users without an own or a channel timezone
+	} `yaml:"quiet_hours"`
 
-	cfg.Environment = os.Getenv("ENVIRONMENT")
+	// Tracking of the broadcast links, enabled when both are set
+	Tracking struct {
+		BaseURL string `env:"TRACKING_BASE_URL" yaml:"base_url"`           // public URL of this server, the tracked links are <base_url>/r/<token>
+		Secret  string `env:"TRACKING_SECRET" yaml:"secret" secret:"true"` // key signing the tokens of the tracked links
+	} `yaml:"tracking"`
 
-	cfg.AuthToken = os.Getenv("AUTH_TOKEN")
+	Http struct {
+		Port string `env:"HTTP_PORT" yaml:"port" default:"8080"`
+	} `yaml:"http"`
 
-	cfg.TgBot.Token = os.Getenv("TG_BOT_TOKEN")
-	cfg.TgBot.URL = os.Getenv("TG_BOT_URL")
+	Logger struct {
+		LOGLVL string `env:"LOGL" yaml:"level" default:"info"`
+		Format string `env:"LOG_FORMAT" yaml:"format" default:"text"` // text or json
 
-	return &cfg, nil
+		// JSON fields of request bodies whose values are masked
+		RedactFields []string `env:"LOG_REDACT_FIELDS" yaml:"redact_fields" default:"password,token,refresh_token,hash,message,username,first_name,last_name,photo_url"`
+		// request headers whose values are masked
+		MaskHeaders  []string `env:"LOG_MASK_HEADERS" yaml:"mask_headers" default:"X-Auth-Token,Authorization,Cookie"`
+		MaxBodySize  int      `env:"LOG_MAX_BODY_SIZE" yaml:"max_body_size" default:"4096"` // bodies over this size are logged by their size only
+		MetadataOnly bool     `env:"LOG_METADATA_ONLY" yaml:"metadata_only"`                // log only the method, path, status, latency and client IP of requests
+	} `yaml:"logger"`
 }
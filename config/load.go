@@ -0,0 +1,300 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv is the environment variable with the path to an optional YAML config file
+const FileEnv = "CONFIG_FILE"
+
+// ClockFormat is the format of the times of day, e.g. the quiet hours
+const ClockFormat = "15:04"
+
+// secretMask replaces the values of secret fields in the config dump
+const secretMask = "***"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// LoadConfig applies the defaults, the CONFIG_FILE file, the environment and the <env>_FILE secrets
+// in this order and validates the result for the command
+func LoadConfig(command string) (*Config, error) {
+	cfg := Config{}
+
+	if err := walkFields(&cfg, func(field reflect.Value, tag reflect.StructTag) error {
+		value, ok := tag.Lookup("default")
+		if !ok {
+			return nil
+		}
+		return setField(field, value)
+	}); err != nil {
+		return nil, fmt.Errorf("can't set defaults: %w", err)
+	}
+
+	if path := os.Getenv(FileEnv); path != "" {
+		if err := loadFile(&cfg, path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := walkFields(&cfg, loadEnv); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(command); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+
+	return &cfg, nil
+}
+
+func loadFile(cfg *Config, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("can't open config file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(cfg); err != nil {
+		return fmt.Errorf("can't parse config file \"%s\": %w", path, err)
+	}
+
+	return nil
+}
+
+// loadEnv sets the field from its environment variable or from the file named by <env>_FILE
+func loadEnv(field reflect.Value, tag reflect.StructTag) error {
+	key := tag.Get("env")
+	if key == "" {
+		return nil
+	}
+
+	value, isSet := os.LookupEnv(key)
+	path, isFileSet := os.LookupEnv(key + "_FILE")
+
+	switch {
+	case isSet && isFileSet:
+		return fmt.Errorf("only one of \"%s\" and \"%s_FILE\" can be set", key, key)
+	case isFileSet:
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("can't read \"%s_FILE\": %w", key, err)
+		}
+		value = strings.TrimRight(string(content), "\r\n")
+	case !isSet || value == "":
+		return nil
+	}
+
+	if err := setField(field, value); err != nil {
+		return fmt.Errorf("can't parse \"%s\": %w", key, err)
+	}
+
+	return nil
+}
+
+// setField parses the value into the field, lists are comma-separated and "-" sets an empty list
+func setField(field reflect.Value, value string) error {
+	switch {
+	case field.Type() == durationType:
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+	case field.Kind() == reflect.Int:
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(number))
+	case field.Kind() == reflect.Bool:
+		flag, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(flag)
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		list := []string{}
+		if value != "-" {
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					list = append(list, item)
+				}
+			}
+		}
+		field.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+
+	return nil
+}
+
+// walkFields calls fn for every leaf field of the config, nested structs are walked recursively
+func walkFields(cfg *Config, fn func(field reflect.Value, tag reflect.StructTag) error) error {
+	var walk func(value reflect.Value) error
+	walk = func(value reflect.Value) error {
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Field(i)
+			structField := value.Type().Field(i)
+
+			if field.Kind() == reflect.Struct && field.Type() != durationType {
+				if err := walk(field); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := fn(field, structField.Tag); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return walk(reflect.ValueOf(cfg).Elem())
+}
+
+// Redacted returns the config keyed by environment variables with the secrets masked, to be logged at startup
+func (c *Config) Redacted() map[string]any {
+	dump := map[string]any{}
+
+	cfg := *c
+	_ = walkFields(&cfg, func(field reflect.Value, tag reflect.StructTag) error {
+		key := tag.Get("env")
+		if key == "" {
+			return nil
+		}
+
+		switch {
+		case tag.Get("secret") == "true" && !field.IsZero():
+			dump[key] = secretMask
+		case field.Type() == durationType:
+			dump[key] = time.Duration(field.Int()).String()
+		default:
+			dump[key] = field.Interface()
+		}
+		return nil
+	})
+
+	return dump
+}
+
+// Validate reports every invalid field at once, the fields required by other commands may be empty
+func (c *Config) Validate(command string) error {
+	var errs []error
+
+	cfg := *c
+	_ = walkFields(&cfg, func(field reflect.Value, tag reflect.StructTag) error {
+		required := tag.Get("required")
+		if field.IsZero() && (required == "true" || slices.Contains(strings.Split(required, ","), command)) {
+			errs = append(errs, fmt.Errorf("\"%s\" is required", tag.Get("env")))
+		}
+		return nil
+	})
+
+	errs = append(errs,
+		validatePort("HTTP_PORT", c.Http.Port),
+		validatePort("POSTGRES_PORT", c.Postgres.PORT),
+		validateOneOf("POSTGRES_SSLMODE", c.Postgres.SSLMODE, "disable", "allow", "prefer", "require", "verify-ca", "verify-full"),
+		validateOneOf("LOGL", c.Logger.LOGLVL, "trace", "debug", "info", "warn", "warning", "error", "fatal", "panic"),
+		validateOneOf("LOG_FORMAT", c.Logger.Format, "text", "json"),
+		validateMinLength("AUTH_TOKEN", c.AuthToken, 16),
+		validateMinLength("ADMIN_JWT_SECRET", c.Admin.JWTSecret, 32),
+		validateMinLength("TRACKING_SECRET", c.Tracking.Secret, 32),
+	)
+
+	if c.TgBot.URL != "" {
+		// the channel codes are appended as the query
+		if u, err := url.Parse(c.TgBot.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+			errs = append(errs, fmt.Errorf("\"TG_BOT_URL\" must be an http(s) URL without a query, got \"%s\"", c.TgBot.URL))
+		}
+	}
+
+	if c.Tracking.BaseURL != "" {
+		if u, err := url.Parse(c.Tracking.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("\"TRACKING_BASE_URL\" must be an http(s) URL, got \"%s\"", c.Tracking.BaseURL))
+		}
+		if c.Tracking.Secret == "" {
+			errs = append(errs, errors.New("\"TRACKING_SECRET\" is required with \"TRACKING_BASE_URL\""))
+		}
+	}
+
+	if c.Logger.MaxBodySize <= 0 {
+		errs = append(errs, errors.New("\"LOG_MAX_BODY_SIZE\" must be positive"))
+	}
+	if c.Postgres.QueryTimeout <= 0 {
+		errs = append(errs, errors.New("\"POSTGRES_QUERY_TIMEOUT\" must be positive"))
+	}
+	if c.Notifications.WorkerCount <= 0 {
+		errs = append(errs, errors.New("\"NOTIFICATIONS_WORKER_COUNT\" must be positive"))
+	}
+	if c.Notifications.BatchSize <= 0 {
+		errs = append(errs, errors.New("\"NOTIFICATIONS_BATCH_SIZE\" must be positive"))
+	}
+	if c.Notifications.MessageInterval < 0 {
+		errs = append(errs, errors.New("\"NOTIFICATIONS_MESSAGE_INTERVAL\" must not be negative"))
+	}
+	if c.Drip.PollInterval <= 0 {
+		errs = append(errs, errors.New("\"DRIP_POLL_INTERVAL\" must be positive"))
+	}
+	if c.Drip.BatchSize <= 0 {
+		errs = append(errs, errors.New("\"DRIP_BATCH_SIZE\" must be positive"))
+	}
+	if _, err := time.Parse(ClockFormat, c.QuietHours.Start); err != nil {
+		errs = append(errs, fmt.Errorf("\"QUIET_HOURS_START\" must be a time like 21:00, got \"%s\"", c.QuietHours.Start))
+	}
+	if _, err := time.Parse(ClockFormat, c.QuietHours.End); err != nil {
+		errs = append(errs, fmt.Errorf("\"QUIET_HOURS_END\" must be a time like 09:00, got \"%s\"", c.QuietHours.End))
+	}
+	if _, err := time.LoadLocation(c.QuietHours.DefaultTimezone); err != nil || c.QuietHours.DefaultTimezone == "Local" {
+		errs = append(errs, fmt.Errorf("\"DEFAULT_TIMEZONE\" must be an IANA timezone name, got \"%s\"", c.QuietHours.DefaultTimezone))
+	}
+	if c.Admin.AccessTokenTTL <= 0 {
+		errs = append(errs, errors.New("\"ADMIN_ACCESS_TOKEN_TTL\" must be positive"))
+	}
+	if c.Admin.RefreshTokenTTL <= c.Admin.AccessTokenTTL {
+		errs = append(errs, errors.New("\"ADMIN_REFRESH_TOKEN_TTL\" must be longer than \"ADMIN_ACCESS_TOKEN_TTL\""))
+	}
+
+	return errors.Join(errs...)
+}
+
+func validatePort(key, value string) error {
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("\"%s\" must be a port number, got \"%s\"", key, value)
+	}
+	return nil
+}
+
+func validateOneOf(key, value string, allowed ...string) error {
+	for _, item := range allowed {
+		if strings.EqualFold(value, item) {
+			return nil
+		}
+	}
+	return fmt.Errorf("\"%s\" must be one of %s, got \"%s\"", key, strings.Join(allowed, ", "), value)
+}
+
+// validateMinLength checks optional secrets, an empty value disables the feature
+func validateMinLength(key, value string, length int) error {
+	if value != "" && len(value) < length {
+		return fmt.Errorf("\"%s\" must be at least %d characters long", key, length)
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+package config_test
+
+import (
+	"fmt"
+	"hr-server/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clearEnv unsets every config variable and its _FILE variant for the test, so the environment of the runner doesn't leak in
+func clearEnv(t *testing.T) {
+	t.Helper()
+
+	keys := []string{config.FileEnv}
+	for key := range (&config.Config{}).Redacted() {
+		keys = append(keys, key, key+"_FILE")
+	}
+
+	for _, key := range keys {
+		t.Setenv(key, "") // restores the value after the test
+		require.NoError(t, os.Unsetenv(key))
+	}
+}
+
+// writeFile writes the content to a file in the temporary directory of the test and returns its path
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	required := map[string]string{
+		"POSTGRES_USER":     "user",
+		"POSTGRES_PASSWORD": "password",
+		"TG_BOT_TOKEN":      "123456:token",
+		"TG_BOT_URL":        "https://t.me/hr_bot",
+	}
+
+	tests := []struct {
+		name    string
+		command string
+		env     map[string]string
+		file    string // content of the CONFIG_FILE file
+		err     string
+		check   func(t *testing.T, cfg *config.Config)
+	}{
+		{
+			name: "defaults",
+			env:  required,
+			check: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, "development", cfg.Environment)
+				assert.Equal(t, "8080", cfg.Http.Port)
+				assert.Equal(t, "localhost", cfg.Postgres.HOST)
+				assert.Equal(t, 5*time.Second, cfg.Postgres.QueryTimeout)
+				assert.Equal(t, 100*time.Millisecond, cfg.Notifications.MessageInterval)
+				assert.Equal(t, []string{"X-Auth-Token", "Authorization", "Cookie"}, cfg.Logger.MaskHeaders)
+				assert.Equal(t, "21:00", cfg.QuietHours.Start)
+				assert.Empty(t, cfg.AuthToken)
+			},
+		},
+		{
+			name: "file",
+			env:  required,
+			file: "http:\n  port: \"9090\"\npostgres:\n  host: db\n  query_timeout: 2s\nlogger:\n  mask_headers: [Authorization]\n",
+			check: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, "9090", cfg.Http.Port)
+				assert.Equal(t, "db", cfg.Postgres.HOST)
+				assert.Equal(t, 2*time.Second, cfg.Postgres.QueryTimeout)
+				assert.Equal(t, []string{"Authorization"}, cfg.Logger.MaskHeaders)
+				assert.Equal(t, "hr_server", cfg.Postgres.DB, "the defaults of the fields missing in the file are kept")
+			},
+		},
+		{
+			name: "environment overrides file",
+			env:  with(required, "HTTP_PORT", "7070", "LOG_MASK_HEADERS", "-"),
+			file: "http:\n  port: \"9090\"\n",
+			check: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, "7070", cfg.Http.Port)
+				assert.Equal(t, []string{}, cfg.Logger.MaskHeaders, "\"-\" sets an empty list")
+			},
+		},
+		{
+			name: "empty environment variable keeps file value",
+			env:  with(required, "HTTP_PORT", ""),
+			file: "http:\n  port: \"9090\"\n",
+			check: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, "9090", cfg.Http.Port)
+			},
+		},
+		{
+			name: "unknown file key",
+			env:  required,
+			file: "http:\n  prot: \"9090\"\n",
+			err:  "field prot not found",
+		},
+		{
+			name: "secret file",
+			env:  with(without(required, "POSTGRES_PASSWORD"), "POSTGRES_PASSWORD_FILE", "secret from file\n"),
+			check: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, "secret from file", cfg.Postgres.PASSWORD, "the trailing newline is trimmed")
+			},
+		},
+		{
+			name: "secret file and variable",
+			env:  with(required, "POSTGRES_PASSWORD_FILE", "secret from file"),
+			err:  "only one of \"POSTGRES_PASSWORD\" and \"POSTGRES_PASSWORD_FILE\" can be set",
+		},
+		{
+			name: "invalid duration",
+			env:  with(required, "POSTGRES_QUERY_TIMEOUT", "5"),
+			err:  "can't parse \"POSTGRES_QUERY_TIMEOUT\"",
+		},
+		{
+			name: "bot settings are required to serve",
+			env:  with(required, "TG_BOT_TOKEN", "", "TG_BOT_URL", ""),
+			err:  "\"TG_BOT_TOKEN\" is required\n\"TG_BOT_URL\" is required",
+		},
+		{
+			name:    "bot settings aren't required to migrate",
+			command: config.CommandMigrate,
+			env:     with(required, "TG_BOT_TOKEN", "", "TG_BOT_URL", ""),
+			check: func(t *testing.T, cfg *config.Config) {
+				assert.Empty(t, cfg.TgBot.Token)
+			},
+		},
+		{
+			name:    "database settings are required to create API keys",
+			command: config.CommandAPIKey,
+			env:     with(required, "POSTGRES_USER", ""),
+			err:     "\"POSTGRES_USER\" is required",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clearEnv(t)
+
+			for key, value := range test.env {
+				if strings.HasSuffix(key, "_FILE") {
+					value = writeFile(t, "secret", value)
+				}
+				t.Setenv(key, value)
+			}
+			if test.file != "" {
+				t.Setenv(config.FileEnv, writeFile(t, "config.yaml", test.file))
+			}
+
+			command := test.command
+			if command == "" {
+				command = config.CommandServe
+			}
+
+			cfg, err := config.LoadConfig(command)
+			if test.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.err)
+				return
+			}
+			require.NoError(t, err)
+			test.check(t, cfg)
+		})
+	}
+}
+
+// with returns a copy of the environment with the key-value pairs set
+func with(env map[string]string, pairs ...string) map[string]string {
+	result := make(map[string]string, len(env)+len(pairs)/2)
+	for key, value := range env {
+		result[key] = value
+	}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		result[pairs[i]] = pairs[i+1]
+	}
+
+	return result
+}
+
+// without returns a copy of the environment without the keys
+func without(env map[string]string, keys ...string) map[string]string {
+	result := with(env)
+	for _, key := range keys {
+		delete(result, key)
+	}
+
+	return result
+}
+
+// validConfig loads a valid config with the defaults and the required settings
+func validConfig(t *testing.T) *config.Config {
+	t.Helper()
+
+	clearEnv(t)
+	t.Setenv("POSTGRES_USER", "user")
+	t.Setenv("POSTGRES_PASSWORD", "password")
+	t.Setenv("TG_BOT_TOKEN", "123456:token")
+	t.Setenv("TG_BOT_URL", "https://t.me/hr_bot")
+
+	cfg, err := config.LoadConfig(config.CommandServe)
+	require.NoError(t, err)
+
+	return cfg
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *config.Config)
+		errs   []string // every error is reported, in this order
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *config.Config) {},
+		},
+		{
+			name: "several invalid fields",
+			modify: func(cfg *config.Config) {
+				cfg.Postgres.USER = ""
+				cfg.Http.Port = "http"
+				cfg.Logger.Format = "xml"
+				cfg.AuthToken = "short"
+			},
+			errs: []string{
+				"\"POSTGRES_USER\" is required",
+				"\"HTTP_PORT\" must be a port number, got \"http\"",
+				"\"LOG_FORMAT\" must be one of text, json, got \"xml\"",
+				"\"AUTH_TOKEN\" must be at least 16 characters long",
+			},
+		},
+		{
+			name:   "bot URL without scheme",
+			modify: func(cfg *config.Config) { cfg.TgBot.URL = "t.me/hr_bot" },
+			errs:   []string{"\"TG_BOT_URL\" must be an http(s) URL without a query, got \"t.me/hr_bot\""},
+		},
+		{
+			name:   "bot URL with query",
+			modify: func(cfg *config.Config) { cfg.TgBot.URL = "https://t.me/hr_bot?start=abc" },
+			errs:   []string{"\"TG_BOT_URL\" must be an http(s) URL without a query, got \"https://t.me/hr_bot?start=abc\""},
+		},
+		{
+			name:   "tracking without secret",
+			modify: func(cfg *config.Config) { cfg.Tracking.BaseURL = "https://go.example.com" },
+			errs:   []string{"\"TRACKING_SECRET\" is required with \"TRACKING_BASE_URL\""},
+		},
+		{
+			name: "quiet hours and token TTLs",
+			modify: func(cfg *config.Config) {
+				cfg.QuietHours.Start = "9pm"
+				cfg.QuietHours.DefaultTimezone = "Local"
+				cfg.Admin.RefreshTokenTTL = cfg.Admin.AccessTokenTTL
+			},
+			errs: []string{
+				"\"QUIET_HOURS_START\" must be a time like 21:00, got \"9pm\"",
+				"\"DEFAULT_TIMEZONE\" must be an IANA timezone name, got \"Local\"",
+				"\"ADMIN_REFRESH_TOKEN_TTL\" must be longer than \"ADMIN_ACCESS_TOKEN_TTL\"",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := validConfig(t)
+			test.modify(cfg)
+
+			err := cfg.Validate(config.CommandServe)
+			if len(test.errs) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, strings.Join(test.errs, "\n"), err.Error())
+		})
+	}
+}
+
+func TestConfig_Redacted(t *testing.T) {
+	cfg := validConfig(t)
+	cfg.Admin.JWTSecret = "admin-jwt-secret-0123456789abcdef"
+
+	dump := cfg.Redacted()
+
+	tests := []struct {
+		key  string
+		want any
+	}{
+		{"TG_BOT_TOKEN", "***"},
+		{"POSTGRES_PASSWORD", "***"},
+		{"ADMIN_JWT_SECRET", "***"},
+		{"AUTH_TOKEN", ""}, // empty secrets show that they are unset
+		{"POSTGRES_USER", "user"},
+		{"TG_BOT_URL", "https://t.me/hr_bot"},
+		{"POSTGRES_QUERY_TIMEOUT", "5s"},
+		{"NOTIFICATIONS_WORKER_COUNT", 5},
+		{"LOG_MASK_HEADERS", []string{"X-Auth-Token", "Authorization", "Cookie"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.key, func(t *testing.T) {
+			assert.Equal(t, test.want, dump[test.key])
+		})
+	}
+
+	for key, value := range dump {
+		assert.NotContains(t, fmt.Sprint(value), "123456:token", "%s holds the bot token", key)
+	}
+}
@@ -0,0 +1,133 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"hr-server/internal/api/http/controllers/admin/dto"
+	"hr-server/internal/api/http/controllers/common"
+	"hr-server/internal/api/http/middleware"
+	"hr-server/internal/logger"
+	"hr-server/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminController struct {
+	adminService *service.AdminService
+}
+
+func NewAdminController(adminService *service.AdminService) *AdminController {
+	return &AdminController{adminService}
+}
+
+// CreateAdmin godoc
+// @Summary Create an admin
+// @Description Create an admin account with a password and/or a Telegram ID for the Telegram Login Widget
+// @Tags Admins
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateAdminRequest true "Create admin request"
+// @Success 200 {object} domain.Admin
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 403 {object} common.ErrorResponse
+// @Failure 409 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Security BearerAuth
+// @Router /admins [post]
+func (c *AdminController) CreateAdminHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		req := dto.NewCreateAdminRequest()
+		if err := req.Parse(ctx); err != nil {
+			logger.FromContext(ctx).Error("unable to parse a request: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			logger.FromContext(ctx).Error("error of validation: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		admin, err := c.adminService.CreateAdmin(ctx, req.Username, req.Password, req.TelegramID, req.Scopes, middleware.ActorScopes(ctx))
+		if errors.Is(err, service.ErrScopeNotHeld) {
+			ctx.JSON(http.StatusForbidden, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrAdminExists) {
+			ctx.JSON(http.StatusConflict, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error("error while create admin: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to create admin: %v", err)})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, admin)
+	}
+}
+
+// GetAdmins godoc
+// @Summary Get all admins
+// @Description Get all admin accounts including disabled ones
+// @Tags Admins
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.GetAdminsResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Security BearerAuth
+// @Router /admins [get]
+func (c *AdminController) GetAdminsHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		admins, err := c.adminService.GetAll(ctx)
+		if err != nil {
+			logger.FromContext(ctx).Error("error while get all admins: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to get all admins: %v", err)})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, dto.NewGetAdminsResponse(admins))
+	}
+}
+
+// DisableAdmin godoc
+// @Summary Disable an admin
+// @Description Disable an admin account and revoke its refresh tokens
+// @Tags Admins
+// @Accept json
+// @Produce json
+// @Param id path int true "Admin ID"
+// @Success 200 {object} common.SuccessResponse
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Security BearerAuth
+// @Router /admins/{id} [delete]
+func (c *AdminController) DisableAdminHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil || id <= 0 {
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: "id must be a positive integer"})
+			return
+		}
+
+		err = c.adminService.DisableAdmin(ctx, id)
+		if errors.Is(err, service.ErrAdminNotFound) {
+			ctx.JSON(http.StatusNotFound, common.ErrorResponse{Error: "admin not found"})
+			return
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error("error while disable admin: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to disable admin %d: %v", id, err)})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, common.SuccessResponse{})
+	}
+}
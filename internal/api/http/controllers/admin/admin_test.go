@@ -0,0 +1,136 @@
+package admin_test
+
+import (
+	"hr-server/internal/api/http/controllers/admin"
+	"hr-server/internal/api/http/controllers/admin/dto"
+	"hr-server/internal/api/http/controllers/controllertest"
+	"hr-server/internal/api/http/middleware"
+	"hr-server/internal/domain"
+	"hr-server/internal/service/servicetest"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRouter(t *testing.T) (*gin.Engine, *servicetest.Services) {
+	t.Helper()
+
+	s := servicetest.NewServices(servicetest.Config())
+	controller := admin.NewAdminController(s.Admin)
+
+	// the requests are made with an API key holding the scopes of the admins created by the tests
+	router := controllertest.NewRouter()
+	router.Use(func(c *gin.Context) {
+		c.Set(middleware.APIKeyContextKey, &domain.APIKey{ID: 1, Name: "hr-panel", Scopes: []string{domain.ScopeAdminsManage, domain.ScopeUsersRead}})
+	})
+	router.POST("/admins", controller.CreateAdminHandler())
+	router.GET("/admins", controller.GetAdminsHandler())
+	router.DELETE("/admins/:id", controller.DisableAdminHandler())
+
+	return router, s
+}
+
+func TestCreateAdminHandler(t *testing.T) {
+	router, _ := newRouter(t)
+
+	body := gin.H{
+		"username": "hr-manager",
+		"password": "correct-horse-battery",
+		"scopes":   []string{domain.ScopeUsersRead},
+	}
+
+	recorder := controllertest.Do(t, router, http.MethodPost, "/admins", body)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	created := controllertest.Decode[domain.Admin](t, recorder)
+	assert.Equal(t, "hr-manager", created.Username)
+	assert.NotContains(t, recorder.Body.String(), "correct-horse-battery")
+
+	recorder = controllertest.Do(t, router, http.MethodPost, "/admins", body)
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+
+	recorder = controllertest.Do(t, router, http.MethodPost, "/admins", gin.H{
+		"username": "hr-lead",
+		"password": "correct-horse-battery",
+		"scopes":   []string{domain.ScopeUsersRead, domain.ScopeUsersWrite},
+	})
+	assert.Equal(t, http.StatusForbidden, recorder.Code, "the caller doesn't hold users:write")
+
+	for name, body := range map[string]gin.H{
+		"short password":          {"username": "hr", "password": "short", "scopes": []string{domain.ScopeUsersRead}},
+		"no password or telegram": {"username": "hr", "scopes": []string{domain.ScopeUsersRead}},
+		"unknown scope":           {"username": "hr", "password": "correct-horse-battery", "scopes": []string{"everything"}},
+	} {
+		recorder := controllertest.Do(t, router, http.MethodPost, "/admins", body)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, name)
+	}
+}
+
+func TestCreateAdminHandler_Concurrent(t *testing.T) {
+	router, s := newRouter(t)
+
+	body := gin.H{"username": "hr-manager", "password": "correct-horse-battery", "scopes": []string{domain.ScopeUsersRead}}
+
+	codes := make([]int, 10)
+	var wg sync.WaitGroup
+	for i := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes[i] = controllertest.Do(t, router, http.MethodPost, "/admins", body).Code
+		}()
+	}
+	wg.Wait()
+
+	slices.Sort(codes)
+	assert.Equal(t, http.StatusOK, codes[0])
+	for _, code := range codes[1:] {
+		assert.Equal(t, http.StatusConflict, code)
+	}
+
+	admins, err := s.Admin.GetAll(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, admins, 1)
+}
+
+func TestGetAdminsHandler(t *testing.T) {
+	router, s := newRouter(t)
+
+	telegramID := int64(42)
+	_, err := s.Admin.CreateAdmin(t.Context(), "hr-manager", "", &telegramID, []string{domain.ScopeUsersRead}, domain.AllScopes)
+	require.NoError(t, err)
+
+	recorder := controllertest.Do(t, router, http.MethodGet, "/admins", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	response := controllertest.Decode[dto.GetAdminsResponse](t, recorder)
+	require.Len(t, response.Admins, 1)
+	assert.Equal(t, &telegramID, response.Admins[0].TelegramID)
+}
+
+func TestDisableAdminHandler(t *testing.T) {
+	router, s := newRouter(t)
+
+	created, err := s.Admin.CreateAdmin(t.Context(), "hr-manager", "correct-horse-battery", nil, []string{domain.ScopeUsersRead}, domain.AllScopes)
+	require.NoError(t, err)
+
+	recorder := controllertest.Do(t, router, http.MethodDelete, "/admins/"+strconv.Itoa(created.ID), nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	admins, err := s.Admin.GetAll(t.Context())
+	require.NoError(t, err)
+	require.Len(t, admins, 1)
+	assert.NotNil(t, admins[0].DisabledAt)
+
+	recorder = controllertest.Do(t, router, http.MethodDelete, "/admins/100", nil)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = controllertest.Do(t, router, http.MethodDelete, "/admins/abc", nil)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
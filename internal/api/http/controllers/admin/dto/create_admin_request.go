@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"fmt"
+	"hr-server/internal/domain"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+const minPasswordLength = 12
+
+type CreateAdminRequest struct {
+	Username   string   `json:"username" example:"hr-manager"`
+	Password   string   `json:"password,omitempty" example:"correct-horse-battery"`
+	TelegramID *int64   `json:"telegram_id,omitempty" example:"123456789"`
+	Scopes     []string `json:"scopes" example:"users:read,users:export"`
+}
+
+func NewCreateAdminRequest() *CreateAdminRequest {
+	return &CreateAdminRequest{}
+}
+
+func (r *CreateAdminRequest) Parse(c *gin.Context) error {
+	return c.ShouldBindJSON(&r)
+}
+
+func (r *CreateAdminRequest) Validate() error {
+	err := validation.ValidateStruct(r,
+		validation.Field(&r.Username, validation.Required.Error("is required"), validation.Length(1, 255)),
+		validation.Field(&r.Password, validation.Length(minPasswordLength, 72)), // bcrypt ignores bytes after 72
+		validation.Field(&r.Scopes, validation.Required.Error("at least one scope is required")),
+	)
+	if err != nil {
+		return err
+	}
+
+	if r.Password == "" && r.TelegramID == nil {
+		return fmt.Errorf("password or telegram_id is required")
+	}
+
+	for _, scope := range r.Scopes {
+		if !slices.Contains(domain.AllScopes, scope) {
+			return fmt.Errorf("scopes: unknown scope '%s'", scope)
+		}
+	}
+
+	return nil
+}
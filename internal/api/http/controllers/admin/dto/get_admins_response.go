@@ -0,0 +1,15 @@
+package dto
+
+import (
+	"hr-server/internal/domain"
+)
+
+type GetAdminsResponse struct {
+	Admins []*domain.Admin `json:"admins"`
+}
+
+func NewGetAdminsResponse(admins []*domain.Admin) *GetAdminsResponse {
+	return &GetAdminsResponse{
+		Admins: admins,
+	}
+}
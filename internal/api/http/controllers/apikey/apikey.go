@@ -0,0 +1,125 @@
+package apikey
+
+import (
+	"errors"
+	"fmt"
+	"hr-server/internal/api/http/controllers/apikey/dto"
+	"hr-server/internal/api/http/controllers/common"
+	"hr-server/internal/api/http/middleware"
+	"hr-server/internal/logger"
+	"hr-server/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyController struct {
+	apiKeyService *service.APIKeyService
+}
+
+func NewAPIKeyController(apiKeyService *service.APIKeyService) *APIKeyController {
+	return &APIKeyController{apiKeyService}
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Create an API key with scopes, the token is returned only once
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateAPIKeyRequest true "Create API key request"
+// @Success 200 {object} dto.CreateAPIKeyResponse
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 403 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /keys [post]
+func (c *APIKeyController) CreateAPIKeyHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		req := dto.NewCreateAPIKeyRequest()
+		if err := req.Parse(ctx); err != nil {
+			logger.FromContext(ctx).Error("unable to parse a request: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			logger.FromContext(ctx).Error("error of validation: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		key, token, err := c.apiKeyService.CreateAPIKey(ctx, req.Name, req.Scopes, middleware.ActorScopes(ctx), req.ExpiresAt)
+		if errors.Is(err, service.ErrScopeNotHeld) {
+			ctx.JSON(http.StatusForbidden, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error("error while create API key: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to create API key: %v", err)})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, dto.NewCreateAPIKeyResponse(key, token))
+	}
+}
+
+// GetAPIKeys godoc
+// @Summary Get all API keys
+// @Description Get all API keys including revoked and expired ones, tokens are never returned
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.GetAPIKeysResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /keys [get]
+func (c *APIKeyController) GetAPIKeysHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		keys, err := c.apiKeyService.GetAll(ctx)
+		if err != nil {
+			logger.FromContext(ctx).Error("error while get all API keys: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to get all API keys: %v", err)})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, dto.NewGetAPIKeysResponse(keys))
+	}
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Revoke an API key, it can't be used afterwards
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Param id path int true "API key ID"
+// @Success 200 {object} common.SuccessResponse
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /keys/{id} [delete]
+func (c *APIKeyController) RevokeAPIKeyHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil || id <= 0 {
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: "id must be a positive integer"})
+			return
+		}
+
+		err = c.apiKeyService.RevokeAPIKey(ctx, id)
+		if errors.Is(err, service.ErrAPIKeyNotFound) {
+			ctx.JSON(http.StatusNotFound, common.ErrorResponse{Error: "API key not found or already revoked"})
+			return
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error("error while revoke API key: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to revoke API key %d: %v", id, err)})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, common.SuccessResponse{})
+	}
+}
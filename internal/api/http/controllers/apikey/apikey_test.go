@@ -0,0 +1,125 @@
+package apikey_test
+
+import (
+	"context"
+	"hr-server/internal/api/http/controllers/apikey"
+	"hr-server/internal/api/http/controllers/apikey/dto"
+	"hr-server/internal/api/http/controllers/controllertest"
+	"hr-server/internal/api/http/middleware"
+	"hr-server/internal/domain"
+	"hr-server/internal/service"
+	"hr-server/internal/service/servicetest"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRouter(t *testing.T) (*gin.Engine, *servicetest.Services) {
+	t.Helper()
+
+	s := servicetest.NewServices(servicetest.Config())
+	controller := apikey.NewAPIKeyController(s.APIKey)
+
+	// the requests are made with a key holding the scopes of the keys created by the tests
+	router := controllertest.NewRouter()
+	router.Use(func(c *gin.Context) {
+		c.Set(middleware.APIKeyContextKey, &domain.APIKey{ID: 1, Name: "bootstrap", Scopes: []string{domain.ScopeAPIKeysManage, domain.ScopeUsersRead}})
+	})
+	router.POST("/api-keys", controller.CreateAPIKeyHandler())
+	router.GET("/api-keys", controller.GetAPIKeysHandler())
+	router.DELETE("/api-keys/:id", controller.RevokeAPIKeyHandler())
+
+	return router, s
+}
+
+func TestCreateAPIKeyHandler(t *testing.T) {
+	router, s := newRouter(t)
+
+	recorder := controllertest.Do(t, router, http.MethodPost, "/api-keys", gin.H{
+		"name":   "hr-panel",
+		"scopes": []string{domain.ScopeUsersRead},
+	})
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	response := controllertest.Decode[dto.CreateAPIKeyResponse](t, recorder)
+	assert.Equal(t, "hr-panel", response.Name)
+	require.NotEmpty(t, response.Token)
+
+	key, err := s.APIKey.Authenticate(context.Background(), response.Token)
+	require.NoError(t, err)
+	assert.Equal(t, response.ID, key.ID)
+
+	for _, scope := range []string{domain.ScopeUsersWrite, domain.ScopeAdminsManage, domain.ScopeAuditRead} {
+		recorder := controllertest.Do(t, router, http.MethodPost, "/api-keys", gin.H{
+			"name":   "escalated",
+			"scopes": []string{domain.ScopeUsersRead, scope},
+		})
+		assert.Equal(t, http.StatusForbidden, recorder.Code, "the caller doesn't hold %s", scope)
+	}
+
+	for name, body := range map[string]gin.H{
+		"missing name":  {"scopes": []string{domain.ScopeUsersRead}},
+		"missing scope": {"name": "hr-panel"},
+		"unknown scope": {"name": "hr-panel", "scopes": []string{"everything"}},
+		"past expiry":   {"name": "hr-panel", "scopes": []string{domain.ScopeUsersRead}, "expires_at": time.Now().Add(-time.Hour)},
+	} {
+		recorder := controllertest.Do(t, router, http.MethodPost, "/api-keys", body)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, name)
+	}
+}
+
+func TestGetAPIKeysHandler(t *testing.T) {
+	router, s := newRouter(t)
+
+	_, _, err := s.APIKey.CreateAPIKey(t.Context(), "hr-panel", []string{domain.ScopeUsersRead}, domain.AllScopes, nil)
+	require.NoError(t, err)
+
+	recorder := controllertest.Do(t, router, http.MethodGet, "/api-keys", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	response := controllertest.Decode[dto.GetAPIKeysResponse](t, recorder)
+	require.Len(t, response.Keys, 1)
+	assert.Equal(t, "hr-panel", response.Keys[0].Name)
+	assert.NotContains(t, recorder.Body.String(), "hash", "the key hash is never returned")
+}
+
+func TestRevokeAPIKeyHandler(t *testing.T) {
+	router, s := newRouter(t)
+
+	key, token, err := s.APIKey.CreateAPIKey(t.Context(), "hr-panel", []string{domain.ScopeUsersRead}, domain.AllScopes, nil)
+	require.NoError(t, err)
+
+	recorder := controllertest.Do(t, router, http.MethodDelete, "/api-keys/"+strconv.Itoa(key.ID), nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	_, err = s.APIKey.Authenticate(context.Background(), token)
+	assert.ErrorIs(t, err, service.ErrInvalidAPIKey)
+
+	recorder = controllertest.Do(t, router, http.MethodDelete, "/api-keys/"+strconv.Itoa(key.ID), nil)
+	assert.Equal(t, http.StatusNotFound, recorder.Code, "already revoked")
+
+	recorder = controllertest.Do(t, router, http.MethodDelete, "/api-keys/abc", nil)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestAuthenticate_LegacyToken(t *testing.T) {
+	_, s := newRouter(t)
+
+	key, err := s.APIKey.Authenticate(t.Context(), servicetest.AuthToken)
+	require.NoError(t, err)
+	assert.Equal(t, domain.LegacyTokenScopes, key.Scopes)
+	assert.False(t, key.HasScope(domain.ScopeAPIKeysManage), "the legacy token can't create keys")
+	assert.False(t, key.HasScope(domain.ScopeAdminsManage), "the legacy token can't create admins")
+
+	cfg := servicetest.Config()
+	cfg.AuthToken = ""
+	s = servicetest.NewServices(cfg)
+
+	_, err = s.APIKey.Authenticate(t.Context(), servicetest.AuthToken)
+	assert.ErrorIs(t, err, service.ErrInvalidAPIKey, "disabled without AUTH_TOKEN")
+}
@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"fmt"
+	"hr-server/internal/domain"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" example:"hr-panel"`
+	Scopes    []string   `json:"scopes" example:"users:read,users:export"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2027-01-01T00:00:00Z"`
+}
+
+func NewCreateAPIKeyRequest() *CreateAPIKeyRequest {
+	return &CreateAPIKeyRequest{}
+}
+
+func (r *CreateAPIKeyRequest) Parse(c *gin.Context) error {
+	return c.ShouldBindJSON(&r)
+}
+
+func (r *CreateAPIKeyRequest) Validate() error {
+	err := validation.ValidateStruct(r,
+		validation.Field(&r.Name, validation.Required.Error("is required"), validation.Length(1, 255)),
+		validation.Field(&r.Scopes, validation.Required.Error("at least one scope is required")),
+		validation.Field(&r.ExpiresAt, validation.By(func(value interface{}) error {
+			expiresAt, _ := value.(*time.Time)
+			if expiresAt != nil && !expiresAt.After(time.Now()) {
+				return fmt.Errorf("must be in the future")
+			}
+			return nil
+		})),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, scope := range r.Scopes {
+		if !slices.Contains(domain.AllScopes, scope) {
+			return fmt.Errorf("scopes: unknown scope '%s'", scope)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"hr-server/internal/domain"
+)
+
+type CreateAPIKeyResponse struct {
+	*domain.APIKey
+	Token string `json:"token"` // shown only once
+}
+
+func NewCreateAPIKeyResponse(key *domain.APIKey, token string) *CreateAPIKeyResponse {
+	return &CreateAPIKeyResponse{
+		APIKey: key,
+		Token:  token,
+	}
+}
+
+type GetAPIKeysResponse struct {
+	Keys []*domain.APIKey `json:"keys"`
+}
+
+func NewGetAPIKeysResponse(keys []*domain.APIKey) *GetAPIKeysResponse {
+	return &GetAPIKeysResponse{
+		Keys: keys,
+	}
+}
@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"hr-server/internal/api/http/controllers/audit/dto"
+	"hr-server/internal/api/http/controllers/common"
+	"hr-server/internal/domain"
+	"hr-server/internal/logger"
+	"hr-server/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditController struct {
+	auditService *service.AuditService
+}
+
+func NewAuditController(auditService *service.AuditService) *AuditController {
+	return &AuditController{auditService}
+}
+
+// GetAudit godoc
+// @Summary Get audit log
+// @Description Get a page of the audit log of administrative actions, newest first by default
+// @Tags Audit
+// @Accept json
+// @Produce json
+// @Param actor_type query string false "Actor type" Enums(api_key, admin)
+// @Param actor_id query int false "API key or admin ID, 0 for the legacy AUTH_TOKEN"
+// @Param action query string false "Action, e.g. notification.send"
+// @Param target query string false "Target, e.g. a Telegram ID or a channel code"
+// @Param result query string false "Result" Enums(success, failure)
+// @Param created_from query string false "Created at or after, RFC3339"
+// @Param created_to query string false "Created before, RFC3339"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size, 50 by default, at most 500"
+// @Param sort_by query string false "Sort field" Enums(created_at)
+// @Param sort_order query string false "Sort order, desc by default" Enums(asc, desc)
+// @Success 200 {object} dto.GetAuditResponse
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Security BearerAuth
+// @Router /audit [get]
+func (c *AuditController) GetAuditHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		req := dto.NewGetAuditRequest()
+		if err := req.Parse(ctx); err != nil {
+			logger.FromContext(ctx).Error("unable to parse a request: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			logger.FromContext(ctx).Error("error of validation: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		entries, err := c.auditService.GetAuditPage(ctx, req.Filter(), req.ToDomain())
+		if errors.Is(err, domain.ErrInvalidCursor) {
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error("error while get audit log: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to get audit log: %v", err)})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, dto.NewGetAuditResponse(entries))
+	}
+}
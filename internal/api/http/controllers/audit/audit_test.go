@@ -0,0 +1,54 @@
+package audit_test
+
+import (
+	"hr-server/internal/api/http/controllers/audit"
+	"hr-server/internal/api/http/controllers/audit/dto"
+	"hr-server/internal/api/http/controllers/controllertest"
+	"hr-server/internal/domain"
+	"hr-server/internal/service/servicetest"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAuditHandler(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+
+	router := controllertest.NewRouter()
+	router.GET("/audit", audit.NewAuditController(s.Audit).GetAuditHandler())
+
+	for _, entry := range []*domain.AuditEntry{
+		{ActorType: domain.AuditActorAPIKey, ActorID: 1, Action: "notification.send", Status: http.StatusOK},
+		{ActorType: domain.AuditActorAdmin, ActorID: 1, Action: "user.delete", Target: "42", Status: http.StatusNotFound},
+		{ActorType: domain.AuditActorAdmin, ActorID: 1, Action: "user.delete", Target: "43", Status: http.StatusOK},
+	} {
+		require.NoError(t, s.Audit.Record(t.Context(), entry))
+	}
+
+	recorder := controllertest.Do(t, router, http.MethodGet, "/audit?actor_type=admin&result=failure", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	response := controllertest.Decode[dto.GetAuditResponse](t, recorder)
+	assert.EqualValues(t, 1, response.TotalCount)
+	require.Len(t, response.Entries, 1)
+	assert.Equal(t, "42", response.Entries[0].Target)
+
+	recorder = controllertest.Do(t, router, http.MethodGet, "/audit?limit=2", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	response = controllertest.Decode[dto.GetAuditResponse](t, recorder)
+	require.Len(t, response.Entries, 2)
+	assert.Equal(t, "43", response.Entries[0].Target, "newest first by default")
+	require.NotEmpty(t, response.NextCursor)
+
+	recorder = controllertest.Do(t, router, http.MethodGet, "/audit?limit=2&cursor="+response.NextCursor, nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Len(t, controllertest.Decode[dto.GetAuditResponse](t, recorder).Entries, 1)
+
+	for _, query := range []string{"cursor=garbage", "actor_type=robot", "result=unknown", "sort_by=action"} {
+		recorder := controllertest.Do(t, router, http.MethodGet, "/audit?"+query, nil)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, query)
+	}
+}
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"hr-server/internal/api/http/controllers/common"
+	"hr-server/internal/domain"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+type GetAuditRequest struct {
+	common.PageRequest
+
+	ActorType   string     `form:"actor_type" enums:"api_key,admin"`
+	ActorID     *int       `form:"actor_id"`
+	Action      string     `form:"action" example:"notification.send"`
+	Target      string     `form:"target"`
+	Result      string     `form:"result" enums:"success,failure"`
+	CreatedFrom *time.Time `form:"created_from" example:"2026-01-01T00:00:00Z"`
+	CreatedTo   *time.Time `form:"created_to" example:"2026-02-01T00:00:00Z"`
+}
+
+func NewGetAuditRequest() *GetAuditRequest {
+	return &GetAuditRequest{}
+}
+
+func (r *GetAuditRequest) Parse(c *gin.Context) error {
+	return c.ShouldBindQuery(r)
+}
+
+func (r *GetAuditRequest) Validate() error {
+	err := validation.ValidateStruct(r,
+		validation.Field(&r.ActorType, validation.In(domain.AuditActorAPIKey, domain.AuditActorAdmin)),
+		validation.Field(&r.ActorID, validation.Min(0)),
+		validation.Field(&r.Action, validation.Length(0, 50)),
+		validation.Field(&r.Target, validation.Length(0, 255)),
+		validation.Field(&r.Result, validation.In(domain.AuditResultSuccess, domain.AuditResultFailure)),
+	)
+	if err != nil {
+		return err
+	}
+
+	return r.ValidatePage("created_at")
+}
+
+func (r *GetAuditRequest) Filter() domain.AuditFilter {
+	return domain.AuditFilter{
+		ActorType: r.ActorType,
+		ActorID:   r.ActorID,
+		Action:    r.Action,
+		Target:    r.Target,
+		Result:    r.Result,
+		CreatedAt: domain.TimeRange{From: r.CreatedFrom, To: r.CreatedTo},
+	}
+}
@@ -0,0 +1,19 @@
+package dto
+
+import (
+	"hr-server/internal/domain"
+)
+
+type GetAuditResponse struct {
+	Entries    []*domain.AuditEntry `json:"entries"`
+	TotalCount int64                `json:"total_count"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
+
+func NewGetAuditResponse(page *domain.Page[*domain.AuditEntry]) *GetAuditResponse {
+	return &GetAuditResponse{
+		Entries:    page.Items,
+		TotalCount: page.TotalCount,
+		NextCursor: page.NextCursor,
+	}
+}
@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"hr-server/internal/api/http/controllers/auth/dto"
+	"hr-server/internal/api/http/controllers/common"
+	"hr-server/internal/domain"
+	"hr-server/internal/logger"
+	"hr-server/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthController struct {
+	adminService *service.AdminService
+}
+
+func NewAuthController(adminService *service.AdminService) *AuthController {
+	return &AuthController{adminService}
+}
+
+// Login godoc
+// @Summary Log in with a password
+// @Description Log in an admin with username and password, returns a JWT access token and a refresh token
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body dto.LoginRequest true "Login request"
+// @Success 200 {object} domain.AdminTokens
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 401 {object} common.ErrorResponse
+// @Failure 503 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /auth/login [post]
+func (c *AuthController) LoginHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		req := dto.NewLoginRequest()
+		if err := req.Parse(ctx); err != nil {
+			logger.FromContext(ctx).Error("unable to parse a request: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			logger.FromContext(ctx).Error("error of validation: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		tokens, err := c.adminService.Login(ctx, req.Username, req.Password)
+		c.respondTokens(ctx, tokens, err)
+	}
+}
+
+// TelegramLogin godoc
+// @Summary Log in with Telegram
+// @Description Log in an admin with the Telegram Login Widget data, the data is verified with the bot token
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body dto.TelegramLoginRequest true "Telegram Login Widget data"
+// @Success 200 {object} domain.AdminTokens
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 401 {object} common.ErrorResponse
+// @Failure 503 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /auth/telegram [post]
+func (c *AuthController) TelegramLoginHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		req := dto.NewTelegramLoginRequest()
+		if err := req.Parse(ctx); err != nil {
+			logger.FromContext(ctx).Error("unable to parse a request: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			logger.FromContext(ctx).Error("error of validation: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		tokens, err := c.adminService.LoginWithTelegram(ctx, req.ToDomain())
+		c.respondTokens(ctx, tokens, err)
+	}
+}
+
+// Refresh godoc
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for new tokens, the refresh token can be used only once
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body dto.RefreshRequest true "Refresh request"
+// @Success 200 {object} domain.AdminTokens
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 401 {object} common.ErrorResponse
+// @Failure 503 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /auth/refresh [post]
+func (c *AuthController) RefreshHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		req := dto.NewRefreshRequest()
+		if err := req.Parse(ctx); err != nil {
+			logger.FromContext(ctx).Error("unable to parse a request: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			logger.FromContext(ctx).Error("error of validation: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		tokens, err := c.adminService.Refresh(ctx, req.RefreshToken)
+		c.respondTokens(ctx, tokens, err)
+	}
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description Revoke the refresh token, the access token stays valid until it expires or the admin is disabled
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body dto.RefreshRequest true "Logout request"
+// @Success 200 {object} common.SuccessResponse
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Router /auth/logout [post]
+func (c *AuthController) LogoutHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		req := dto.NewRefreshRequest()
+		if err := req.Parse(ctx); err != nil {
+			logger.FromContext(ctx).Error("unable to parse a request: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			logger.FromContext(ctx).Error("error of validation: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := c.adminService.Logout(ctx, req.RefreshToken); err != nil {
+			logger.FromContext(ctx).Error("error while logout admin: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to logout: %v", err)})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, common.SuccessResponse{})
+	}
+}
+
+func (c *AuthController) respondTokens(ctx *gin.Context, tokens *domain.AdminTokens, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidCredentials),
+		errors.Is(err, service.ErrInvalidAdminToken),
+		errors.Is(err, service.ErrInvalidTelegramData):
+		ctx.JSON(http.StatusUnauthorized, common.ErrorResponse{Error: err.Error()})
+	case errors.Is(err, service.ErrAdminAuthDisabled):
+		ctx.JSON(http.StatusServiceUnavailable, common.ErrorResponse{Error: err.Error()})
+	case err != nil:
+		logger.FromContext(ctx).Error("error while login admin: ", err)
+		ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to login: %v", err)})
+	default:
+		ctx.JSON(http.StatusOK, tokens)
+	}
+}
@@ -0,0 +1,178 @@
+package auth_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"hr-server/internal/api/http/controllers/auth"
+	"hr-server/internal/api/http/controllers/controllertest"
+	"hr-server/internal/domain"
+	"hr-server/internal/service"
+	"hr-server/internal/service/servicetest"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const password = "correct-horse-battery"
+
+func newRouter(t *testing.T, s *servicetest.Services) *gin.Engine {
+	t.Helper()
+
+	controller := auth.NewAuthController(s.Admin)
+
+	router := controllertest.NewRouter()
+	router.POST("/auth/login", controller.LoginHandler())
+	router.POST("/auth/telegram", controller.TelegramLoginHandler())
+	router.POST("/auth/refresh", controller.RefreshHandler())
+	router.POST("/auth/logout", controller.LogoutHandler())
+
+	return router
+}
+
+func TestLoginHandler(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+	router := newRouter(t, s)
+
+	_, err := s.Admin.CreateAdmin(t.Context(), "hr-manager", password, nil, []string{domain.ScopeUsersRead}, domain.AllScopes)
+	require.NoError(t, err)
+
+	recorder := controllertest.Do(t, router, http.MethodPost, "/auth/login", gin.H{"username": "hr-manager", "password": password})
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	tokens := controllertest.Decode[domain.AdminTokens](t, recorder)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NotEmpty(t, tokens.RefreshToken)
+
+	admin, err := s.Admin.AuthenticateAccessToken(t.Context(), tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "hr-manager", admin.Username)
+
+	require.NoError(t, s.Admin.DisableAdmin(t.Context(), admin.ID))
+	_, err = s.Admin.AuthenticateAccessToken(t.Context(), tokens.AccessToken)
+	assert.ErrorIs(t, err, service.ErrInvalidAdminToken, "disabled admins lose access before the token expires")
+
+	for name, body := range map[string]gin.H{
+		"wrong password": {"username": "hr-manager", "password": "wrong-password"},
+		"unknown admin":  {"username": "unknown", "password": password},
+	} {
+		recorder := controllertest.Do(t, router, http.MethodPost, "/auth/login", body)
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code, name)
+	}
+
+	recorder = controllertest.Do(t, router, http.MethodPost, "/auth/login", gin.H{"username": "hr-manager"})
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestLoginHandler_TelegramOnlyAdmin(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+	router := newRouter(t, s)
+
+	telegramID := int64(42)
+	_, err := s.Admin.CreateAdmin(t.Context(), "telegram-only", "", &telegramID, []string{domain.ScopeUsersRead}, domain.AllScopes)
+	require.NoError(t, err)
+
+	for _, password := range []string{"dummy-password", "", "anything"} {
+		recorder := controllertest.Do(t, router, http.MethodPost, "/auth/login", gin.H{"username": "telegram-only", "password": password})
+		assert.NotEqual(t, http.StatusOK, recorder.Code, password)
+	}
+
+	_, err = s.Admin.Login(t.Context(), "telegram-only", "dummy-password")
+	assert.ErrorIs(t, err, service.ErrInvalidCredentials)
+}
+
+func TestLoginHandler_Disabled(t *testing.T) {
+	cfg := servicetest.Config()
+	cfg.Admin.JWTSecret = ""
+
+	s := servicetest.NewServices(cfg)
+	router := newRouter(t, s)
+
+	recorder := controllertest.Do(t, router, http.MethodPost, "/auth/login", gin.H{"username": "hr-manager", "password": password})
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+}
+
+func TestTelegramLoginHandler(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+	router := newRouter(t, s)
+
+	telegramID := int64(42)
+	_, err := s.Admin.CreateAdmin(t.Context(), "hr-manager", "", &telegramID, []string{domain.ScopeUsersRead}, domain.AllScopes)
+	require.NoError(t, err)
+
+	data := gin.H{
+		"id":         telegramID,
+		"first_name": "Ivan",
+		"auth_date":  time.Now().Unix(),
+	}
+	data["hash"] = signTelegramLogin(data)
+
+	recorder := controllertest.Do(t, router, http.MethodPost, "/auth/telegram", data)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotEmpty(t, controllertest.Decode[domain.AdminTokens](t, recorder).AccessToken)
+
+	data["first_name"] = "Petr"
+	recorder = controllertest.Do(t, router, http.MethodPost, "/auth/telegram", data)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code, "tampered data")
+
+	data = gin.H{"id": telegramID, "auth_date": time.Now().Add(-48 * time.Hour).Unix()}
+	data["hash"] = signTelegramLogin(data)
+	recorder = controllertest.Do(t, router, http.MethodPost, "/auth/telegram", data)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code, "outdated data")
+
+	data = gin.H{"id": telegramID, "auth_date": time.Now().Add(time.Hour).Unix()}
+	data["hash"] = signTelegramLogin(data)
+	recorder = controllertest.Do(t, router, http.MethodPost, "/auth/telegram", data)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code, "data from the future")
+
+	data = gin.H{"id": telegramID, "auth_date": time.Now().Add(time.Minute).Unix()}
+	data["hash"] = signTelegramLogin(data)
+	recorder = controllertest.Do(t, router, http.MethodPost, "/auth/telegram", data)
+	assert.Equal(t, http.StatusOK, recorder.Code, "small clock skew")
+}
+
+func TestRefreshHandler(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+	router := newRouter(t, s)
+
+	_, err := s.Admin.CreateAdmin(t.Context(), "hr-manager", password, nil, []string{domain.ScopeUsersRead}, domain.AllScopes)
+	require.NoError(t, err)
+
+	tokens, err := s.Admin.Login(t.Context(), "hr-manager", password)
+	require.NoError(t, err)
+
+	recorder := controllertest.Do(t, router, http.MethodPost, "/auth/refresh", gin.H{"refresh_token": tokens.RefreshToken})
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	refreshed := controllertest.Decode[domain.AdminTokens](t, recorder)
+	assert.NotEqual(t, tokens.RefreshToken, refreshed.RefreshToken)
+
+	recorder = controllertest.Do(t, router, http.MethodPost, "/auth/refresh", gin.H{"refresh_token": tokens.RefreshToken})
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code, "refresh token is used only once")
+
+	recorder = controllertest.Do(t, router, http.MethodPost, "/auth/logout", gin.H{"refresh_token": refreshed.RefreshToken})
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = controllertest.Do(t, router, http.MethodPost, "/auth/refresh", gin.H{"refresh_token": refreshed.RefreshToken})
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code, "revoked on logout")
+}
+
+// signTelegramLogin signs the Telegram Login Widget data with the test bot token
+func signTelegramLogin(data gin.H) string {
+	dataCheckString := "auth_date=" + strconv.FormatInt(data["auth_date"].(int64), 10)
+	if firstName, ok := data["first_name"]; ok {
+		dataCheckString += "\nfirst_name=" + firstName.(string)
+	}
+	dataCheckString += "\nid=" + strconv.FormatInt(data["id"].(int64), 10)
+
+	secretKey := sha256.Sum256([]byte(servicetest.BotToken))
+	mac := hmac.New(sha256.New, secretKey[:])
+	mac.Write([]byte(dataCheckString))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+type LoginRequest struct {
+	Username string `json:"username" example:"hr-manager"`
+	Password string `json:"password" example:"secret"`
+}
+
+func NewLoginRequest() *LoginRequest {
+	return &LoginRequest{}
+}
+
+func (r *LoginRequest) Parse(c *gin.Context) error {
+	return c.ShouldBindJSON(&r)
+}
+
+func (r *LoginRequest) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.Username, validation.Required.Error("is required")),
+		validation.Field(&r.Password, validation.Required.Error("is required")),
+	)
+}
@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// RefreshRequest is used both to refresh the tokens and to log out
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func NewRefreshRequest() *RefreshRequest {
+	return &RefreshRequest{}
+}
+
+func (r *RefreshRequest) Parse(c *gin.Context) error {
+	return c.ShouldBindJSON(&r)
+}
+
+func (r *RefreshRequest) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.RefreshToken, validation.Required.Error("is required")),
+	)
+}
@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"hr-server/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// TelegramLoginRequest represents the data passed to the onauth callback of the Telegram Login Widget
+type TelegramLoginRequest struct {
+	ID        int64  `json:"id" example:"123456789"`
+	FirstName string `json:"first_name,omitempty" example:"Ivan"`
+	LastName  string `json:"last_name,omitempty" example:"Petrov"`
+	Username  string `json:"username,omitempty" example:"ivan_petrov"`
+	PhotoURL  string `json:"photo_url,omitempty"`
+	AuthDate  int64  `json:"auth_date" example:"1760000000"`
+	Hash      string `json:"hash"`
+}
+
+func NewTelegramLoginRequest() *TelegramLoginRequest {
+	return &TelegramLoginRequest{}
+}
+
+func (r *TelegramLoginRequest) Parse(c *gin.Context) error {
+	return c.ShouldBindJSON(&r)
+}
+
+func (r *TelegramLoginRequest) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.ID, validation.Required.Error("is required")),
+		validation.Field(&r.AuthDate, validation.Required.Error("is required")),
+		validation.Field(&r.Hash, validation.Required.Error("is required")),
+	)
+}
+
+func (r *TelegramLoginRequest) ToDomain() *domain.TelegramLoginData {
+	return &domain.TelegramLoginData{
+		ID:        r.ID,
+		FirstName: r.FirstName,
+		LastName:  r.LastName,
+		Username:  r.Username,
+		PhotoURL:  r.PhotoURL,
+		AuthDate:  r.AuthDate,
+		Hash:      r.Hash,
+	}
+}
@@ -0,0 +1,112 @@
+package campaign
+
+import (
+	"errors"
+	"fmt"
+	"hr-server/internal/api/http/controllers/campaign/dto"
+	"hr-server/internal/api/http/controllers/common"
+	"hr-server/internal/logger"
+	"hr-server/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CampaignController struct {
+	campaignService *service.CampaignService
+}
+
+func NewCampaignController(campaignService *service.CampaignService) *CampaignController {
+	return &CampaignController{campaignService}
+}
+
+// CreateCampaign godoc
+// @Summary Create a new campaign
+// @Description Create a new campaign to group channels
+// @Tags Campaigns
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateCampaignRequest true "Create campaign request"
+// @Success 200 {object} domain.Campaign
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 409 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /campaigns [post]
+func (c *CampaignController) CreateCampaignHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		req := dto.NewCreateCampaignRequest()
+		if err := req.Parse(ctx); err != nil {
+			logger.FromContext(ctx).Error("unable to parse a request: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			logger.FromContext(ctx).Error("error of validation: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		campaign, err := c.campaignService.CreateCampaign(ctx, req.Name)
+		if errors.Is(err, service.ErrCampaignExists) {
+			ctx.JSON(http.StatusConflict, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error("error while create campaign: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to create campaign: %v", err)})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, campaign)
+	}
+}
+
+// GetCampaigns godoc
+// @Summary Get all campaigns
+// @Description Get all campaigns
+// @Tags Campaigns
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.GetCampaignsResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /campaigns [get]
+func (c *CampaignController) GetCampaignsHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		campaigns, err := c.campaignService.GetAll(ctx)
+		if err != nil {
+			logger.FromContext(ctx).Error("error while get all campaigns: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to get all campaigns: %v", err)})
+			return
+		}
+
+		response := dto.NewGetCampaignsResponse(campaigns)
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+// GetCampaignStats godoc
+// @Summary Get campaign stats
+// @Description Get the number of channels and users of every campaign
+// @Tags Campaigns
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.GetCampaignStatsResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /campaigns/stats [get]
+func (c *CampaignController) GetCampaignStatsHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		stats, err := c.campaignService.GetStats(ctx)
+		if err != nil {
+			logger.FromContext(ctx).Error("error while get campaign stats: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to get campaign stats: %v", err)})
+			return
+		}
+
+		response := dto.NewGetCampaignStatsResponse(stats)
+		ctx.JSON(http.StatusOK, response)
+	}
+}
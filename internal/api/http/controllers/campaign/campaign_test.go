@@ -0,0 +1,93 @@
+package campaign_test
+
+import (
+	"hr-server/internal/api/http/controllers/campaign"
+	"hr-server/internal/api/http/controllers/campaign/dto"
+	"hr-server/internal/api/http/controllers/controllertest"
+	"hr-server/internal/domain"
+	"hr-server/internal/service/servicetest"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRouter(t *testing.T) (*gin.Engine, *servicetest.Services) {
+	t.Helper()
+
+	s := servicetest.NewServices(servicetest.Config())
+	controller := campaign.NewCampaignController(s.Campaign)
+
+	router := controllertest.NewRouter()
+	router.POST("/campaigns", controller.CreateCampaignHandler())
+	router.GET("/campaigns", controller.GetCampaignsHandler())
+	router.GET("/campaigns/stats", controller.GetCampaignStatsHandler())
+
+	return router, s
+}
+
+func TestCreateCampaignHandler(t *testing.T) {
+	router, _ := newRouter(t)
+
+	recorder := controllertest.Do(t, router, http.MethodPost, "/campaigns", gin.H{"name": "spring-2026"})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "spring-2026", controllertest.Decode[domain.Campaign](t, recorder).Name)
+
+	recorder = controllertest.Do(t, router, http.MethodPost, "/campaigns", gin.H{"name": ""})
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = controllertest.Do(t, router, http.MethodPost, "/campaigns", gin.H{"name": "spring-2026"})
+	assert.Equal(t, http.StatusConflict, recorder.Code, "names are unique")
+}
+
+func TestGetCampaignsHandler(t *testing.T) {
+	router, s := newRouter(t)
+
+	for _, name := range []string{"b", "a"} {
+		_, err := s.Campaign.CreateCampaign(t.Context(), name)
+		require.NoError(t, err)
+	}
+
+	recorder := controllertest.Do(t, router, http.MethodGet, "/campaigns", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	response := controllertest.Decode[dto.GetCampaignsResponse](t, recorder)
+	require.Len(t, response.Campaigns, 2)
+	assert.Equal(t, "a", response.Campaigns[0].Name)
+}
+
+func TestGetCampaignStatsHandler(t *testing.T) {
+	router, s := newRouter(t)
+
+	campaign, err := s.Campaign.CreateCampaign(t.Context(), "spring-2026")
+	require.NoError(t, err)
+	empty, err := s.Campaign.CreateCampaign(t.Context(), "empty")
+	require.NoError(t, err)
+	source, err := s.Source.CreateSource(t.Context(), "hh.ru")
+	require.NoError(t, err)
+
+	attributed, err := s.Channel.GenerateChannel(t.Context(), "hh.ru", domain.ChannelAttributes{CampaignID: &campaign.ID})
+	require.NoError(t, err)
+	s.AddUser(t, 1, "attributed", &attributed.ID)
+
+	// a campaign channel nobody started the bot with still counts
+	_, err = s.Channel.GenerateChannel(t.Context(), "unused", domain.ChannelAttributes{CampaignID: &campaign.ID})
+	require.NoError(t, err)
+
+	// neither a channel without a campaign nor a user without a channel counts
+	unattributed, err := s.Channel.GenerateChannel(t.Context(), "no campaign", domain.ChannelAttributes{SourceID: &source.ID})
+	require.NoError(t, err)
+	s.AddUser(t, 2, "no campaign", &unattributed.ID)
+	s.AddUser(t, 3, "no channel", nil)
+
+	recorder := controllertest.Do(t, router, http.MethodGet, "/campaigns/stats", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	response := controllertest.Decode[dto.GetCampaignStatsResponse](t, recorder)
+	assert.Equal(t, []*domain.GroupStats{
+		{ID: empty.ID, Name: "empty"},
+		{ID: campaign.ID, Name: "spring-2026", ChannelsCount: 2, UsersCount: 1},
+	}, response.Stats)
+}
@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+type CreateCampaignRequest struct {
+	Name string `json:"name" example:"spring-2026"`
+}
+
+func NewCreateCampaignRequest() *CreateCampaignRequest {
+	return &CreateCampaignRequest{}
+}
+
+func (r *CreateCampaignRequest) Parse(c *gin.Context) error {
+	return c.ShouldBindJSON(&r)
+}
+
+func (r *CreateCampaignRequest) Validate() error {
+	err := validation.ValidateStruct(r,
+		validation.Field(&r.Name,
+			validation.Required.Error("is required"),
+			validation.Length(1, 255),
+		),
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
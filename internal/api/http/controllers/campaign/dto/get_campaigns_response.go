@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"hr-server/internal/domain"
+)
+
+type GetCampaignsResponse struct {
+	Campaigns []*domain.Campaign `json:"campaigns"`
+}
+
+func NewGetCampaignsResponse(campaigns []*domain.Campaign) *GetCampaignsResponse {
+	return &GetCampaignsResponse{
+		Campaigns: campaigns,
+	}
+}
+
+type GetCampaignStatsResponse struct {
+	Stats []*domain.GroupStats `json:"stats"`
+}
+
+func NewGetCampaignStatsResponse(stats []*domain.GroupStats) *GetCampaignStatsResponse {
+	return &GetCampaignStatsResponse{
+		Stats: stats,
+	}
+}
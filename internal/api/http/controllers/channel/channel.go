@@ -1,14 +1,16 @@
 package channel
 
 import (
+	"errors"
 	"fmt"
 	"hr-server/internal/api/http/controllers/channel/dto"
 	"hr-server/internal/api/http/controllers/common"
+	"hr-server/internal/domain"
+	"hr-server/internal/logger"
 	"hr-server/internal/service"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 )
 
 type ChannelController struct {
@@ -35,20 +37,24 @@ func (c *ChannelController) GenerateChannelHandler() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		req := dto.NewGenerateChannelRequest()
 		if err := req.Parse(ctx); err != nil {
-			logrus.Error("unable to parse a request: ", err)
+			logger.FromContext(ctx).Error("unable to parse a request: ", err)
 			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
 			return
 		}
 
 		if err := req.Validate(); err != nil {
-			logrus.Error("error of validation: ", err)
+			logger.FromContext(ctx).Error("error of validation: ", err)
 			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
 			return
 		}
 
-		channel, err := c.channelService.GenerateChannel(req.ChannelName)
+		channel, err := c.channelService.GenerateChannel(ctx, req.ChannelName, req.Attributes())
+		if isGroupingNotFound(err) {
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
 		if err != nil {
-			logrus.Error("error while generate channel: ", err)
+			logger.FromContext(ctx).Error("error while generate channel: ", err)
 			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to generate channel: %v", err)})
 			return
 		}
@@ -73,20 +79,24 @@ func (c *ChannelController) GenerateBulkChannelHandler() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		req := dto.NewGenerateBulkChannelRequest()
 		if err := req.Parse(ctx); err != nil {
-			logrus.Error("unable to parse a request: ", err)
+			logger.FromContext(ctx).Error("unable to parse a request: ", err)
 			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
 			return
 		}
 
 		if err := req.Validate(); err != nil {
-			logrus.Error("error of validation: ", err)
+			logger.FromContext(ctx).Error("error of validation: ", err)
 			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
 			return
 		}
 
-		channels, err := c.channelService.GenerateBulkChannel(req.ChannelNames)
+		channels, err := c.channelService.GenerateBulkChannel(ctx, req.ChannelNames, req.Attributes())
+		if isGroupingNotFound(err) {
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
 		if err != nil {
-			logrus.Error("error while generate bulk channel: ", err)
+			logger.FromContext(ctx).Error("error while generate bulk channel: ", err)
 			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to generate bulk channel: %v", err)})
 			return
 		}
@@ -115,9 +125,9 @@ func (c *ChannelController) GetChannelByCodeHandler() gin.HandlerFunc {
 			return
 		}
 
-		channel, err := c.channelService.GetChannelByCode(code)
+		channel, err := c.channelService.GetChannelByCode(ctx, code)
 		if err != nil {
-			logrus.Error("error while get channel by code: ", err)
+			logger.FromContext(ctx).Error("error while get channel by code: ", err)
 			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to get channel by code '%s': %v", code, err)})
 			return
 		}
@@ -131,21 +141,101 @@ func (c *ChannelController) GetChannelByCodeHandler() gin.HandlerFunc {
 	}
 }
 
+// UpdateChannel godoc
+// @Summary Update channel
+// @Description Update the name, campaign, source, tags, expiry, usage cap and timezone of the channel
+// @Tags Channels
+// @Accept json
+// @Produce json
+// @Param code path string true "Channel code"
+// @Param request body dto.UpdateChannelRequest true "Update channel request"
+// @Success 200 {object} domain.Channel
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /channels/{code} [put]
+func (c *ChannelController) UpdateChannelHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		code := ctx.Param("code")
+
+		req := dto.NewUpdateChannelRequest()
+		if err := req.Parse(ctx); err != nil {
+			logger.FromContext(ctx).Error("unable to parse a request: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			logger.FromContext(ctx).Error("error of validation: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		channel, err := c.channelService.UpdateChannel(ctx, code, req.ChannelName, req.Attributes())
+		if isGroupingNotFound(err) {
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error("error while update channel: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to update channel '%s': %v", code, err)})
+			return
+		}
+
+		if channel == nil {
+			ctx.JSON(http.StatusNotFound, common.ErrorResponse{Error: "Channel code not found"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, channel)
+	}
+}
+
 // GetChannels godoc
-// @Summary Get all channels
-// @Description Get all Telegram channels
+// @Summary Get channels
+// @Description Get a page of Telegram channels filtered and sorted by the query parameters
 // @Tags Channels
 // @Accept json
 // @Produce json
-// @Success 200 {array} domain.Channel
+// @Param campaign_id query int false "Campaign ID"
+// @Param source_id query int false "Source ID"
+// @Param tag query []string false "Tags, the channel must have all of them" collectionFormat(multi)
+// @Param created_from query string false "Created at or after, RFC3339"
+// @Param created_to query string false "Created before, RFC3339"
+// @Param name query string false "Name substring, case-insensitive"
+// @Param status query string false "Channel status" Enums(active, expired, full)
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size, 50 by default, at most 500"
+// @Param sort_by query string false "Sort field" Enums(created_at, name)
+// @Param sort_order query string false "Sort order, desc by default" Enums(asc, desc)
+// @Success 200 {object} dto.GetChannelsResponse
+// @Failure 400 {object} common.ErrorResponse
 // @Failure 500 {object} common.ErrorResponse
 // @Security XAuthToken
 // @Router /channels/all [get]
 func (c *ChannelController) GetChannelsHandler() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		channels, err := c.channelService.GetAll()
+		req := dto.NewGetChannelsRequest()
+		if err := req.Parse(ctx); err != nil {
+			logger.FromContext(ctx).Error("unable to parse a request: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			logger.FromContext(ctx).Error("error of validation: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		channels, err := c.channelService.GetChannelsPage(ctx, req.Filter(), req.ToDomain())
+		if errors.Is(err, domain.ErrInvalidCursor) {
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
 		if err != nil {
-			logrus.Error("error while get all channels: ", err)
+			logger.FromContext(ctx).Error("error while get all channels: ", err)
 			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to get all channels: %v", err)})
 			return
 		}
@@ -154,3 +244,7 @@ func (c *ChannelController) GetChannelsHandler() gin.HandlerFunc {
 		ctx.JSON(http.StatusOK, response)
 	}
 }
+
+func isGroupingNotFound(err error) bool {
+	return errors.Is(err, service.ErrCampaignNotFound) || errors.Is(err, service.ErrSourceNotFound)
+}
@@ -0,0 +1,159 @@
+package channel_test
+
+import (
+	"hr-server/internal/api/http/controllers/channel"
+	"hr-server/internal/api/http/controllers/channel/dto"
+	"hr-server/internal/api/http/controllers/controllertest"
+	"hr-server/internal/domain"
+	"hr-server/internal/service/servicetest"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRouter(t *testing.T) (*gin.Engine, *servicetest.Services) {
+	t.Helper()
+
+	s := servicetest.NewServices(servicetest.Config())
+	controller := channel.NewChannelController(s.Channel)
+
+	router := controllertest.NewRouter()
+	router.POST("/channels/generate", controller.GenerateChannelHandler())
+	router.GET("/channels/:code", controller.GetChannelByCodeHandler())
+	router.PUT("/channels/:code", controller.UpdateChannelHandler())
+	router.POST("/channels/bulk", controller.GenerateBulkChannelHandler())
+	router.GET("/channels/all", controller.GetChannelsHandler())
+
+	return router, s
+}
+
+func TestGenerateChannelHandler(t *testing.T) {
+	router, s := newRouter(t)
+
+	campaign, err := s.Campaign.CreateCampaign(t.Context(), "spring-2026")
+	require.NoError(t, err)
+
+	recorder := controllertest.Do(t, router, http.MethodPost, "/channels/generate", gin.H{
+		"channel_name": "hh.ru",
+		"link_type":    domain.ChannelLinkTypeStartApp,
+		"campaign_id":  campaign.ID,
+		"tags":         []string{"Go"},
+		"max_users":    10,
+		"timezone":     "Asia/Vladivostok",
+	})
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	created := controllertest.Decode[domain.Channel](t, recorder)
+	assert.Equal(t, "hh.ru", created.Name)
+	assert.Equal(t, servicetest.BotURL+"?startapp="+created.Code, created.Link)
+	assert.Equal(t, []string{"go"}, created.Tags)
+	assert.Equal(t, &campaign.ID, created.CampaignID)
+	assert.Equal(t, "Asia/Vladivostok", created.Timezone)
+
+	for name, body := range map[string]gin.H{
+		"missing name":     {"link_type": domain.ChannelLinkTypeStart},
+		"unknown type":     {"channel_name": "vk", "link_type": "unknown"},
+		"past expiry":      {"channel_name": "vk", "expires_at": time.Now().Add(-time.Hour)},
+		"missing campaign": {"channel_name": "vk", "campaign_id": 100},
+		"negative cap":     {"channel_name": "vk", "max_users": -1},
+		"unknown timezone": {"channel_name": "vk", "timezone": "Mars/Olympus"},
+	} {
+		recorder := controllertest.Do(t, router, http.MethodPost, "/channels/generate", body)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, name)
+	}
+}
+
+func TestGenerateBulkChannelHandler(t *testing.T) {
+	router, _ := newRouter(t)
+
+	recorder := controllertest.Do(t, router, http.MethodPost, "/channels/bulk", gin.H{"channel_names": []string{"hh.ru", "vk"}})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Len(t, controllertest.Decode[[]*domain.Channel](t, recorder), 2)
+
+	recorder = controllertest.Do(t, router, http.MethodPost, "/channels/bulk", gin.H{"channel_names": []string{"hh.ru"}, "source_id": 100})
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = controllertest.Do(t, router, http.MethodPost, "/channels/bulk", gin.H{})
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestGetChannelByCodeHandler(t *testing.T) {
+	router, s := newRouter(t)
+
+	created, err := s.Channel.GenerateChannel(t.Context(), "hh.ru", domain.ChannelAttributes{})
+	require.NoError(t, err)
+	s.AddUser(t, 1, "alice", &created.ID)
+
+	recorder := controllertest.Do(t, router, http.MethodGet, "/channels/"+created.Code, nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	found := controllertest.Decode[domain.Channel](t, recorder)
+	assert.Equal(t, created.ID, found.ID)
+	assert.EqualValues(t, 1, found.UsersCount)
+
+	recorder = controllertest.Do(t, router, http.MethodGet, "/channels/missing", nil)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestUpdateChannelHandler(t *testing.T) {
+	router, s := newRouter(t)
+
+	created, err := s.Channel.GenerateChannel(t.Context(), "hh.ru", domain.ChannelAttributes{Tags: []string{"old"}})
+	require.NoError(t, err)
+
+	recorder := controllertest.Do(t, router, http.MethodPut, "/channels/"+created.Code, gin.H{"channel_name": "hh", "tags": []string{"new"}})
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	updated := controllertest.Decode[domain.Channel](t, recorder)
+	assert.Equal(t, "hh", updated.Name)
+	assert.Equal(t, []string{"new"}, updated.Tags)
+
+	recorder = controllertest.Do(t, router, http.MethodPut, "/channels/"+created.Code, gin.H{"source_id": 100})
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = controllertest.Do(t, router, http.MethodPut, "/channels/missing", gin.H{"channel_name": "hh"})
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestGetChannelsHandler(t *testing.T) {
+	router, s := newRouter(t)
+
+	source, err := s.Source.CreateSource(t.Context(), "job boards")
+	require.NoError(t, err)
+
+	for _, name := range []string{"hh.ru", "superjob", "vk"} {
+		attrs := domain.ChannelAttributes{Tags: []string{"city:kazan"}}
+		if name != "vk" {
+			attrs.SourceID = &source.ID
+		}
+		_, err := s.Channel.GenerateChannel(t.Context(), name, attrs)
+		require.NoError(t, err)
+	}
+
+	recorder := controllertest.Do(t, router, http.MethodGet, "/channels/all?source_id="+strconv.Itoa(source.ID)+"&tag=city:kazan&sort_by=name&sort_order=asc", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	response := controllertest.Decode[dto.GetChannelsResponse](t, recorder)
+	assert.EqualValues(t, 2, response.TotalCount)
+	require.Len(t, response.Channels, 2)
+	assert.Equal(t, "hh.ru", response.Channels[0].Name)
+	assert.Equal(t, "superjob", response.Channels[1].Name)
+
+	recorder = controllertest.Do(t, router, http.MethodGet, "/channels/all?limit=1", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	response = controllertest.Decode[dto.GetChannelsResponse](t, recorder)
+	require.Len(t, response.Channels, 1)
+	assert.Equal(t, "vk", response.Channels[0].Name, "newest first by default")
+	assert.NotEmpty(t, response.NextCursor)
+
+	for _, query := range []string{"cursor=garbage", "status=unknown", "sort_by=code"} {
+		recorder := controllertest.Do(t, router, http.MethodGet, "/channels/all?"+query, nil)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, query)
+	}
+}
@@ -2,13 +2,23 @@ package dto
 
 import (
 	"fmt"
+	"hr-server/internal/api/http/controllers/common"
+	"hr-server/internal/domain"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	validation "github.com/go-ozzo/ozzo-validation"
 )
 
 type GenerateBulkChannelRequest struct {
-	ChannelNames []string `json:"channel_names"`
+	ChannelNames []string   `json:"channel_names"`
+	LinkType     string     `json:"link_type" example:"start" enums:"start,startapp"`
+	CampaignID   *int       `json:"campaign_id,omitempty"`
+	SourceID     *int       `json:"source_id,omitempty"`
+	Tags         []string   `json:"tags,omitempty" example:"city:kazan"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty" example:"2026-06-01T00:00:00Z"`
+	MaxUsers     *int       `json:"max_users,omitempty" example:"500"`
+	Timezone     string     `json:"timezone,omitempty" example:"Asia/Vladivostok"` // region of the audience, the default timezone of the users
 }
 
 func NewGenerateBulkChannelRequest() *GenerateBulkChannelRequest {
@@ -25,6 +35,13 @@ func (r *GenerateBulkChannelRequest) Validate() error {
 			validation.Required.Error("channel names array is required"),
 			validation.Length(1, 100).Error("must have between 1 and 100 channel names"),
 		),
+		validation.Field(&r.LinkType, linkTypeRule),
+		validation.Field(&r.CampaignID, validation.Min(1)),
+		validation.Field(&r.SourceID, validation.Min(1)),
+		validation.Field(&r.Tags, tagsRule),
+		validation.Field(&r.ExpiresAt, futureTimeRule),
+		validation.Field(&r.MaxUsers, validation.Min(1)),
+		validation.Field(&r.Timezone, common.TimezoneRule),
 	)
 	if err != nil {
 		return err
@@ -39,3 +56,15 @@ func (r *GenerateBulkChannelRequest) Validate() error {
 
 	return nil
 }
+
+func (r *GenerateBulkChannelRequest) Attributes() domain.ChannelAttributes {
+	return domain.ChannelAttributes{
+		LinkType:   r.LinkType,
+		CampaignID: r.CampaignID,
+		SourceID:   r.SourceID,
+		Tags:       r.Tags,
+		ExpiresAt:  r.ExpiresAt,
+		MaxUsers:   r.MaxUsers,
+		Timezone:   r.Timezone,
+	}
+}
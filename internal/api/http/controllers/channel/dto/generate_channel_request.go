@@ -1,12 +1,24 @@
 package dto
 
 import (
+	"fmt"
+	"hr-server/internal/api/http/controllers/common"
+	"hr-server/internal/domain"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	validation "github.com/go-ozzo/ozzo-validation"
 )
 
 type GenerateChannelRequest struct {
-	ChannelName string `json:"channel_name"`
+	ChannelName string     `json:"channel_name"`
+	LinkType    string     `json:"link_type" example:"start" enums:"start,startapp"`
+	CampaignID  *int       `json:"campaign_id,omitempty"`
+	SourceID    *int       `json:"source_id,omitempty"`
+	Tags        []string   `json:"tags,omitempty" example:"city:kazan"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" example:"2026-06-01T00:00:00Z"`
+	MaxUsers    *int       `json:"max_users,omitempty" example:"500"`
+	Timezone    string     `json:"timezone,omitempty" example:"Asia/Vladivostok"` // region of the audience, the default timezone of the users
 }
 
 func NewGenerateChannelRequest() *GenerateChannelRequest {
@@ -20,6 +32,13 @@ func (r *GenerateChannelRequest) Parse(c *gin.Context) error {
 func (r *GenerateChannelRequest) Validate() error {
 	err := validation.ValidateStruct(r,
 		validation.Field(&r.ChannelName, validation.Required.Error("is required")),
+		validation.Field(&r.LinkType, linkTypeRule),
+		validation.Field(&r.CampaignID, validation.Min(1)),
+		validation.Field(&r.SourceID, validation.Min(1)),
+		validation.Field(&r.Tags, tagsRule),
+		validation.Field(&r.ExpiresAt, futureTimeRule),
+		validation.Field(&r.MaxUsers, validation.Min(1)),
+		validation.Field(&r.Timezone, common.TimezoneRule),
 	)
 	if err != nil {
 		return err
@@ -27,3 +46,51 @@ func (r *GenerateChannelRequest) Validate() error {
 
 	return nil
 }
+
+func (r *GenerateChannelRequest) Attributes() domain.ChannelAttributes {
+	return domain.ChannelAttributes{
+		LinkType:   r.LinkType,
+		CampaignID: r.CampaignID,
+		SourceID:   r.SourceID,
+		Tags:       r.Tags,
+		ExpiresAt:  r.ExpiresAt,
+		MaxUsers:   r.MaxUsers,
+		Timezone:   r.Timezone,
+	}
+}
+
+// linkTypeRule allows an empty link type, which defaults to "start"
+var linkTypeRule = validation.In(
+	domain.ChannelLinkTypeStart,
+	domain.ChannelLinkTypeStartApp,
+).Error("must be either 'start' or 'startapp'")
+
+const (
+	maxTagsCount  = 20
+	maxTagsLength = 100
+)
+
+var tagsRule = validation.By(func(value interface{}) error {
+	tags, _ := value.([]string)
+	if len(tags) > maxTagsCount {
+		return fmt.Errorf("must have at most %d tags", maxTagsCount)
+	}
+
+	for i, tag := range tags {
+		if len(tag) > maxTagsLength {
+			return fmt.Errorf("tag at index %d must be at most %d characters long", i, maxTagsLength)
+		}
+	}
+
+	return nil
+})
+
+// futureTimeRule requires an optional time to be in the future
+var futureTimeRule = validation.By(func(value interface{}) error {
+	t, _ := value.(*time.Time)
+	if t != nil && !t.After(time.Now()) {
+		return fmt.Errorf("must be in the future")
+	}
+
+	return nil
+})
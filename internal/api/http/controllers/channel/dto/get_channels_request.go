@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"hr-server/internal/api/http/controllers/common"
+	"hr-server/internal/domain"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+type GetChannelsRequest struct {
+	common.PageRequest
+
+	CampaignID  *int       `form:"campaign_id"`
+	SourceID    *int       `form:"source_id"`
+	Tags        []string   `form:"tag"`
+	CreatedFrom *time.Time `form:"created_from" example:"2026-01-01T00:00:00Z"`
+	CreatedTo   *time.Time `form:"created_to" example:"2026-02-01T00:00:00Z"`
+	Name        string     `form:"name"`
+	Status      string     `form:"status" enums:"active,expired,full"`
+}
+
+func NewGetChannelsRequest() *GetChannelsRequest {
+	return &GetChannelsRequest{}
+}
+
+func (r *GetChannelsRequest) Parse(c *gin.Context) error {
+	return c.ShouldBindQuery(r)
+}
+
+func (r *GetChannelsRequest) Validate() error {
+	err := validation.ValidateStruct(r,
+		validation.Field(&r.CampaignID, validation.Min(1)),
+		validation.Field(&r.SourceID, validation.Min(1)),
+		validation.Field(&r.Tags, tagsRule),
+		validation.Field(&r.Name, validation.Length(0, 255)),
+		validation.Field(&r.Status, validation.In(
+			domain.ChannelStatusActive,
+			domain.ChannelStatusExpired,
+			domain.ChannelStatusFull,
+		)),
+	)
+	if err != nil {
+		return err
+	}
+
+	return r.ValidatePage("created_at", "name")
+}
+
+func (r *GetChannelsRequest) Filter() domain.ChannelFilter {
+	return domain.ChannelFilter{
+		CampaignID: r.CampaignID,
+		SourceID:   r.SourceID,
+		Tags:       r.Tags,
+		CreatedAt:  domain.TimeRange{From: r.CreatedFrom, To: r.CreatedTo},
+		Name:       r.Name,
+		Status:     r.Status,
+	}
+}
@@ -5,11 +5,15 @@ import (
 )
 
 type GetChannelsResponse struct {
-	Channels []*domain.Channel `json:"channels"`
+	Channels   []*domain.Channel `json:"channels"`
+	TotalCount int64             `json:"total_count"`
+	NextCursor string            `json:"next_cursor,omitempty"`
 }
 
-func NewGetChannelsResponse(channels []*domain.Channel) *GetChannelsResponse {
+func NewGetChannelsResponse(page *domain.Page[*domain.Channel]) *GetChannelsResponse {
 	return &GetChannelsResponse{
-		Channels: channels,
+		Channels:   page.Items,
+		TotalCount: page.TotalCount,
+		NextCursor: page.NextCursor,
 	}
 }
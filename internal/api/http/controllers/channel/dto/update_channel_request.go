@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"hr-server/internal/api/http/controllers/common"
+	"hr-server/internal/domain"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// UpdateChannelRequest replaces the attributes of the channel, omitted campaign, source, tags, expiry, cap or timezone are cleared
+type UpdateChannelRequest struct {
+	ChannelName string     `json:"channel_name,omitempty"`
+	CampaignID  *int       `json:"campaign_id,omitempty"`
+	SourceID    *int       `json:"source_id,omitempty"`
+	Tags        []string   `json:"tags,omitempty" example:"city:kazan"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" example:"2026-06-01T00:00:00Z"`
+	MaxUsers    *int       `json:"max_users,omitempty" example:"500"`
+	Timezone    string     `json:"timezone,omitempty" example:"Asia/Vladivostok"` // region of the audience, the default timezone of the users
+}
+
+func NewUpdateChannelRequest() *UpdateChannelRequest {
+	return &UpdateChannelRequest{}
+}
+
+func (r *UpdateChannelRequest) Parse(c *gin.Context) error {
+	return c.ShouldBindJSON(&r)
+}
+
+func (r *UpdateChannelRequest) Validate() error {
+	err := validation.ValidateStruct(r,
+		validation.Field(&r.CampaignID, validation.Min(1)),
+		validation.Field(&r.SourceID, validation.Min(1)),
+		validation.Field(&r.Tags, tagsRule),
+		validation.Field(&r.MaxUsers, validation.Min(1)),
+		validation.Field(&r.Timezone, common.TimezoneRule),
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *UpdateChannelRequest) Attributes() domain.ChannelAttributes {
+	return domain.ChannelAttributes{
+		CampaignID: r.CampaignID,
+		SourceID:   r.SourceID,
+		Tags:       r.Tags,
+		ExpiresAt:  r.ExpiresAt,
+		MaxUsers:   r.MaxUsers,
+		Timezone:   r.Timezone,
+	}
+}
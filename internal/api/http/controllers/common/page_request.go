@@ -0,0 +1,35 @@
+package common
+
+import (
+	"hr-server/internal/domain"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+const maxPageLimit = 500
+
+// PageRequest represents cursor-based pagination and sorting query parameters
+type PageRequest struct {
+	Cursor    string `form:"cursor"`
+	Limit     int    `form:"limit" example:"50"`
+	SortBy    string `form:"sort_by"`
+	SortOrder string `form:"sort_order" enums:"asc,desc"`
+}
+
+// ValidatePage validates the page request against the sort fields supported by the list
+func (r *PageRequest) ValidatePage(sortFields ...interface{}) error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.Limit, validation.Min(0), validation.Max(maxPageLimit)),
+		validation.Field(&r.SortBy, validation.In(sortFields...)),
+		validation.Field(&r.SortOrder, validation.In(domain.SortOrderAsc, domain.SortOrderDesc)),
+	)
+}
+
+func (r *PageRequest) ToDomain() domain.PageRequest {
+	return domain.PageRequest{
+		Cursor:    r.Cursor,
+		Limit:     r.Limit,
+		SortBy:    r.SortBy,
+		SortOrder: r.SortOrder,
+	}
+}
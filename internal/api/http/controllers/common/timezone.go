@@ -0,0 +1,32 @@
+package common
+
+import (
+	"errors"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// TimezoneRule accepts an empty value or an IANA timezone name like Europe/Moscow
+var TimezoneRule = validation.By(func(value interface{}) error {
+	var name string
+	switch v := value.(type) {
+	case string:
+		name = v
+	case *string:
+		if v != nil {
+			name = *v
+		}
+	}
+
+	if name == "" {
+		return nil
+	}
+
+	// "Local" depends on the server, not on the recipient
+	if _, err := time.LoadLocation(name); err != nil || name == "Local" {
+		return errors.New("must be an IANA timezone name like Europe/Moscow")
+	}
+
+	return nil
+})
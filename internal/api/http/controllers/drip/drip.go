@@ -0,0 +1,210 @@
+package drip
+
+import (
+	"errors"
+	"fmt"
+	"hr-server/internal/api/http/controllers/common"
+	"hr-server/internal/api/http/controllers/drip/dto"
+	"hr-server/internal/domain"
+	"hr-server/internal/logger"
+	"hr-server/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DripController struct {
+	dripService *service.DripService
+}
+
+func NewDripController(dripService *service.DripService) *DripController {
+	return &DripController{dripService}
+}
+
+// CreateDripSequence godoc
+// @Summary Create a drip sequence
+// @Description Create an active sequence of messages sent to the new users after their first /start. The delays are counted from the /start, a step is skipped if its condition doesn't hold: no_reply skips it if the user wrote to the bot or pressed a button since the /start, replied sends it only then. The messages are templates like the broadcast messages. The steps can't be changed later
+// @Tags Drip Sequences
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateDripSequenceRequest true "Create drip sequence request"
+// @Success 200 {object} domain.DripSequence
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /drips [post]
+func (c *DripController) CreateDripSequenceHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		req := dto.NewCreateDripSequenceRequest()
+		if err := req.Parse(ctx); err != nil {
+			logger.FromContext(ctx).Error("unable to parse a request: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			logger.FromContext(ctx).Error("error of validation: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		sequence, err := c.dripService.CreateSequence(ctx, req.ToDomain())
+		if errors.Is(err, service.ErrInvalidMessageTemplate) || errors.Is(err, service.ErrChannelNotFound) {
+			logger.FromContext(ctx).Error("error of validation: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error("error while create drip sequence: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to create drip sequence: %v", err)})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, sequence)
+	}
+}
+
+// GetDripSequences godoc
+// @Summary Get drip sequences
+// @Description Get all drip sequences with the sent, skipped and failed counts of the steps and the enrollments by status
+// @Tags Drip Sequences
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.GetDripSequencesResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /drips [get]
+func (c *DripController) GetDripSequencesHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		sequences, err := c.dripService.GetSequences(ctx)
+		if err != nil {
+			logger.FromContext(ctx).Error("error while get drip sequences: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to get drip sequences: %v", err)})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, dto.NewGetDripSequencesResponse(sequences))
+	}
+}
+
+// GetDripSequence godoc
+// @Summary Get a drip sequence
+// @Description Get a drip sequence with the counts of its steps and enrollments
+// @Tags Drip Sequences
+// @Accept json
+// @Produce json
+// @Param id path int true "Drip sequence ID"
+// @Success 200 {object} domain.DripSequence
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /drips/{id} [get]
+func (c *DripController) GetDripSequenceHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, ok := parseID(ctx)
+		if !ok {
+			return
+		}
+
+		sequence, err := c.dripService.GetSequence(ctx, id)
+		respondSequence(ctx, sequence, err, "get drip sequence")
+	}
+}
+
+// UpdateDripSequence godoc
+// @Summary Enable or disable a drip sequence
+// @Description A disabled sequence neither enrolls new users nor sends messages, its enrollments are kept and the overdue steps are sent once it's enabled again
+// @Tags Drip Sequences
+// @Accept json
+// @Produce json
+// @Param id path int true "Drip sequence ID"
+// @Param request body dto.UpdateDripSequenceRequest true "Update drip sequence request"
+// @Success 200 {object} domain.DripSequence
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /drips/{id} [patch]
+func (c *DripController) UpdateDripSequenceHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, ok := parseID(ctx)
+		if !ok {
+			return
+		}
+
+		req := dto.NewUpdateDripSequenceRequest()
+		if err := req.Parse(ctx); err != nil {
+			logger.FromContext(ctx).Error("unable to parse a request: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			logger.FromContext(ctx).Error("error of validation: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		sequence, err := c.dripService.SetSequenceActive(ctx, id, *req.Active)
+		respondSequence(ctx, sequence, err, "update drip sequence")
+	}
+}
+
+// DeleteDripSequence godoc
+// @Summary Delete a drip sequence
+// @Description Delete a drip sequence with its enrollments, the remaining steps aren't sent
+// @Tags Drip Sequences
+// @Accept json
+// @Produce json
+// @Param id path int true "Drip sequence ID"
+// @Success 200 {object} common.SuccessResponse
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /drips/{id} [delete]
+func (c *DripController) DeleteDripSequenceHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, ok := parseID(ctx)
+		if !ok {
+			return
+		}
+
+		err := c.dripService.DeleteSequence(ctx, id)
+		if errors.Is(err, service.ErrDripSequenceNotFound) {
+			ctx.JSON(http.StatusNotFound, common.ErrorResponse{Error: "Drip sequence not found"})
+			return
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error("error while delete drip sequence: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to delete drip sequence %d: %v", id, err)})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, common.SuccessResponse{})
+	}
+}
+
+func parseID(ctx *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id <= 0 {
+		ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: "id must be a positive integer"})
+		return 0, false
+	}
+
+	return id, true
+}
+
+func respondSequence(ctx *gin.Context, sequence *domain.DripSequence, err error, action string) {
+	switch {
+	case errors.Is(err, service.ErrDripSequenceNotFound):
+		ctx.JSON(http.StatusNotFound, common.ErrorResponse{Error: "Drip sequence not found"})
+	case err != nil:
+		logger.FromContext(ctx).Error("error while "+action+": ", err)
+		ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to %s: %v", action, err)})
+	default:
+		ctx.JSON(http.StatusOK, sequence)
+	}
+}
@@ -0,0 +1,97 @@
+package drip_test
+
+import (
+	"hr-server/internal/api/http/controllers/controllertest"
+	"hr-server/internal/api/http/controllers/drip"
+	"hr-server/internal/api/http/controllers/drip/dto"
+	"hr-server/internal/domain"
+	"hr-server/internal/service/servicetest"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRouter(s *servicetest.Services) *gin.Engine {
+	controller := drip.NewDripController(s.Drip)
+
+	router := controllertest.NewRouter()
+	router.POST("/drips", controller.CreateDripSequenceHandler())
+	router.GET("/drips", controller.GetDripSequencesHandler())
+	router.GET("/drips/:id", controller.GetDripSequenceHandler())
+	router.PATCH("/drips/:id", controller.UpdateDripSequenceHandler())
+	router.DELETE("/drips/:id", controller.DeleteDripSequenceHandler())
+
+	return router
+}
+
+func TestDripSequenceHandlers(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+	router := newRouter(s)
+
+	recorder := controllertest.Do(t, router, http.MethodPost, "/drips", gin.H{
+		"name": "onboarding",
+		"steps": []gin.H{
+			{"message": "Welcome"},
+			{"delay_minutes": 1440, "message": "Any questions?", "condition": "no_reply"},
+		},
+	})
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	sequence := controllertest.Decode[domain.DripSequence](t, recorder)
+	assert.True(t, sequence.Active)
+	require.Len(t, sequence.Steps, 2)
+	assert.Equal(t, domain.DripConditionAlways, sequence.Steps[0].Condition)
+	path := "/drips/" + strconv.Itoa(sequence.ID)
+
+	recorder = controllertest.Do(t, router, http.MethodGet, "/drips", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Len(t, controllertest.Decode[dto.GetDripSequencesResponse](t, recorder).Sequences, 1)
+
+	recorder = controllertest.Do(t, router, http.MethodPatch, path, gin.H{"active": false})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.False(t, controllertest.Decode[domain.DripSequence](t, recorder).Active)
+
+	recorder = controllertest.Do(t, router, http.MethodGet, path, nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "onboarding", controllertest.Decode[domain.DripSequence](t, recorder).Name)
+
+	recorder = controllertest.Do(t, router, http.MethodDelete, path, nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = controllertest.Do(t, router, http.MethodGet, path, nil)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	recorder = controllertest.Do(t, router, http.MethodPatch, path, gin.H{"active": true})
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	recorder = controllertest.Do(t, router, http.MethodDelete, path, nil)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestCreateDripSequenceHandler_Validation(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+	router := newRouter(s)
+
+	for name, body := range map[string]gin.H{
+		"missing name":      {"steps": []gin.H{{"message": "hi"}}},
+		"no steps":          {"name": "empty", "steps": []gin.H{}},
+		"missing message":   {"name": "n", "steps": []gin.H{{"delay_minutes": 10}}},
+		"negative delay":    {"name": "n", "steps": []gin.H{{"message": "hi", "delay_minutes": -1}}},
+		"decreasing delays": {"name": "n", "steps": []gin.H{{"message": "a", "delay_minutes": 60}, {"message": "b", "delay_minutes": 30}}},
+		"unknown condition": {"name": "n", "steps": []gin.H{{"message": "hi", "condition": "clicked"}}},
+		"invalid template":  {"name": "n", "steps": []gin.H{{"message": "Hi, {{.Nme}}"}}},
+		"unknown channel":   {"name": "n", "channel_id": 42, "steps": []gin.H{{"message": "hi"}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			recorder := controllertest.Do(t, router, http.MethodPost, "/drips", body)
+			assert.Equal(t, http.StatusBadRequest, recorder.Code, recorder.Body.String())
+		})
+	}
+
+	recorder := controllertest.Do(t, router, http.MethodPatch, "/drips/1", gin.H{})
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	recorder = controllertest.Do(t, router, http.MethodGet, "/drips/abc", nil)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
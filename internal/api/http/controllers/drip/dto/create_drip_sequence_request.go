@@ -0,0 +1,81 @@
+package dto
+
+import (
+	"fmt"
+	"hr-server/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// maxDripDelayMinutes limits the delay of a step to a year
+const maxDripDelayMinutes = 365 * 24 * 60
+
+type CreateDripSequenceRequest struct {
+	Name      string            `json:"name" example:"onboarding"`
+	ChannelID *int              `json:"channel_id,omitempty"` // only the users of the channel are enrolled, all new users if omitted
+	Steps     []DripStepRequest `json:"steps"`
+}
+
+type DripStepRequest struct {
+	DelayMinutes int    `json:"delay_minutes" example:"1440"` // since the first /start, not decreasing between the steps
+	Message      string `json:"message"`
+	Condition    string `json:"condition,omitempty" enums:"always,no_reply,replied"` // always by default
+}
+
+func (r DripStepRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.DelayMinutes, validation.Min(0), validation.Max(maxDripDelayMinutes)),
+		validation.Field(&r.Message, validation.Required.Error("is required")),
+		validation.Field(&r.Condition, validation.In(domain.DripConditionAlways, domain.DripConditionNoReply, domain.DripConditionReplied)),
+	)
+}
+
+func NewCreateDripSequenceRequest() *CreateDripSequenceRequest {
+	return &CreateDripSequenceRequest{}
+}
+
+func (r *CreateDripSequenceRequest) Parse(c *gin.Context) error {
+	return c.ShouldBindJSON(&r)
+}
+
+func (r *CreateDripSequenceRequest) Validate() error {
+	err := validation.ValidateStruct(r,
+		validation.Field(&r.Name, validation.Required.Error("is required"), validation.Length(1, 255)),
+		validation.Field(&r.ChannelID, validation.Min(1)),
+		validation.Field(&r.Steps, validation.Required.Error("at least one step is required"), validation.Length(1, domain.MaxDripSteps)),
+	)
+	if err != nil {
+		return err
+	}
+
+	for i := 1; i < len(r.Steps); i++ {
+		if r.Steps[i].DelayMinutes < r.Steps[i-1].DelayMinutes {
+			return fmt.Errorf("steps: delay_minutes of step %d is less than of the previous step", i)
+		}
+	}
+
+	return nil
+}
+
+func (r *CreateDripSequenceRequest) ToDomain() *domain.DripSequence {
+	sequence := &domain.DripSequence{
+		Name:      r.Name,
+		ChannelID: r.ChannelID,
+	}
+
+	for _, step := range r.Steps {
+		condition := step.Condition
+		if condition == "" {
+			condition = domain.DripConditionAlways
+		}
+
+		sequence.Steps = append(sequence.Steps, domain.DripStep{
+			DelayMinutes: step.DelayMinutes,
+			Message:      step.Message,
+			Condition:    condition,
+		})
+	}
+
+	return sequence
+}
@@ -0,0 +1,13 @@
+package dto
+
+import "hr-server/internal/domain"
+
+type GetDripSequencesResponse struct {
+	Sequences []*domain.DripSequence `json:"sequences"`
+}
+
+func NewGetDripSequencesResponse(sequences []*domain.DripSequence) *GetDripSequencesResponse {
+	return &GetDripSequencesResponse{
+		Sequences: sequences,
+	}
+}
@@ -0,0 +1,24 @@
+package dto
+
+import (
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+type UpdateDripSequenceRequest struct {
+	Active *bool `json:"active"` // inactive sequences neither enroll users nor send messages
+}
+
+func NewUpdateDripSequenceRequest() *UpdateDripSequenceRequest {
+	return &UpdateDripSequenceRequest{}
+}
+
+func (r *UpdateDripSequenceRequest) Parse(c *gin.Context) error {
+	return c.ShouldBindJSON(&r)
+}
+
+func (r *UpdateDripSequenceRequest) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.Active, validation.NotNil.Error("is required")),
+	)
+}
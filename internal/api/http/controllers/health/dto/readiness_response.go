@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"hr-server/internal/domain"
+	"time"
+)
+
+// ReadinessResponse is the readiness report of the public probe, the errors and details of the components are only logged
+type ReadinessResponse struct {
+	Status     string                      `json:"status"`
+	Components map[string]*ComponentStatus `json:"components"`
+	CheckedAt  time.Time                   `json:"checked_at"`
+}
+
+type ComponentStatus struct {
+	Status string `json:"status"`
+}
+
+func NewReadinessResponse(report *domain.HealthReport) *ReadinessResponse {
+	components := make(map[string]*ComponentStatus, len(report.Components))
+	for name, component := range report.Components {
+		components[name] = &ComponentStatus{Status: component.Status}
+	}
+
+	return &ReadinessResponse{
+		Status:     report.Status,
+		Components: components,
+		CheckedAt:  report.CheckedAt,
+	}
+}
@@ -0,0 +1,64 @@
+package health
+
+import (
+	"hr-server/internal/api/http/controllers/health/dto"
+	"hr-server/internal/domain"
+	"hr-server/internal/logger"
+	"hr-server/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	healthService *service.HealthService
+}
+
+func NewHealthController(healthService *service.HealthService) *HealthController {
+	return &HealthController{healthService}
+}
+
+// Liveness godoc
+// @Summary Liveness probe
+// @Description Returns 200 while the HTTP server is able to respond, dependencies are not checked
+// @Tags Health
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /health/live [get]
+func (c *HealthController) LivenessHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{
+			"status":  http.StatusOK,
+			"message": "pong",
+		})
+	}
+}
+
+// Readiness godoc
+// @Summary Readiness probe
+// @Description Checks the database, the Telegram updates loop and the broadcast workers, returns 503 if any of them is unavailable.
+// @Description The probe is public, so only the statuses are returned and the errors are logged.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} dto.ReadinessResponse
+// @Failure 503 {object} dto.ReadinessResponse
+// @Router /health/ready [get]
+func (c *HealthController) ReadinessHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		report := c.healthService.CheckReadiness(ctx)
+
+		status := http.StatusOK
+		if report.Status != domain.HealthStatusOK {
+			status = http.StatusServiceUnavailable
+		}
+
+		for name, component := range report.Components {
+			if component.Status != domain.HealthStatusOK {
+				logger.FromContext(ctx).WithFields(component.Details).WithField("component", name).
+					Error("readiness check failed: ", component.Error)
+			}
+		}
+
+		ctx.JSON(status, dto.NewReadinessResponse(report))
+	}
+}
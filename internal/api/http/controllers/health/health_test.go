@@ -0,0 +1,79 @@
+package health_test
+
+import (
+	"errors"
+	"hr-server/internal/api/http/controllers/controllertest"
+	"hr-server/internal/api/http/controllers/health"
+	"hr-server/internal/api/http/controllers/health/dto"
+	"hr-server/internal/domain"
+	"hr-server/internal/service/servicetest"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLivenessHandler(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+	s.Database.SetError(errors.New("connection refused"))
+
+	router := controllertest.NewRouter()
+	router.GET("/health/live", health.NewHealthController(s.Health).LivenessHandler())
+
+	recorder := controllertest.Do(t, router, http.MethodGet, "/health/live", nil)
+	assert.Equal(t, http.StatusOK, recorder.Code, "liveness doesn't check the dependencies")
+}
+
+func TestReadinessHandler(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+	s.RunNotifications(t)
+
+	router := controllertest.NewRouter()
+	router.GET("/health/ready", health.NewHealthController(s.Health).ReadinessHandler())
+
+	recorder := controllertest.Do(t, router, http.MethodGet, "/health/ready", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	report := controllertest.Decode[dto.ReadinessResponse](t, recorder)
+	assert.Equal(t, domain.HealthStatusOK, report.Status)
+	for name, component := range report.Components {
+		assert.Equal(t, domain.HealthStatusOK, component.Status, name)
+	}
+
+	t.Run("database is down", func(t *testing.T) {
+		s.Database.SetError(errors.New("connection refused"))
+		defer s.Database.SetError(nil)
+
+		recorder := controllertest.Do(t, router, http.MethodGet, "/health/ready", nil)
+		require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+		report := controllertest.Decode[dto.ReadinessResponse](t, recorder)
+		assert.Equal(t, domain.HealthStatusUnavailable, report.Components["database"].Status)
+		assert.Equal(t, domain.HealthStatusOK, report.Components["telegram"].Status)
+		assert.NotContains(t, recorder.Body.String(), "connection refused", "the errors are only logged")
+		assert.NotContains(t, recorder.Body.String(), "open_connections", "the details are only logged")
+	})
+
+	t.Run("bot is stopped", func(t *testing.T) {
+		s.Bot.SetRunning(false)
+		defer s.Bot.SetRunning(true)
+
+		recorder := controllertest.Do(t, router, http.MethodGet, "/health/ready", nil)
+		require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		assert.Equal(t, domain.HealthStatusUnavailable, controllertest.Decode[dto.ReadinessResponse](t, recorder).Components["telegram"].Status)
+	})
+
+	t.Run("notification workers are not running", func(t *testing.T) {
+		stopped := servicetest.NewServices(servicetest.Config())
+		router := controllertest.NewRouter()
+		router.GET("/health/ready", health.NewHealthController(stopped.Health).ReadinessHandler())
+
+		recorder := controllertest.Do(t, router, http.MethodGet, "/health/ready", nil)
+		require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+		report := controllertest.Decode[dto.ReadinessResponse](t, recorder)
+		assert.Equal(t, domain.HealthStatusUnavailable, report.Components["broadcasts"].Status)
+		assert.Equal(t, domain.HealthStatusOK, report.Components["telegram"].Status)
+	})
+}
@@ -0,0 +1,15 @@
+package dto
+
+import (
+	"hr-server/internal/domain"
+)
+
+type GetBroadcastsResponse struct {
+	Broadcasts []*domain.Broadcast `json:"broadcasts"`
+}
+
+func NewGetBroadcastsResponse(broadcasts []*domain.Broadcast) *GetBroadcastsResponse {
+	return &GetBroadcastsResponse{
+		Broadcasts: broadcasts,
+	}
+}
@@ -1,7 +1,9 @@
 package dto
 
 import (
+	"errors"
 	"fmt"
+	"hr-server/internal/domain"
 	"net/url"
 	"strings"
 
@@ -10,8 +12,36 @@ import (
 )
 
 type SendNotificationRequest struct {
-	Message  string  `json:"message"`
-	ImageURL *string `json:"image_url,omitempty"`
+	Message    string  `json:"message"`
+	ImageURL   *string `json:"image_url,omitempty"`
+	ButtonText string  `json:"button_text,omitempty"` // inline button counting the responses
+	ButtonURL  string  `json:"button_url,omitempty"`  // makes the button open the link, requires button_text
+
+	Priority       string `json:"priority,omitempty" enums:"transactional,marketing"` // marketing by default
+	MaxConcurrency int    `json:"max_concurrency,omitempty"`                          // messages sent at once, all workers by default
+	Delivery       string `json:"delivery,omitempty" enums:"immediate,local_window"`  // local_window holds the messages during the quiet hours of the recipients
+
+	// Variants of the message for an A/B test, the message must be empty then
+	Variants []NotificationVariantRequest `json:"variants,omitempty"`
+
+	// TrackLinks replaces the links of the messages and the button with tracked redirects
+	TrackLinks bool `json:"track_links,omitempty"`
+}
+
+type NotificationVariantRequest struct {
+	Message    string `json:"message"`
+	Weight     int    `json:"weight" example:"1"`
+	ButtonText string `json:"button_text,omitempty"`
+	ButtonURL  string `json:"button_url,omitempty"`
+}
+
+func (r NotificationVariantRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Message, validation.Required.Error("is required")),
+		validation.Field(&r.Weight, validation.Required.Error("is required"), validation.Min(1), validation.Max(100)),
+		validation.Field(&r.ButtonText, validation.Length(0, 64)),
+		validation.Field(&r.ButtonURL, validation.By(validateButtonURL(r.ButtonText))),
+	)
 }
 
 func NewSendNotificationRequest() *SendNotificationRequest {
@@ -23,9 +53,25 @@ func (r *SendNotificationRequest) Parse(c *gin.Context) error {
 }
 
 func (r *SendNotificationRequest) Validate() error {
+	messageRule := validation.Rule(validation.Required.Error("is required"))
+	if len(r.Variants) > 0 {
+		messageRule = validation.By(func(value interface{}) error {
+			if strings.TrimSpace(value.(string)) != "" {
+				return errors.New("must be empty when variants are given")
+			}
+			return nil
+		})
+	}
+
 	err := validation.ValidateStruct(r,
-		validation.Field(&r.Message, validation.Required.Error("is required")),
+		validation.Field(&r.Message, messageRule),
 		validation.Field(&r.ImageURL, validation.By(validateImageURL)),
+		validation.Field(&r.ButtonText, validation.Length(0, 64)),
+		validation.Field(&r.ButtonURL, validation.By(validateButtonURL(r.ButtonText))),
+		validation.Field(&r.Variants, validation.Length(2, domain.MaxNotificationVariants)),
+		validation.Field(&r.Priority, validation.In(domain.NotificationPriorityTransactional, domain.NotificationPriorityMarketing)),
+		validation.Field(&r.MaxConcurrency, validation.Min(0)),
+		validation.Field(&r.Delivery, validation.In(domain.NotificationDeliveryImmediate, domain.NotificationDeliveryLocalWindow)),
 	)
 	if err != nil {
 		return err
@@ -34,6 +80,30 @@ func (r *SendNotificationRequest) Validate() error {
 	return nil
 }
 
+func (r *SendNotificationRequest) ToDomain() *domain.NotificationData {
+	data := &domain.NotificationData{
+		Message:        r.Message,
+		ImageURL:       r.ImageURL,
+		ButtonText:     r.ButtonText,
+		ButtonURL:      r.ButtonURL,
+		Priority:       r.Priority,
+		MaxConcurrency: r.MaxConcurrency,
+		Delivery:       r.Delivery,
+		TrackLinks:     r.TrackLinks,
+	}
+
+	for _, variant := range r.Variants {
+		data.Variants = append(data.Variants, domain.NotificationVariant{
+			Message:    variant.Message,
+			Weight:     variant.Weight,
+			ButtonText: variant.ButtonText,
+			ButtonURL:  variant.ButtonURL,
+		})
+	}
+
+	return data
+}
+
 // validateImageURL validates that the image URL is properly formatted
 func validateImageURL(value interface{}) error {
 	if value == nil {
@@ -73,3 +143,24 @@ func validateImageURL(value interface{}) error {
 
 	return nil
 }
+
+// validateButtonURL validates the optional link of the button, the button must have a text then
+func validateButtonURL(buttonText string) validation.RuleFunc {
+	return func(value interface{}) error {
+		urlStr := value.(string)
+		if urlStr == "" {
+			return nil
+		}
+
+		if strings.TrimSpace(buttonText) == "" {
+			return errors.New("requires button_text")
+		}
+
+		parsedURL, err := url.Parse(urlStr)
+		if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+			return errors.New("must be HTTP or HTTPS URL")
+		}
+
+		return nil
+	}
+}
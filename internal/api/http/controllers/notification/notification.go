@@ -1,15 +1,16 @@
 package notification
 
 import (
+	"errors"
 	"fmt"
 	"hr-server/internal/api/http/controllers/common"
 	"hr-server/internal/api/http/controllers/notification/dto"
 	"hr-server/internal/domain"
+	"hr-server/internal/logger"
 	"hr-server/internal/service"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 )
 
 type NotificationController struct {
@@ -22,43 +23,203 @@ func NewNotificationController(notificationService *service.NotificationService)
 
 // SendNotification godoc
 // @Summary Send notification to all users
-// @Description Send a notification message to all users
+// @Description Start a broadcast of a notification message to all users. Transactional broadcasts are sent before the marketing ones, max_concurrency limits the workers used by the broadcast. The message may have the placeholders {{.Username}}, {{.FirstName}} and {{.ChannelName}} with fallbacks like {{.FirstName | default "друг"}}. Variants split the recipients for an A/B test by the hash of the Telegram ID, the button presses of each variant are counted. track_links replaces the links of the messages and button_url with tracked redirects, it requires TRACKING_BASE_URL and TRACKING_SECRET. delivery local_window holds the messages during the quiet hours of each recipient in their timezone: the one set for the user, the timezone of the channel or DEFAULT_TIMEZONE
 // @Tags Notifications
 // @Accept json
 // @Produce json
 // @Param request body dto.SendNotificationRequest true "Send notification request"
-// @Success 200 {object} common.SuccessResponse
+// @Success 200 {object} domain.Broadcast
 // @Failure 400 {object} common.ErrorResponse
 // @Failure 500 {object} common.ErrorResponse
+// @Failure 503 {object} common.ErrorResponse
 // @Security XAuthToken
 // @Router /notifications [post]
 func (c *NotificationController) SendNotificationHandler() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		req := dto.NewSendNotificationRequest()
 		if err := req.Parse(ctx); err != nil {
-			logrus.Error("unable to parse a request: ", err)
+			logger.FromContext(ctx).Error("unable to parse a request: ", err)
 			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
 			return
 		}
 
 		if err := req.Validate(); err != nil {
-			logrus.Error("error of validation: ", err)
+			logger.FromContext(ctx).Error("error of validation: ", err)
 			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
 			return
 		}
 
-		data := &domain.NotificationData{
-			Message:  req.Message,
-			ImageURL: req.ImageURL,
+		broadcast, err := c.notificationService.SendNotification(ctx.Request.Context(), req.ToDomain())
+		if errors.Is(err, service.ErrInvalidMessageTemplate) || errors.Is(err, service.ErrLinkTrackingDisabled) {
+			logger.FromContext(ctx).Error("error of validation: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrNotificationsStopped) {
+			ctx.JSON(http.StatusServiceUnavailable, common.ErrorResponse{Error: err.Error()})
+			return
 		}
-
-		err := c.notificationService.SendNotification(data)
 		if err != nil {
-			logrus.Error("error while send notification: ", err)
+			logger.FromContext(ctx).Error("error while send notification: ", err)
 			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to send notification: %v", err)})
 			return
 		}
 
-		ctx.JSON(http.StatusOK, common.SuccessResponse{})
+		ctx.JSON(http.StatusOK, broadcast)
+	}
+}
+
+// GetBroadcasts godoc
+// @Summary Get broadcasts
+// @Description Get the running, paused and recently finished broadcasts, the latest first. Finished broadcasts are kept in memory until restart
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.GetBroadcastsResponse
+// @Security XAuthToken
+// @Router /notifications/broadcasts [get]
+func (c *NotificationController) GetBroadcastsHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, dto.NewGetBroadcastsResponse(c.notificationService.GetBroadcasts()))
+	}
+}
+
+// GetBroadcast godoc
+// @Summary Get a broadcast
+// @Description Get the status and delivery counters of a broadcast
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param id path string true "Broadcast ID"
+// @Success 200 {object} domain.Broadcast
+// @Failure 404 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /notifications/broadcasts/{id} [get]
+func (c *NotificationController) GetBroadcastHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		broadcast, err := c.notificationService.GetBroadcast(ctx.Param("id"))
+		c.respondBroadcast(ctx, broadcast, err, "get broadcast")
+	}
+}
+
+// PauseBroadcast godoc
+// @Summary Pause a broadcast
+// @Description Stop queuing the messages of a running broadcast, the already queued ones are still sent
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param id path string true "Broadcast ID"
+// @Success 200 {object} domain.Broadcast
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 409 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /notifications/broadcasts/{id}/pause [post]
+func (c *NotificationController) PauseBroadcastHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		broadcast, err := c.notificationService.PauseBroadcast(ctx, ctx.Param("id"))
+		c.respondBroadcast(ctx, broadcast, err, "pause broadcast")
+	}
+}
+
+// ResumeBroadcast godoc
+// @Summary Resume a broadcast
+// @Description Continue a paused broadcast
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param id path string true "Broadcast ID"
+// @Success 200 {object} domain.Broadcast
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 409 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /notifications/broadcasts/{id}/resume [post]
+func (c *NotificationController) ResumeBroadcastHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		broadcast, err := c.notificationService.ResumeBroadcast(ctx, ctx.Param("id"))
+		c.respondBroadcast(ctx, broadcast, err, "resume broadcast")
+	}
+}
+
+// CancelBroadcast godoc
+// @Summary Cancel a broadcast
+// @Description Stop a running or paused broadcast, the queued messages are dropped
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param id path string true "Broadcast ID"
+// @Success 200 {object} domain.Broadcast
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 409 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /notifications/broadcasts/{id}/cancel [post]
+func (c *NotificationController) CancelBroadcastHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		broadcast, err := c.notificationService.CancelBroadcast(ctx, ctx.Param("id"))
+		c.respondBroadcast(ctx, broadcast, err, "cancel broadcast")
+	}
+}
+
+// GetBroadcastClicks godoc
+// @Summary Get clicks of a broadcast
+// @Description Get the clicks on the tracked links of a broadcast by link and variant. The clicks are stored in the database and outlive the broadcast, a broadcast without tracked links has zeros
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param id path string true "Broadcast ID"
+// @Success 200 {object} domain.BroadcastClickStats
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /notifications/broadcasts/{id}/clicks [get]
+func (c *NotificationController) GetBroadcastClicksHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		stats, err := c.notificationService.GetBroadcastClicks(ctx, ctx.Param("id"))
+		if err != nil {
+			logger.FromContext(ctx).Error("error while get broadcast clicks: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to get broadcast clicks: %v", err)})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, stats)
+	}
+}
+
+// GetBroadcastVariants godoc
+// @Summary Get variants of a broadcast
+// @Description Get the sent, failed and response counts of the broadcast variants. The counts are stored in the database and outlive the broadcast
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Param id path string true "Broadcast ID"
+// @Success 200 {array} domain.BroadcastVariant
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /notifications/broadcasts/{id}/variants [get]
+func (c *NotificationController) GetBroadcastVariantsHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		variants, err := c.notificationService.GetBroadcastVariants(ctx, ctx.Param("id"))
+		switch {
+		case errors.Is(err, service.ErrBroadcastNotFound):
+			ctx.JSON(http.StatusNotFound, common.ErrorResponse{Error: "Broadcast not found"})
+		case err != nil:
+			logger.FromContext(ctx).Error("error while get broadcast variants: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to get broadcast variants: %v", err)})
+		default:
+			ctx.JSON(http.StatusOK, variants)
+		}
+	}
+}
+
+func (c *NotificationController) respondBroadcast(ctx *gin.Context, broadcast *domain.Broadcast, err error, action string) {
+	switch {
+	case errors.Is(err, service.ErrBroadcastNotFound):
+		ctx.JSON(http.StatusNotFound, common.ErrorResponse{Error: "Broadcast not found"})
+	case errors.Is(err, service.ErrBroadcastNotActive):
+		ctx.JSON(http.StatusConflict, common.ErrorResponse{Error: err.Error()})
+	case err != nil:
+		logger.FromContext(ctx).Error("error while "+action+": ", err)
+		ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to %s: %v", action, err)})
+	default:
+		ctx.JSON(http.StatusOK, broadcast)
 	}
 }
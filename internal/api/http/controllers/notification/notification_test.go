@@ -0,0 +1,161 @@
+package notification_test
+
+import (
+	"hr-server/internal/api/http/controllers/controllertest"
+	"hr-server/internal/api/http/controllers/notification"
+	"hr-server/internal/api/http/controllers/notification/dto"
+	"hr-server/internal/domain"
+	"hr-server/internal/service/servicetest"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRouter(s *servicetest.Services) *gin.Engine {
+	controller := notification.NewNotificationController(s.Notification)
+
+	router := controllertest.NewRouter()
+	router.POST("/notifications", controller.SendNotificationHandler())
+	router.GET("/notifications/broadcasts", controller.GetBroadcastsHandler())
+	router.GET("/notifications/broadcasts/:id", controller.GetBroadcastHandler())
+	router.POST("/notifications/broadcasts/:id/pause", controller.PauseBroadcastHandler())
+	router.POST("/notifications/broadcasts/:id/resume", controller.ResumeBroadcastHandler())
+	router.POST("/notifications/broadcasts/:id/cancel", controller.CancelBroadcastHandler())
+	router.GET("/notifications/broadcasts/:id/clicks", controller.GetBroadcastClicksHandler())
+	router.GET("/notifications/broadcasts/:id/variants", controller.GetBroadcastVariantsHandler())
+
+	return router
+}
+
+func TestSendNotificationHandler(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+	s.RunNotifications(t)
+	router := newRouter(s)
+
+	s.AddUser(t, 1, "alice", nil)
+	s.AddUser(t, 2, "bob", nil)
+
+	recorder := controllertest.Do(t, router, http.MethodPost, "/notifications", gin.H{"message": "hello", "priority": "transactional"})
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	broadcast := controllertest.Decode[domain.Broadcast](t, recorder)
+	assert.NotEmpty(t, broadcast.ID)
+	assert.Equal(t, domain.NotificationPriorityTransactional, broadcast.Priority)
+
+	s.WaitForBroadcasts(t, 5*time.Second)
+	assert.Len(t, s.Telegram.Sent(), 2)
+
+	recorder = controllertest.Do(t, router, http.MethodGet, "/notifications/broadcasts/"+broadcast.ID, nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	finished := controllertest.Decode[domain.Broadcast](t, recorder)
+	assert.Equal(t, domain.BroadcastStatusFinished, finished.Status)
+	assert.Equal(t, int64(2), finished.Sent)
+
+	recorder = controllertest.Do(t, router, http.MethodPost, "/notifications", gin.H{"variants": []gin.H{
+		{"message": "a", "weight": 1, "button_text": "Go"},
+		{"message": "b", "weight": 3},
+	}})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	variants := controllertest.Decode[domain.Broadcast](t, recorder).Variants
+	require.Len(t, variants, 2)
+	assert.Equal(t, 3, variants[1].Weight)
+	s.WaitForBroadcasts(t, 5*time.Second)
+
+	for name, body := range map[string]gin.H{
+		"missing message":          {"message": ""},
+		"invalid image url":        {"message": "hello", "image_url": "not a url"},
+		"unknown priority":         {"message": "hello", "priority": "urgent"},
+		"negative max concurrency": {"message": "hello", "max_concurrency": -1},
+		"unknown placeholder":      {"message": "hello, {{.Name}}"},
+		"message with variants":    {"message": "hello", "variants": []gin.H{{"message": "a", "weight": 1}, {"message": "b", "weight": 1}}},
+		"single variant":           {"variants": []gin.H{{"message": "a", "weight": 1}}},
+		"variant without weight":   {"variants": []gin.H{{"message": "a", "weight": 1}, {"message": "b"}}},
+		"too many variants":        {"variants": []gin.H{{"message": "a", "weight": 1}, {"message": "b", "weight": 1}, {"message": "c", "weight": 1}, {"message": "d", "weight": 1}}},
+		"button url without text":  {"message": "hello", "button_url": "https://example.com"},
+		"invalid button url":       {"message": "hello", "button_text": "Go", "button_url": "example.com"},
+		"tracking disabled":        {"message": "https://example.com", "track_links": true},
+	} {
+		recorder := controllertest.Do(t, router, http.MethodPost, "/notifications", body)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, name)
+	}
+}
+
+func TestBroadcastControlHandlers(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+	s.RunNotifications(t)
+	router := newRouter(s)
+
+	for id := int64(1); id <= 50; id++ {
+		s.AddUser(t, id, "user", nil)
+	}
+
+	recorder := controllertest.Do(t, router, http.MethodPost, "/notifications", gin.H{"message": "hello", "max_concurrency": 1})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	broadcast := controllertest.Decode[domain.Broadcast](t, recorder)
+
+	recorder = controllertest.Do(t, router, http.MethodPost, "/notifications/broadcasts/"+broadcast.ID+"/pause", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, domain.BroadcastStatusPaused, controllertest.Decode[domain.Broadcast](t, recorder).Status)
+
+	recorder = controllertest.Do(t, router, http.MethodPost, "/notifications/broadcasts/"+broadcast.ID+"/resume", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, domain.BroadcastStatusRunning, controllertest.Decode[domain.Broadcast](t, recorder).Status)
+
+	recorder = controllertest.Do(t, router, http.MethodPost, "/notifications/broadcasts/"+broadcast.ID+"/cancel", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, domain.BroadcastStatusCancelled, controllertest.Decode[domain.Broadcast](t, recorder).Status)
+
+	recorder = controllertest.Do(t, router, http.MethodPost, "/notifications/broadcasts/"+broadcast.ID+"/pause", nil)
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+
+	recorder = controllertest.Do(t, router, http.MethodPost, "/notifications/broadcasts/unknown/cancel", nil)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	s.WaitForBroadcasts(t, 5*time.Second)
+
+	recorder = controllertest.Do(t, router, http.MethodGet, "/notifications/broadcasts", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	response := controllertest.Decode[dto.GetBroadcastsResponse](t, recorder)
+	require.Len(t, response.Broadcasts, 1)
+	assert.Equal(t, domain.BroadcastStatusCancelled, response.Broadcasts[0].Status)
+}
+
+func TestGetBroadcastClicksHandler(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+	router := newRouter(s)
+
+	recorder := controllertest.Do(t, router, http.MethodGet, "/notifications/broadcasts/unknown/clicks", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	stats := controllertest.Decode[domain.BroadcastClickStats](t, recorder)
+	assert.Equal(t, "unknown", stats.BroadcastID)
+	assert.Zero(t, stats.Clicks)
+	assert.Empty(t, stats.Links)
+}
+
+func TestGetBroadcastVariantsHandler(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+	s.RunNotifications(t)
+	router := newRouter(s)
+
+	s.AddUser(t, 1, "alice", nil)
+
+	broadcast, err := s.Notification.SendNotification(t.Context(), &domain.NotificationData{Message: "hello"})
+	require.NoError(t, err)
+	s.WaitForBroadcasts(t, 5*time.Second)
+
+	recorder := controllertest.Do(t, router, http.MethodGet, "/notifications/broadcasts/"+broadcast.ID+"/variants", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	variants := controllertest.Decode[[]domain.BroadcastVariant](t, recorder)
+	require.Len(t, variants, 1)
+	assert.Equal(t, "A", variants[0].Name)
+	assert.Equal(t, int64(1), variants[0].Sent)
+
+	recorder = controllertest.Do(t, router, http.MethodGet, "/notifications/broadcasts/unknown/variants", nil)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
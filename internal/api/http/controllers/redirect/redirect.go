@@ -0,0 +1,39 @@
+package redirect
+
+import (
+	"errors"
+	"fmt"
+	"hr-server/internal/api/http/controllers/common"
+	"hr-server/internal/logger"
+	"hr-server/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RedirectController struct {
+	linkService *service.LinkService
+}
+
+func NewRedirectController(linkService *service.LinkService) *RedirectController {
+	return &RedirectController{linkService}
+}
+
+// RedirectHandler records the click of the recipient on a tracked link of a broadcast and redirects to the original URL.
+// It's served at /r/:token outside of the API base path, so it's not in the Swagger docs.
+func (c *RedirectController) RedirectHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		url, err := c.linkService.Click(ctx, ctx.Param("token"), ctx.Request.UserAgent())
+		if errors.Is(err, service.ErrInvalidLinkToken) {
+			ctx.JSON(http.StatusNotFound, common.ErrorResponse{Error: "Link not found"})
+			return
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error("error while get tracked link: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to open link: %v", err)})
+			return
+		}
+
+		ctx.Redirect(http.StatusFound, url)
+	}
+}
@@ -0,0 +1,87 @@
+package redirect_test
+
+import (
+	"context"
+	"errors"
+	"hr-server/internal/api/http/controllers/controllertest"
+	"hr-server/internal/api/http/controllers/redirect"
+	"hr-server/internal/domain"
+	"hr-server/internal/repository"
+	"hr-server/internal/service"
+	"hr-server/internal/service/servicetest"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedirectHandler(t *testing.T) {
+	cfg := servicetest.Config()
+	cfg.Tracking.BaseURL = servicetest.TrackingBaseURL
+	cfg.Tracking.Secret = servicetest.TrackingSecret
+	s := servicetest.NewServices(cfg)
+	s.RunNotifications(t)
+
+	router := controllertest.NewRouter()
+	router.GET("/r/:token", redirect.NewRedirectController(s.Link).RedirectHandler())
+
+	s.AddUser(t, 1, "alice", nil)
+
+	broadcast, err := s.Notification.SendNotification(t.Context(), &domain.NotificationData{
+		Message:    "hello",
+		ButtonText: "Open",
+		ButtonURL:  "https://example.com/jobs",
+		TrackLinks: true,
+	})
+	require.NoError(t, err)
+	s.WaitForBroadcasts(t, 5*time.Second)
+
+	sent := s.Telegram.Sent()
+	require.Len(t, sent, 1)
+	path, ok := strings.CutPrefix(sent[0].ButtonURL, servicetest.TrackingBaseURL)
+	require.True(t, ok, sent[0].ButtonURL)
+
+	recorder := controllertest.Do(t, router, http.MethodGet, path, nil, "User-Agent", "Mozilla/5.0")
+	require.Equal(t, http.StatusFound, recorder.Code)
+	assert.Equal(t, "https://example.com/jobs", recorder.Header().Get("Location"))
+
+	stats, err := s.Notification.GetBroadcastClicks(t.Context(), broadcast.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Clicks)
+
+	recorder = controllertest.Do(t, router, http.MethodGet, "/r/abcdefghijklmnopqrstu", nil)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+// failingClicks fails to record the clicks
+type failingClicks struct {
+	*repository.LinkMemoryRepository
+}
+
+func (failingClicks) CreateClick(ctx context.Context, click *domain.LinkClick) error {
+	return errors.New("database is unavailable")
+}
+
+func TestRedirectHandler_ClickNotRecorded(t *testing.T) {
+	cfg := servicetest.Config()
+	cfg.Tracking.BaseURL = servicetest.TrackingBaseURL
+	cfg.Tracking.Secret = servicetest.TrackingSecret
+	s := servicetest.NewServices(cfg)
+
+	links, err := s.Link.TrackLinks(t.Context(), "broadcast", "A", []string{"https://example.com/jobs"})
+	require.NoError(t, err)
+
+	linkService := service.NewLinkService(cfg, failingClicks{repository.NewLinkMemoryRepository(s.DB)})
+	router := controllertest.NewRouter()
+	router.GET("/r/:token", redirect.NewRedirectController(linkService).RedirectHandler())
+
+	path, ok := strings.CutPrefix(linkService.TrackedURL(links[0].ID, 1), servicetest.TrackingBaseURL)
+	require.True(t, ok)
+
+	recorder := controllertest.Do(t, router, http.MethodGet, path, nil)
+	require.Equal(t, http.StatusFound, recorder.Code, "the recipient is redirected without the click")
+	assert.Equal(t, "https://example.com/jobs", recorder.Header().Get("Location"))
+}
@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"hr-server/internal/domain"
+)
+
+type GetSourcesResponse struct {
+	Sources []*domain.Source `json:"sources"`
+}
+
+func NewGetSourcesResponse(sources []*domain.Source) *GetSourcesResponse {
+	return &GetSourcesResponse{
+		Sources: sources,
+	}
+}
+
+type GetSourceStatsResponse struct {
+	Stats []*domain.GroupStats `json:"stats"`
+}
+
+func NewGetSourceStatsResponse(stats []*domain.GroupStats) *GetSourceStatsResponse {
+	return &GetSourceStatsResponse{
+		Stats: stats,
+	}
+}
@@ -0,0 +1,112 @@
+package source
+
+import (
+	"errors"
+	"fmt"
+	"hr-server/internal/api/http/controllers/common"
+	"hr-server/internal/api/http/controllers/source/dto"
+	"hr-server/internal/logger"
+	"hr-server/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SourceController struct {
+	sourceService *service.SourceService
+}
+
+func NewSourceController(sourceService *service.SourceService) *SourceController {
+	return &SourceController{sourceService}
+}
+
+// CreateSource godoc
+// @Summary Create a new source
+// @Description Create a new source to group channels
+// @Tags Sources
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateSourceRequest true "Create source request"
+// @Success 200 {object} domain.Source
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 409 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /sources [post]
+func (c *SourceController) CreateSourceHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		req := dto.NewCreateSourceRequest()
+		if err := req.Parse(ctx); err != nil {
+			logger.FromContext(ctx).Error("unable to parse a request: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			logger.FromContext(ctx).Error("error of validation: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		source, err := c.sourceService.CreateSource(ctx, req.Name)
+		if errors.Is(err, service.ErrSourceExists) {
+			ctx.JSON(http.StatusConflict, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error("error while create source: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to create source: %v", err)})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, source)
+	}
+}
+
+// GetSources godoc
+// @Summary Get all sources
+// @Description Get all sources
+// @Tags Sources
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.GetSourcesResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /sources [get]
+func (c *SourceController) GetSourcesHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		sources, err := c.sourceService.GetAll(ctx)
+		if err != nil {
+			logger.FromContext(ctx).Error("error while get all sources: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to get all sources: %v", err)})
+			return
+		}
+
+		response := dto.NewGetSourcesResponse(sources)
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+// GetSourceStats godoc
+// @Summary Get source stats
+// @Description Get the number of channels and users of every source
+// @Tags Sources
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.GetSourceStatsResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /sources/stats [get]
+func (c *SourceController) GetSourceStatsHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		stats, err := c.sourceService.GetStats(ctx)
+		if err != nil {
+			logger.FromContext(ctx).Error("error while get source stats: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to get source stats: %v", err)})
+			return
+		}
+
+		response := dto.NewGetSourceStatsResponse(stats)
+		ctx.JSON(http.StatusOK, response)
+	}
+}
@@ -0,0 +1,95 @@
+package source_test
+
+import (
+	"hr-server/internal/api/http/controllers/controllertest"
+	"hr-server/internal/api/http/controllers/source"
+	"hr-server/internal/api/http/controllers/source/dto"
+	"hr-server/internal/domain"
+	"hr-server/internal/service/servicetest"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRouter(t *testing.T) (*gin.Engine, *servicetest.Services) {
+	t.Helper()
+
+	s := servicetest.NewServices(servicetest.Config())
+	controller := source.NewSourceController(s.Source)
+
+	router := controllertest.NewRouter()
+	router.POST("/sources", controller.CreateSourceHandler())
+	router.GET("/sources", controller.GetSourcesHandler())
+	router.GET("/sources/stats", controller.GetSourceStatsHandler())
+
+	return router, s
+}
+
+func TestCreateSourceHandler(t *testing.T) {
+	router, s := newRouter(t)
+
+	_, err := s.Campaign.CreateCampaign(t.Context(), "hh.ru")
+	require.NoError(t, err)
+
+	recorder := controllertest.Do(t, router, http.MethodPost, "/sources", gin.H{"name": "hh.ru"})
+	require.Equal(t, http.StatusOK, recorder.Code, "a campaign name doesn't take the source name")
+	assert.Equal(t, "hh.ru", controllertest.Decode[domain.Source](t, recorder).Name)
+
+	recorder = controllertest.Do(t, router, http.MethodPost, "/sources", gin.H{"name": "hh.ru"})
+	assert.Equal(t, http.StatusConflict, recorder.Code, "names are unique")
+
+	recorder = controllertest.Do(t, router, http.MethodPost, "/sources", gin.H{"name": strings.Repeat("a", 256)})
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestGetSourcesHandler(t *testing.T) {
+	router, s := newRouter(t)
+
+	for _, name := range []string{"b", "a"} {
+		_, err := s.Source.CreateSource(t.Context(), name)
+		require.NoError(t, err)
+	}
+
+	recorder := controllertest.Do(t, router, http.MethodGet, "/sources", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	response := controllertest.Decode[dto.GetSourcesResponse](t, recorder)
+	require.Len(t, response.Sources, 2)
+	assert.Equal(t, "a", response.Sources[0].Name)
+}
+
+func TestGetSourceStatsHandler(t *testing.T) {
+	router, s := newRouter(t)
+
+	source, err := s.Source.CreateSource(t.Context(), "hh.ru")
+	require.NoError(t, err)
+	empty, err := s.Source.CreateSource(t.Context(), "empty")
+	require.NoError(t, err)
+
+	// channels of different campaigns roll up into their common source
+	for i, name := range []string{"spring-2026", "autumn-2026"} {
+		campaign, err := s.Campaign.CreateCampaign(t.Context(), name)
+		require.NoError(t, err)
+
+		channel, err := s.Channel.GenerateChannel(t.Context(), name, domain.ChannelAttributes{CampaignID: &campaign.ID, SourceID: &source.ID})
+		require.NoError(t, err)
+		s.AddUser(t, int64(i+1), name, &channel.ID)
+	}
+
+	unattributed, err := s.Channel.GenerateChannel(t.Context(), "no source", domain.ChannelAttributes{})
+	require.NoError(t, err)
+	s.AddUser(t, 3, "no source", &unattributed.ID)
+
+	recorder := controllertest.Do(t, router, http.MethodGet, "/sources/stats", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	response := controllertest.Decode[dto.GetSourceStatsResponse](t, recorder)
+	assert.Equal(t, []*domain.GroupStats{
+		{ID: empty.ID, Name: "empty"},
+		{ID: source.ID, Name: "hh.ru", ChannelsCount: 2, UsersCount: 2},
+	}, response.Stats)
+}
@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"hr-server/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+type EraseUserRequest struct {
+	Mode   string `json:"mode" enums:"delete,anonymize"`
+	Reason string `json:"reason" example:"Candidate request by email"`
+}
+
+func NewEraseUserRequest() *EraseUserRequest {
+	return &EraseUserRequest{}
+}
+
+func (r *EraseUserRequest) Parse(c *gin.Context) error {
+	return c.ShouldBindJSON(&r)
+}
+
+func (r *EraseUserRequest) Validate() error {
+	err := validation.ValidateStruct(r,
+		validation.Field(&r.Mode,
+			validation.Required.Error("is required"),
+			validation.In(domain.ErasureModeDelete, domain.ErasureModeAnonymize),
+		),
+		validation.Field(&r.Reason, validation.Length(0, 1000)),
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package dto
+
+import (
+	"fmt"
+	"hr-server/internal/domain"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// Export formats
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+)
+
+type ExportUsersRequest struct {
+	ChannelID   *int       `form:"channel_id"`
+	CreatedFrom *time.Time `form:"created_from" example:"2026-01-01T00:00:00Z"`
+	CreatedTo   *time.Time `form:"created_to" example:"2026-02-01T00:00:00Z"`
+	Username    string     `form:"username"`
+	Status      string     `form:"status" enums:"active,blocked,erased"`
+
+	Format   string   `form:"format" enums:"csv,xlsx"`
+	Columns  []string `form:"columns"`
+	Timezone string   `form:"timezone" example:"Europe/Moscow"`
+	BOM      bool     `form:"bom"`
+
+	Location *time.Location `form:"-"`
+}
+
+func NewExportUsersRequest() *ExportUsersRequest {
+	return &ExportUsersRequest{}
+}
+
+// Parse parses the query, columns may be passed both as repeated and comma-separated values
+func (r *ExportUsersRequest) Parse(c *gin.Context) error {
+	if err := c.ShouldBindQuery(r); err != nil {
+		return err
+	}
+
+	var columns []string
+	for _, value := range r.Columns {
+		for _, column := range strings.Split(value, ",") {
+			if column = strings.TrimSpace(column); column != "" {
+				columns = append(columns, column)
+			}
+		}
+	}
+	r.Columns = columns
+
+	if r.Format == "" {
+		r.Format = ExportFormatCSV
+	}
+
+	return nil
+}
+
+// Validate validates the request against the supported export columns and loads the timezone
+func (r *ExportUsersRequest) Validate(supportedColumns []string) error {
+	err := validation.ValidateStruct(r,
+		validation.Field(&r.ChannelID, validation.Min(1)),
+		validation.Field(&r.Username, validation.Length(0, 255)),
+		validation.Field(&r.Status, validation.In(domain.UserStatusActive, domain.UserStatusBlocked, domain.UserStatusErased)),
+		validation.Field(&r.Format, validation.In(ExportFormatCSV, ExportFormatXLSX)),
+	)
+	if err != nil {
+		return err
+	}
+
+	supported := make(map[string]bool, len(supportedColumns))
+	for _, column := range supportedColumns {
+		supported[column] = true
+	}
+
+	for _, column := range r.Columns {
+		if !supported[column] {
+			return fmt.Errorf("columns: unknown column '%s', supported columns: %s", column, strings.Join(supportedColumns, ", "))
+		}
+	}
+
+	r.Location = time.UTC
+	if r.Timezone != "" {
+		location, err := time.LoadLocation(r.Timezone)
+		if err != nil {
+			return fmt.Errorf("timezone: unknown timezone '%s'", r.Timezone)
+		}
+		r.Location = location
+	}
+
+	return nil
+}
+
+func (r *ExportUsersRequest) Filter() domain.UserFilter {
+	return domain.UserFilter{
+		ChannelID: r.ChannelID,
+		CreatedAt: domain.TimeRange{From: r.CreatedFrom, To: r.CreatedTo},
+		Username:  r.Username,
+		Status:    r.Status,
+	}
+}
@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"hr-server/internal/api/http/controllers/common"
+	"hr-server/internal/domain"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+type GetUsersRequest struct {
+	common.PageRequest
+
+	ChannelID   *int       `form:"channel_id"`
+	CreatedFrom *time.Time `form:"created_from" example:"2026-01-01T00:00:00Z"`
+	CreatedTo   *time.Time `form:"created_to" example:"2026-02-01T00:00:00Z"`
+	Username    string     `form:"username"`
+	Status      string     `form:"status" enums:"active,blocked,erased"`
+}
+
+func NewGetUsersRequest() *GetUsersRequest {
+	return &GetUsersRequest{}
+}
+
+func (r *GetUsersRequest) Parse(c *gin.Context) error {
+	return c.ShouldBindQuery(r)
+}
+
+func (r *GetUsersRequest) Validate() error {
+	err := validation.ValidateStruct(r,
+		validation.Field(&r.ChannelID, validation.Min(1)),
+		validation.Field(&r.Username, validation.Length(0, 255)),
+		validation.Field(&r.Status, validation.In(domain.UserStatusActive, domain.UserStatusBlocked, domain.UserStatusErased)),
+	)
+	if err != nil {
+		return err
+	}
+
+	return r.ValidatePage("created_at", "username", "telegram_id")
+}
+
+func (r *GetUsersRequest) Filter() domain.UserFilter {
+	return domain.UserFilter{
+		ChannelID: r.ChannelID,
+		CreatedAt: domain.TimeRange{From: r.CreatedFrom, To: r.CreatedTo},
+		Username:  r.Username,
+		Status:    r.Status,
+	}
+}
@@ -5,11 +5,15 @@ import (
 )
 
 type GetUsersResponse struct {
-	Users []*domain.UserWithChannel `json:"users"`
+	Users      []*domain.UserWithChannel `json:"users"`
+	TotalCount int64                     `json:"total_count"`
+	NextCursor string                    `json:"next_cursor,omitempty"`
 }
 
-func NewGetUsersResponse(users []*domain.UserWithChannel) *GetUsersResponse {
+func NewGetUsersResponse(page *domain.Page[*domain.UserWithChannel]) *GetUsersResponse {
 	return &GetUsersResponse{
-		Users: users,
+		Users:      page.Items,
+		TotalCount: page.TotalCount,
+		NextCursor: page.NextCursor,
 	}
 }
@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"fmt"
+	"mime/multipart"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxImportFileSize = 10 << 20 // 10 MiB
+
+type ImportUsersRequest struct {
+	File     *multipart.FileHeader `form:"file" swaggerignore:"true"`
+	DryRun   bool                  `form:"dry_run"`
+	Timezone string                `form:"timezone" example:"Europe/Moscow"`
+
+	Location *time.Location `form:"-"`
+}
+
+func NewImportUsersRequest() *ImportUsersRequest {
+	return &ImportUsersRequest{}
+}
+
+func (r *ImportUsersRequest) Parse(c *gin.Context) error {
+	return c.ShouldBind(r)
+}
+
+// Validate validates the uploaded file and loads the timezone of the dates in it
+func (r *ImportUsersRequest) Validate() error {
+	if r.File == nil {
+		return fmt.Errorf("file: is required")
+	}
+
+	if r.File.Size > maxImportFileSize {
+		return fmt.Errorf("file: must be at most %d MiB", maxImportFileSize>>20)
+	}
+
+	r.Location = time.UTC
+	if r.Timezone != "" {
+		location, err := time.LoadLocation(r.Timezone)
+		if err != nil {
+			return fmt.Errorf("timezone: unknown timezone '%s'", r.Timezone)
+		}
+		r.Location = location
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"fmt"
+	"hr-server/internal/api/http/controllers/common"
+	"hr-server/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+type UpdateUserRequest struct {
+	ChannelID    *int    `json:"channel_id,omitempty"`
+	ClearChannel bool    `json:"clear_channel,omitempty"`
+	Status       *string `json:"status,omitempty" enums:"active,blocked"`
+	Timezone     *string `json:"timezone,omitempty" example:"Asia/Vladivostok"` // overrides the timezone of the channel, empty clears it
+}
+
+func NewUpdateUserRequest() *UpdateUserRequest {
+	return &UpdateUserRequest{}
+}
+
+func (r *UpdateUserRequest) Parse(c *gin.Context) error {
+	return c.ShouldBindJSON(&r)
+}
+
+func (r *UpdateUserRequest) Validate() error {
+	err := validation.ValidateStruct(r,
+		validation.Field(&r.ChannelID, validation.Min(1)),
+		validation.Field(&r.Status, validation.In(domain.UserStatusActive, domain.UserStatusBlocked)),
+		validation.Field(&r.Timezone, common.TimezoneRule),
+	)
+	if err != nil {
+		return err
+	}
+
+	if r.ChannelID != nil && r.ClearChannel {
+		return fmt.Errorf("channel_id and clear_channel are mutually exclusive")
+	}
+
+	if r.ChannelID == nil && !r.ClearChannel && r.Status == nil && r.Timezone == nil {
+		return fmt.Errorf("nothing to update")
+	}
+
+	return nil
+}
+
+func (r *UpdateUserRequest) ToDomain() domain.UserUpdate {
+	return domain.UserUpdate{
+		ChannelID:    r.ChannelID,
+		ClearChannel: r.ClearChannel,
+		Status:       r.Status,
+		Timezone:     r.Timezone,
+	}
+}
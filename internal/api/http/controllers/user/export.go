@@ -0,0 +1,188 @@
+package user
+
+import (
+	"encoding/csv"
+	"fmt"
+	"hr-server/internal/domain"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const exportTimeFormat = "2006-01-02 15:04:05"
+
+// exportColumn describes a column of the users export
+type exportColumn struct {
+	key    string
+	header string
+	value  func(user *domain.UserWithChannel, location *time.Location) string
+}
+
+// exportColumns are all supported export columns in their default order
+var exportColumns = []exportColumn{
+	{"id", "ID", func(u *domain.UserWithChannel, _ *time.Location) string {
+		return strconv.Itoa(u.ID)
+	}},
+	{"telegram_id", "Telegram ID", func(u *domain.UserWithChannel, _ *time.Location) string {
+		return strconv.FormatInt(u.TelegramID, 10)
+	}},
+	{"username", "Username", func(u *domain.UserWithChannel, _ *time.Location) string {
+		return u.Username
+	}},
+	{"channel_id", "Channel ID", func(u *domain.UserWithChannel, _ *time.Location) string {
+		if u.ChannelID == nil {
+			return ""
+		}
+		return strconv.Itoa(*u.ChannelID)
+	}},
+	{"channel_name", "Channel Name", func(u *domain.UserWithChannel, _ *time.Location) string {
+		return derefString(u.ChannelName)
+	}},
+	{"channel_link", "Bot Start Link", func(u *domain.UserWithChannel, _ *time.Location) string {
+		return derefString(u.ChannelLink)
+	}},
+	{"status", "Status", func(u *domain.UserWithChannel, _ *time.Location) string {
+		return u.Status
+	}},
+	{"created_at", "Created At", func(u *domain.UserWithChannel, location *time.Location) string {
+		return u.CreatedAt.In(location).Format(exportTimeFormat)
+	}},
+	{"updated_at", "Updated At", func(u *domain.UserWithChannel, location *time.Location) string {
+		return u.UpdatedAt.In(location).Format(exportTimeFormat)
+	}},
+	{"last_seen_at", "Last Seen At", func(u *domain.UserWithChannel, location *time.Location) string {
+		if u.LastSeenAt == nil {
+			return ""
+		}
+		return u.LastSeenAt.In(location).Format(exportTimeFormat)
+	}},
+	{"timezone", "Timezone", func(u *domain.UserWithChannel, _ *time.Location) string {
+		return u.Timezone
+	}},
+}
+
+func exportColumnKeys() []string {
+	keys := make([]string, 0, len(exportColumns))
+	for _, column := range exportColumns {
+		keys = append(keys, column.key)
+	}
+
+	return keys
+}
+
+// selectExportColumns returns the columns in the requested order, all columns if none are requested
+func selectExportColumns(keys []string) []exportColumn {
+	if len(keys) == 0 {
+		return exportColumns
+	}
+
+	byKey := make(map[string]exportColumn, len(exportColumns))
+	for _, column := range exportColumns {
+		byKey[column.key] = column
+	}
+
+	columns := make([]exportColumn, 0, len(keys))
+	for _, key := range keys {
+		columns = append(columns, byKey[key])
+	}
+
+	return columns
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// rowWriter writes export rows in a specific file format
+type rowWriter interface {
+	WriteRow(row []string) error
+	// Flush sends the rows written so far to the client if the format allows it
+	Flush() error
+	Close() error
+}
+
+type csvRowWriter struct {
+	w *csv.Writer
+}
+
+func newCSVRowWriter(w io.Writer, bom bool) (*csvRowWriter, error) {
+	if bom {
+		// UTF-8 byte order mark, makes Excel detect the encoding of Cyrillic usernames
+		if _, err := w.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
+			return nil, fmt.Errorf("failed to write BOM: %w", err)
+		}
+	}
+
+	return &csvRowWriter{csv.NewWriter(w)}, nil
+}
+
+func (cw *csvRowWriter) WriteRow(row []string) error {
+	return cw.w.Write(row)
+}
+
+func (cw *csvRowWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func (cw *csvRowWriter) Close() error {
+	return cw.Flush()
+}
+
+// xlsxRowWriter streams rows to a single sheet, the file is written out on Close
+type xlsxRowWriter struct {
+	w      io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newXLSXRowWriter(w io.Writer) (*xlsxRowWriter, error) {
+	file := excelize.NewFile()
+
+	stream, err := file.NewStreamWriter("Sheet1")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create XLSX stream writer: %w", err)
+	}
+
+	return &xlsxRowWriter{w: w, file: file, stream: stream}, nil
+}
+
+func (xw *xlsxRowWriter) WriteRow(row []string) error {
+	xw.row++
+
+	cell, err := excelize.CoordinatesToCellName(1, xw.row)
+	if err != nil {
+		return err
+	}
+
+	values := make([]interface{}, 0, len(row))
+	for _, value := range row {
+		values = append(values, value)
+	}
+
+	return xw.stream.SetRow(cell, values)
+}
+
+func (xw *xlsxRowWriter) Flush() error {
+	return nil
+}
+
+func (xw *xlsxRowWriter) Close() error {
+	defer xw.file.Close()
+
+	if err := xw.stream.Flush(); err != nil {
+		return fmt.Errorf("failed to flush XLSX stream: %w", err)
+	}
+
+	if err := xw.file.Write(xw.w); err != nil {
+		return fmt.Errorf("failed to write XLSX file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package user
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"hr-server/internal/domain"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxImportRows = 50000
+
+// importColumnKeys maps the export headers and column keys to the column keys, case-insensitive
+func importColumnKeys() map[string]string {
+	keys := make(map[string]string, 2*len(exportColumns))
+	for _, column := range exportColumns {
+		keys[strings.ToLower(column.header)] = column.key
+		keys[column.key] = column.key
+	}
+
+	return keys
+}
+
+// parseImportFile parses a CSV in the export layout. Only the Telegram ID column is required,
+// ID, Status and Updated At columns are ignored. Invalid values are reported per row.
+func parseImportFile(r io.Reader, location *time.Location) ([]*domain.UserImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	knownKeys := importColumnKeys()
+	indexes := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\uFEFF")
+		}
+
+		if key, ok := knownKeys[strings.ToLower(strings.TrimSpace(name))]; ok {
+			indexes[key] = i
+		}
+	}
+
+	if _, ok := indexes["telegram_id"]; !ok {
+		return nil, fmt.Errorf("header must have a 'Telegram ID' column")
+	}
+
+	var rows []*domain.UserImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("file must have at most %d rows", maxImportRows)
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, parseImportRecord(line, record, indexes, location))
+	}
+
+	return rows, nil
+}
+
+func parseImportRecord(line int, record []string, indexes map[string]int, location *time.Location) *domain.UserImportRow {
+	row := &domain.UserImportRow{Row: line}
+
+	value := func(key string) string {
+		i, ok := indexes[key]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	telegramID, err := strconv.ParseInt(value("telegram_id"), 10, 64)
+	if err != nil || telegramID <= 0 {
+		row.ParseErrors = append(row.ParseErrors, "telegram_id must be a positive integer")
+	}
+	row.TelegramID = telegramID
+
+	row.Username = strings.TrimPrefix(value("username"), "@")
+	if len(row.Username) > 255 {
+		row.ParseErrors = append(row.ParseErrors, "username must be at most 255 characters long")
+	}
+
+	if channelID := value("channel_id"); channelID != "" {
+		id, err := strconv.Atoi(channelID)
+		if err != nil || id <= 0 {
+			row.ParseErrors = append(row.ParseErrors, "channel_id must be a positive integer")
+		} else {
+			row.ChannelID = &id
+		}
+	}
+
+	row.ChannelRef = value("channel_name")
+	if row.ChannelRef == "" {
+		if link := value("channel_link"); link != "" {
+			code, err := channelCodeFromLink(link)
+			if err != nil {
+				row.ParseErrors = append(row.ParseErrors, err.Error())
+			}
+			row.ChannelRef = code
+		}
+	}
+
+	if createdAt := value("created_at"); createdAt != "" {
+		t, err := parseImportTime(createdAt, location)
+		if err != nil {
+			row.ParseErrors = append(row.ParseErrors, "created_at must be in '2006-01-02 15:04:05' or RFC3339 format")
+		} else {
+			row.CreatedAt = &t
+		}
+	}
+
+	return row
+}
+
+// channelCodeFromLink extracts the channel code from a bot link, e.g. https://t.me/bot?start=<code>
+func channelCodeFromLink(link string) (string, error) {
+	parsedURL, err := url.Parse(link)
+	if err != nil {
+		return "", fmt.Errorf("channel_link has invalid URL format")
+	}
+
+	query := parsedURL.Query()
+	for _, linkType := range []string{domain.ChannelLinkTypeStart, domain.ChannelLinkTypeStartApp} {
+		if code := query.Get(linkType); code != "" {
+			return code, nil
+		}
+	}
+
+	return "", fmt.Errorf("channel_link has no channel code")
+}
+
+func parseImportTime(value string, location *time.Location) (time.Time, error) {
+	if t, err := time.ParseInLocation(exportTimeFormat, value, location); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
@@ -1,19 +1,23 @@
 package user
 
 import (
-	"encoding/csv"
+	"errors"
 	"fmt"
 	"hr-server/internal/api/http/controllers/common"
 	"hr-server/internal/api/http/controllers/user/dto"
+	"hr-server/internal/api/http/middleware"
+	"hr-server/internal/domain"
+	"hr-server/internal/logger"
 	"hr-server/internal/service"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 )
 
+const exportBatchSize = 1000
+
 type UserController struct {
 	userService *service.UserService
 }
@@ -23,20 +27,47 @@ func NewUserController(userService *service.UserService) *UserController {
 }
 
 // GetUsers godoc
-// @Summary Get all users
-// @Description Get all registered users
+// @Summary Get users
+// @Description Get a page of registered users filtered and sorted by the query parameters
 // @Tags Users
 // @Accept json
 // @Produce json
+// @Param channel_id query int false "Channel ID"
+// @Param created_from query string false "Created at or after, RFC3339"
+// @Param created_to query string false "Created before, RFC3339"
+// @Param username query string false "Username substring, case-insensitive"
+// @Param status query string false "User status" Enums(active, blocked, erased)
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size, 50 by default, at most 500"
+// @Param sort_by query string false "Sort field" Enums(created_at, username, telegram_id)
+// @Param sort_order query string false "Sort order, desc by default" Enums(asc, desc)
 // @Success 200 {object} dto.GetUsersResponse
+// @Failure 400 {object} common.ErrorResponse
 // @Failure 500 {object} common.ErrorResponse
 // @Security XAuthToken
 // @Router /users [get]
 func (c *UserController) GetUsersHandler() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		users, err := c.userService.GetAllUsersWithChannel()
+		req := dto.NewGetUsersRequest()
+		if err := req.Parse(ctx); err != nil {
+			logger.FromContext(ctx).Error("unable to parse a request: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			logger.FromContext(ctx).Error("error of validation: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		users, err := c.userService.GetUsersPage(ctx, req.Filter(), req.ToDomain())
+		if errors.Is(err, domain.ErrInvalidCursor) {
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
 		if err != nil {
-			logrus.Error("error while get all users: ", err)
+			logger.FromContext(ctx).Error("error while get all users: ", err)
 			ctx.JSON(
 				http.StatusInternalServerError,
 				common.ErrorResponse{Error: fmt.Sprintf("failed to get all users: %v", err)},
@@ -49,89 +80,348 @@ func (c *UserController) GetUsersHandler() gin.HandlerFunc {
 	}
 }
 
+// GetUser godoc
+// @Summary Get user
+// @Description Get a user with channel information by Telegram ID
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param telegram_id path int true "Telegram ID"
+// @Success 200 {object} domain.UserWithChannel
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /users/{telegram_id} [get]
+func (c *UserController) GetUserHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		telegramID, err := parseTelegramID(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		user, err := c.userService.GetUserWithChannel(ctx, telegramID)
+		if err != nil {
+			logger.FromContext(ctx).Error("error while get user: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to get user %d: %v", telegramID, err)})
+			return
+		}
+
+		if user == nil {
+			ctx.JSON(http.StatusNotFound, common.ErrorResponse{Error: "User not found"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, user)
+	}
+}
+
+// UpdateUser godoc
+// @Summary Update user
+// @Description Correct the channel attribution, the status or the timezone of a user
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param telegram_id path int true "Telegram ID"
+// @Param request body dto.UpdateUserRequest true "Update user request"
+// @Success 200 {object} domain.UserWithChannel
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /users/{telegram_id} [patch]
+func (c *UserController) UpdateUserHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		telegramID, err := parseTelegramID(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		req := dto.NewUpdateUserRequest()
+		if err := req.Parse(ctx); err != nil {
+			logger.FromContext(ctx).Error("unable to parse a request: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			logger.FromContext(ctx).Error("error of validation: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		user, err := c.userService.UpdateUser(ctx, telegramID, req.ToDomain())
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			ctx.JSON(http.StatusNotFound, common.ErrorResponse{Error: "User not found"})
+			return
+		case errors.Is(err, service.ErrChannelNotFound):
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		case err != nil:
+			logger.FromContext(ctx).Error("error while update user: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to update user %d: %v", telegramID, err)})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, user)
+	}
+}
+
+// DeleteUser godoc
+// @Summary Delete user
+// @Description Delete a user by Telegram ID, use the erase endpoint for right-to-erasure requests
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param telegram_id path int true "Telegram ID"
+// @Success 200 {object} common.SuccessResponse
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /users/{telegram_id} [delete]
+func (c *UserController) DeleteUserHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		telegramID, err := parseTelegramID(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		err = c.userService.DeleteUser(ctx, telegramID)
+		if errors.Is(err, service.ErrUserNotFound) {
+			ctx.JSON(http.StatusNotFound, common.ErrorResponse{Error: "User not found"})
+			return
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error("error while delete user: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to delete user %d: %v", telegramID, err)})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, common.SuccessResponse{})
+	}
+}
+
+// EraseUser godoc
+// @Summary Erase user
+// @Description Right-to-erasure: delete or anonymize a user with all related records and write an audit entry
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param telegram_id path int true "Telegram ID"
+// @Param request body dto.EraseUserRequest true "Erase user request"
+// @Success 200 {object} domain.UserErasure
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 404 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /users/{telegram_id}/erase [post]
+func (c *UserController) EraseUserHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		telegramID, err := parseTelegramID(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		req := dto.NewEraseUserRequest()
+		if err := req.Parse(ctx); err != nil {
+			logger.FromContext(ctx).Error("unable to parse a request: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			logger.FromContext(ctx).Error("error of validation: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		// The erasure is attributed to the admin or the API key, e.g. "admin:3 hr-manager"
+		var requestedBy string
+		if actorType, actorID, actorName := middleware.Actor(ctx); actorType != "" {
+			requestedBy = fmt.Sprintf("%s:%d %s", actorType, actorID, actorName)
+		}
+
+		erasure, err := c.userService.EraseUser(ctx, telegramID, req.Mode, req.Reason, requestedBy)
+		if errors.Is(err, service.ErrUserNotFound) {
+			ctx.JSON(http.StatusNotFound, common.ErrorResponse{Error: "User not found"})
+			return
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error("error while erase user: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: fmt.Sprintf("failed to erase user: %v", err)})
+			return
+		}
+
+		// The audit entry of the erasure refers to the internal ID, the Telegram ID is erased from the audit log
+		middleware.SetAuditTarget(ctx, domain.ErasedUserReference(erasure.UserID))
+
+		ctx.JSON(http.StatusOK, erasure)
+	}
+}
+
+// ImportUsers godoc
+// @Summary Import users from CSV
+// @Description Upsert users by Telegram ID from a CSV in the export layout and return a per-row report
+// @Description Channels are resolved by Channel ID, by Channel Name (name or code) or by the code in Bot Start Link
+// @Tags Users
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV file"
+// @Param dry_run formData bool false "Validate and report without writing"
+// @Param timezone formData string false "IANA timezone of the dates, UTC by default"
+// @Success 200 {object} domain.UserImportReport
+// @Failure 400 {object} common.ErrorResponse
+// @Failure 500 {object} common.ErrorResponse
+// @Security XAuthToken
+// @Router /users/import [post]
+func (c *UserController) ImportUsersHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		req := dto.NewImportUsersRequest()
+		if err := req.Parse(ctx); err != nil {
+			logger.FromContext(ctx).Error("unable to parse a request: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			logger.FromContext(ctx).Error("error of validation: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		file, err := req.File.Open()
+		if err != nil {
+			logger.FromContext(ctx).Error("error while open import file: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: "failed to open file"})
+			return
+		}
+		defer file.Close()
+
+		rows, err := parseImportFile(file, req.Location)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		report := c.userService.ImportUsers(ctx, rows, req.DryRun)
+		ctx.JSON(http.StatusOK, report)
+	}
+}
+
 // ExportUsers godoc
-// @Summary Export all users to CSV
-// @Description Export all registered users with channel information to CSV format
+// @Summary Export users to CSV or XLSX
+// @Description Stream registered users with channel information matching the filters as a CSV or XLSX file
 // @Tags Users
 // @Accept json
-// @Produce text/csv
-// @Success 200 {file} file CSV file with users data
+// @Produce text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param channel_id query int false "Channel ID"
+// @Param created_from query string false "Created at or after, RFC3339"
+// @Param created_to query string false "Created before, RFC3339"
+// @Param username query string false "Username substring, case-insensitive"
+// @Param status query string false "User status" Enums(active, blocked, erased)
+// @Param format query string false "File format, csv by default" Enums(csv, xlsx)
+// @Param columns query []string false "Columns in order, all by default" collectionFormat(csv) Enums(id, telegram_id, username, channel_id, channel_name, channel_link, status, created_at, updated_at, last_seen_at)
+// @Param timezone query string false "IANA timezone of the dates, UTC by default"
+// @Param bom query bool false "Prepend a UTF-8 BOM to the CSV for Excel"
+// @Success 200 {file} file File with users data
+// @Failure 400 {object} common.ErrorResponse
 // @Failure 500 {object} common.ErrorResponse
 // @Security XAuthToken
 // @Router /users/export [get]
 func (c *UserController) ExportUsersHandler() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		users, err := c.userService.GetAllUsersWithChannel()
+		req := dto.NewExportUsersRequest()
+		if err := req.Parse(ctx); err != nil {
+			logger.FromContext(ctx).Error("unable to parse a request: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := req.Validate(exportColumnKeys()); err != nil {
+			logger.FromContext(ctx).Error("error of validation: ", err)
+			ctx.JSON(http.StatusBadRequest, common.ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		columns := selectExportColumns(req.Columns)
+
+		var writer rowWriter
+		var err error
+		switch req.Format {
+		case dto.ExportFormatXLSX:
+			ctx.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+			writer, err = newXLSXRowWriter(ctx.Writer)
+		default:
+			ctx.Header("Content-Type", "text/csv; charset=utf-8")
+			writer, err = newCSVRowWriter(ctx.Writer, req.BOM)
+		}
 		if err != nil {
-			logrus.Error("error while get all users for export: ", err)
-			ctx.JSON(
-				http.StatusInternalServerError,
-				common.ErrorResponse{Error: fmt.Sprintf("failed to get all users: %v", err)},
-			)
+			logger.FromContext(ctx).Error("error while creating export writer: ", err)
+			ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: "failed to generate export"})
 			return
 		}
 
-		// Set CSV headers
-		ctx.Header("Content-Type", "text/csv")
 		ctx.Header(
 			"Content-Disposition",
-			"attachment; filename=users_export_"+time.Now().Format("2006-01-02")+".csv",
+			"attachment; filename=users_export_"+time.Now().In(req.Location).Format("2006-01-02")+"."+req.Format,
 		)
 
-		// Create CSV writer
-		writer := csv.NewWriter(ctx.Writer)
-		defer writer.Flush()
-
-		// Write CSV header
-		header := []string{
-			"ID",
-			"Telegram ID",
-			"Username",
-			"Channel ID",
-			"Channel Name",
-			"Bot Start Link",
-			"Created At",
-			"Updated At",
-		}
-		if err := writer.Write(header); err != nil {
-			logrus.Error("error while writing CSV header: ", err)
-			ctx.JSON(
-				http.StatusInternalServerError,
-				common.ErrorResponse{Error: "failed to generate CSV"},
-			)
-			return
+		header := make([]string, 0, len(columns))
+		for _, column := range columns {
+			header = append(header, column.header)
 		}
 
-		// Write user data
-		for _, user := range users {
-			channelID := ""
-			if user.ChannelID != nil {
-				channelID = strconv.Itoa(*user.ChannelID)
-			}
+		err = writer.WriteRow(header)
+		if err == nil {
+			err = c.userService.ExportUsers(ctx, req.Filter(), exportBatchSize, func(users []*domain.UserWithChannel) error {
+				for _, user := range users {
+					row := make([]string, 0, len(columns))
+					for _, column := range columns {
+						row = append(row, column.value(user, req.Location))
+					}
 
-			channelName := ""
-			if user.ChannelName != nil {
-				channelName = *user.ChannelName
-			}
+					if err := writer.WriteRow(row); err != nil {
+						return fmt.Errorf("failed to write row: %w", err)
+					}
+				}
 
-			row := []string{
-				strconv.Itoa(user.ID),
-				strconv.FormatInt(user.TelegramID, 10),
-				user.Username,
-				channelID,
-				channelName,
-				user.CreatedAt.Format("2006-01-02 15:04:05"),
-				user.UpdatedAt.Format("2006-01-02 15:04:05"),
-			}
+				if err := writer.Flush(); err != nil {
+					return fmt.Errorf("failed to flush rows: %w", err)
+				}
+				ctx.Writer.Flush()
+
+				return nil
+			})
+		}
+		if err == nil {
+			err = writer.Close()
+		}
 
-			if err := writer.Write(row); err != nil {
-				logrus.Error("error while writing CSV row: ", err)
-				ctx.JSON(
-					http.StatusInternalServerError,
-					common.ErrorResponse{Error: "failed to generate CSV"},
-				)
-				return
+		if err != nil {
+			logger.FromContext(ctx).Error("error while exporting users: ", err)
+
+			// The response is already partially sent, so only a not started one can report the error
+			if !ctx.Writer.Written() {
+				ctx.Writer.Header().Del("Content-Disposition")
+				ctx.JSON(http.StatusInternalServerError, common.ErrorResponse{Error: "failed to generate export"})
 			}
+			ctx.Abort()
+			return
 		}
 	}
 }
+
+func parseTelegramID(ctx *gin.Context) (int64, error) {
+	telegramID, err := strconv.ParseInt(ctx.Param("telegram_id"), 10, 64)
+	if err != nil || telegramID <= 0 {
+		return 0, fmt.Errorf("telegram_id must be a positive integer")
+	}
+
+	return telegramID, nil
+}
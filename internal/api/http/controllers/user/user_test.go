@@ -0,0 +1,288 @@
+package user_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"hr-server/internal/api/http/controllers/common"
+	"hr-server/internal/api/http/controllers/controllertest"
+	"hr-server/internal/api/http/controllers/user"
+	"hr-server/internal/api/http/controllers/user/dto"
+	"hr-server/internal/api/http/middleware"
+	"hr-server/internal/domain"
+	"hr-server/internal/service/servicetest"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRouter(t *testing.T) (*gin.Engine, *servicetest.Services) {
+	t.Helper()
+
+	s := servicetest.NewServices(servicetest.Config())
+	controller := user.NewUserController(s.User)
+
+	router := controllertest.NewRouter()
+	router.GET("/users", controller.GetUsersHandler())
+	router.GET("/users/export", controller.ExportUsersHandler())
+	router.POST("/users/import", controller.ImportUsersHandler())
+	router.GET("/users/:telegram_id", controller.GetUserHandler())
+	router.PATCH("/users/:telegram_id", controller.UpdateUserHandler())
+	router.DELETE("/users/:telegram_id", controller.DeleteUserHandler())
+	router.POST("/users/:telegram_id/erase", controller.EraseUserHandler())
+
+	return router, s
+}
+
+func TestGetUsersHandler(t *testing.T) {
+	router, s := newRouter(t)
+
+	channel, err := s.Channel.GenerateChannel(t.Context(), "hh.ru", domain.ChannelAttributes{})
+	require.NoError(t, err)
+	s.AddUser(t, 1, "alice", &channel.ID)
+	s.AddUser(t, 2, "bob", nil)
+	s.AddUser(t, 3, "carol", nil)
+
+	recorder := controllertest.Do(t, router, http.MethodGet, "/users?channel_id="+strconv.Itoa(channel.ID), nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	response := controllertest.Decode[dto.GetUsersResponse](t, recorder)
+	assert.EqualValues(t, 1, response.TotalCount)
+	require.Len(t, response.Users, 1)
+	assert.Equal(t, "alice", response.Users[0].Username)
+	assert.Equal(t, "hh.ru", *response.Users[0].ChannelName)
+
+	recorder = controllertest.Do(t, router, http.MethodGet, "/users?limit=2&sort_by=username&sort_order=asc", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	response = controllertest.Decode[dto.GetUsersResponse](t, recorder)
+	require.Len(t, response.Users, 2)
+	require.NotEmpty(t, response.NextCursor)
+
+	recorder = controllertest.Do(t, router, http.MethodGet, "/users?limit=2&sort_by=username&sort_order=asc&cursor="+response.NextCursor, nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	response = controllertest.Decode[dto.GetUsersResponse](t, recorder)
+	require.Len(t, response.Users, 1)
+	assert.Equal(t, "carol", response.Users[0].Username)
+	assert.Empty(t, response.NextCursor)
+
+	t.Run("invalid requests", func(t *testing.T) {
+		for _, query := range []string{"cursor=garbage", "sort_by=password", "limit=-1", "status=unknown"} {
+			recorder := controllertest.Do(t, router, http.MethodGet, "/users?"+query, nil)
+			assert.Equal(t, http.StatusBadRequest, recorder.Code, query)
+		}
+	})
+}
+
+func TestGetUserHandler(t *testing.T) {
+	router, s := newRouter(t)
+	s.AddUser(t, 1, "alice", nil)
+
+	recorder := controllertest.Do(t, router, http.MethodGet, "/users/1", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "alice", controllertest.Decode[domain.UserWithChannel](t, recorder).Username)
+
+	recorder = controllertest.Do(t, router, http.MethodGet, "/users/2", nil)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = controllertest.Do(t, router, http.MethodGet, "/users/abc", nil)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestUpdateUserHandler(t *testing.T) {
+	router, s := newRouter(t)
+
+	channel, err := s.Channel.GenerateChannel(t.Context(), "hh.ru", domain.ChannelAttributes{})
+	require.NoError(t, err)
+	s.AddUser(t, 1, "alice", nil)
+
+	recorder := controllertest.Do(t, router, http.MethodPatch, "/users/1", gin.H{"channel_id": channel.ID, "status": domain.UserStatusBlocked})
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	updated := controllertest.Decode[domain.UserWithChannel](t, recorder)
+	assert.Equal(t, &channel.ID, updated.ChannelID)
+	assert.Equal(t, domain.UserStatusBlocked, updated.Status)
+
+	recorder = controllertest.Do(t, router, http.MethodPatch, "/users/1", gin.H{"channel_id": 100})
+	assert.Equal(t, http.StatusBadRequest, recorder.Code, "missing channel")
+
+	recorder = controllertest.Do(t, router, http.MethodPatch, "/users/1", gin.H{"status": "unknown"})
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = controllertest.Do(t, router, http.MethodPatch, "/users/1", gin.H{"timezone": "Asia/Vladivostok"})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "Asia/Vladivostok", controllertest.Decode[domain.UserWithChannel](t, recorder).Timezone)
+
+	recorder = controllertest.Do(t, router, http.MethodPatch, "/users/1", gin.H{"timezone": "Local"})
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = controllertest.Do(t, router, http.MethodPatch, "/users/2", gin.H{"clear_channel": true})
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestDeleteUserHandler(t *testing.T) {
+	router, s := newRouter(t)
+	s.AddUser(t, 1, "alice", nil)
+
+	recorder := controllertest.Do(t, router, http.MethodDelete, "/users/1", nil)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = controllertest.Do(t, router, http.MethodDelete, "/users/1", nil)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestEraseUserHandler(t *testing.T) {
+	_, s := newRouter(t)
+	s.AddUser(t, 1, "alice", nil)
+
+	// The erasure is attributed to the API key set by the auth middleware
+	router := controllertest.NewRouter()
+	router.POST("/users/:telegram_id/erase", func(c *gin.Context) {
+		c.Set(middleware.APIKeyContextKey, &domain.APIKey{ID: 5, Name: "hr-panel"})
+	}, user.NewUserController(s.User).EraseUserHandler())
+
+	recorder := controllertest.Do(t, router, http.MethodPost, "/users/1/erase", gin.H{"mode": "unknown", "reason": "request"})
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = controllertest.Do(t, router, http.MethodPost, "/users/1/erase", gin.H{"mode": domain.ErasureModeAnonymize, "reason": "request"})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	erasure := controllertest.Decode[domain.UserErasure](t, recorder)
+	assert.Equal(t, domain.ErasureModeAnonymize, erasure.Mode)
+	assert.Equal(t, "api_key:5 hr-panel", erasure.RequestedBy)
+
+	recorder = controllertest.Do(t, router, http.MethodPost, "/users/1/erase", gin.H{"mode": domain.ErasureModeDelete, "reason": "request"})
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestEraseUserHandler_AuditLog(t *testing.T) {
+	_, s := newRouter(t)
+	s.AddUser(t, 123456789, "alice_hr", nil)
+	s.AddUser(t, 1234567890, "bob", nil)
+
+	controller := user.NewUserController(s.User)
+	apiKey := func(c *gin.Context) {
+		c.Set(middleware.APIKeyContextKey, &domain.APIKey{ID: 5, Name: "hr-panel"})
+	}
+
+	router := controllertest.NewRouter()
+	router.PATCH("/api/users/:telegram_id", apiKey, middleware.Audit(s.Audit, domain.AuditActionUserUpdate), controller.UpdateUserHandler())
+	router.POST("/api/users/:telegram_id/erase", apiKey, middleware.Audit(s.Audit, domain.AuditActionUserErase), controller.EraseUserHandler())
+
+	for _, telegramID := range []string{"123456789", "1234567890"} {
+		recorder := controllertest.Do(t, router, http.MethodPatch, "/api/users/"+telegramID, gin.H{"status": domain.UserStatusBlocked})
+		require.Equal(t, http.StatusOK, recorder.Code)
+	}
+	require.NoError(t, s.Audit.Record(t.Context(), &domain.AuditEntry{
+		Action:  domain.AuditActionUserImport,
+		Request: "POST /api/users/import\n123456789,alice_hr\n1234567890,bob",
+	}))
+
+	recorder := controllertest.Do(t, router, http.MethodPost, "/api/users/123456789/erase", gin.H{"mode": domain.ErasureModeAnonymize, "reason": "request"})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	reference := domain.ErasedUserReference(controllertest.Decode[domain.UserErasure](t, recorder).UserID)
+
+	page, err := s.Audit.GetAuditPage(t.Context(), domain.AuditFilter{}, domain.PageRequest{Limit: 100})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 4)
+
+	targets := map[string]string{}
+	for _, entry := range page.Items {
+		for _, field := range []string{entry.Target, entry.Request} {
+			assert.NotRegexp(t, `\b123456789\b`, field, "the Telegram ID is erased from the %s entry", entry.Action)
+			assert.NotContains(t, field, "alice_hr", "the username is erased from the %s entry", entry.Action)
+		}
+		targets[entry.Action+" "+entry.Target] = entry.Request
+	}
+
+	assert.Equal(t, "POST /api/users/"+reference+"/erase\n{\"mode\":\"anonymize\",\"reason\":\"request\"}", targets[domain.AuditActionUserErase+" "+reference])
+	assert.Contains(t, targets, domain.AuditActionUserUpdate+" "+reference)
+	assert.Contains(t, targets, domain.AuditActionUserUpdate+" 1234567890", "the entries of other users are kept")
+	assert.Equal(t, "POST /api/users/import\n"+reference+","+domain.ErasedName+"\n1234567890,bob", targets[domain.AuditActionUserImport+" "])
+}
+
+func TestExportUsersHandler(t *testing.T) {
+	router, s := newRouter(t)
+	s.AddUser(t, 1, "alice", nil)
+	s.AddUser(t, 2, "bob", nil)
+
+	recorder := controllertest.Do(t, router, http.MethodGet, "/users/export?format=csv&columns=telegram_id,username", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Header().Get("Content-Type"), "text/csv")
+
+	records, err := csv.NewReader(recorder.Body).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"Telegram ID", "Username"}, {"1", "alice"}, {"2", "bob"}}, records)
+
+	recorder = controllertest.Do(t, router, http.MethodGet, "/users/export?format=xlsx", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.True(t, bytes.HasPrefix(recorder.Body.Bytes(), []byte("PK")), "xlsx is a zip archive")
+
+	recorder = controllertest.Do(t, router, http.MethodGet, "/users/export?columns=password", nil)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestImportUsersHandler(t *testing.T) {
+	router, s := newRouter(t)
+
+	_, err := s.Channel.GenerateChannel(t.Context(), "hh.ru", domain.ChannelAttributes{})
+	require.NoError(t, err)
+
+	file := strings.Join([]string{
+		"Telegram ID,Username,Channel Name",
+		"1,alice,hh.ru",
+		"2,bob,",
+		"abc,carol,",
+	}, "\n")
+
+	recorder := upload(t, router, file, "dry_run", "true")
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	report := controllertest.Decode[domain.UserImportReport](t, recorder)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 2, report.Created)
+	assert.Equal(t, 1, report.Failed)
+
+	all, err := s.User.GetAllUsers(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, all)
+
+	recorder = upload(t, router, file)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, 2, controllertest.Decode[domain.UserImportReport](t, recorder).Created)
+
+	user, err := s.User.GetUserWithChannel(t.Context(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "hh.ru", *user.ChannelName)
+
+	recorder = upload(t, router, "Username\nalice")
+	assert.Equal(t, http.StatusBadRequest, recorder.Code, "no Telegram ID column")
+
+	recorder = controllertest.Do(t, router, http.MethodPost, "/users/import", nil)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.NotEmpty(t, controllertest.Decode[common.ErrorResponse](t, recorder).Error)
+}
+
+// upload posts the CSV file to the import endpoint with the form fields given as name, value pairs
+func upload(t *testing.T, router *gin.Engine, content string, fields ...string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for i := 0; i+1 < len(fields); i += 2 {
+		require.NoError(t, writer.WriteField(fields[i], fields[i+1]))
+	}
+	part, err := writer.CreateFormFile("file", "users.csv")
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	return controllertest.Do(t, router, http.MethodPost, "/users/import", &body, "Content-Type", writer.FormDataContentType())
+}
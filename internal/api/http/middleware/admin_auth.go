@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"errors"
+	"hr-server/internal/logger"
+	"hr-server/internal/service"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminContextKey is the gin context key of the authenticated *domain.Admin
+const AdminContextKey = "admin"
+
+const bearerPrefix = "Bearer "
+
+// AdminAuthMiddleware authenticates admins by the JWT access token from the Authorization header,
+// requests without the header are passed on to AuthTokenMiddleware
+func AdminAuthMiddleware(adminService *service.AdminService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.Request.Header.Get("Authorization")
+		if !strings.HasPrefix(header, bearerPrefix) {
+			c.Next()
+			return
+		}
+
+		admin, err := adminService.AuthenticateAccessToken(c, strings.TrimPrefix(header, bearerPrefix))
+		if err != nil {
+			if !errors.Is(err, service.ErrInvalidAdminToken) && !errors.Is(err, service.ErrAdminAuthDisabled) {
+				logger.FromContext(c).Error("error while authenticate admin: ", err)
+			}
+
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid access token"})
+			return
+		}
+
+		c.Set(AdminContextKey, admin)
+		c.Next()
+	}
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"context"
+	"hr-server/internal/domain"
+	"hr-server/internal/logger"
+	"hr-server/internal/service"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// auditTargetKey is the gin context key of the target set by SetAuditTarget
+const auditTargetKey = "audit_target"
+
+const (
+	maxAuditBodyRead    = 16 << 10 // bodies over this size are summarized by their size only
+	maxAuditBodySummary = 1000
+)
+
+// auditRedactor masks secrets in audit request summaries, unlike the log redactor
+// it keeps PII and message texts since the audit log must show what was done
+var auditRedactor = NewRedactor([]string{"password", "token", "refresh_token", "hash"}, nil, maxAuditBodyRead)
+
+// Audit records the action to the audit log after the handler has run.
+// It must be placed after the authentication middlewares to know the actor.
+func Audit(auditService *service.AuditService, action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body := truncate(auditRedactor.RequestBody(c), maxAuditBodySummary)
+
+		c.Next()
+
+		target, path := auditTarget(c), c.Request.URL.Path
+		if override := c.GetString(auditTargetKey); override != "" {
+			target, path = override, replacePathParams(c, path, override)
+		}
+
+		entry := &domain.AuditEntry{
+			Action:   action,
+			Target:   target,
+			Request:  auditRequestSummary(c, path, body),
+			Status:   c.Writer.Status(),
+			ClientIP: c.ClientIP(),
+		}
+
+		entry.ActorType, entry.ActorID, entry.ActorName = Actor(c)
+
+		// the entry is recorded even if the client has gone away
+		if err := auditService.Record(context.WithoutCancel(c), entry); err != nil {
+			logger.FromContext(c).Error("error while record audit entry: ", err)
+		}
+	}
+}
+
+// Actor returns the type, the ID and the name of the admin or the API key of the request,
+// an empty type if the request isn't authenticated
+func Actor(c *gin.Context) (string, int, string) {
+	if admin, ok := c.Value(AdminContextKey).(*domain.Admin); ok {
+		return domain.AuditActorAdmin, admin.ID, admin.Username
+	}
+	if key, ok := c.Value(APIKeyContextKey).(*domain.APIKey); ok {
+		return domain.AuditActorAPIKey, key.ID, key.Name
+	}
+
+	return "", 0, ""
+}
+
+// ActorScopes returns the scopes of the admin or the API key of the request, nil if the request isn't authenticated
+func ActorScopes(c *gin.Context) []string {
+	if admin, ok := c.Value(AdminContextKey).(*domain.Admin); ok {
+		return admin.Scopes
+	}
+	if key, ok := c.Value(APIKeyContextKey).(*domain.APIKey); ok {
+		return key.Scopes
+	}
+
+	return nil
+}
+
+// auditTarget returns the path parameters identifying the affected entity
+func auditTarget(c *gin.Context) string {
+	values := make([]string, 0, len(c.Params))
+	for _, param := range c.Params {
+		values = append(values, param.Value)
+	}
+
+	return strings.Join(values, ",")
+}
+
+// SetAuditTarget replaces the path parameters in the target and the request of the audit entry,
+// so that the entry of an erasure doesn't identify the erased user
+func SetAuditTarget(c *gin.Context, target string) {
+	c.Set(auditTargetKey, target)
+}
+
+// replacePathParams replaces the path segments holding the path parameters
+func replacePathParams(c *gin.Context, path, replacement string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		for _, param := range c.Params {
+			if segment == param.Value {
+				segments[i] = replacement
+			}
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func auditRequestSummary(c *gin.Context, path, body string) string {
+	summary := c.Request.Method + " " + path
+	if c.Request.URL.RawQuery != "" {
+		summary += "?" + c.Request.URL.RawQuery
+	}
+
+	if body != "" {
+		summary += "\n" + body
+	}
+
+	return summary
+}
@@ -1,18 +1,59 @@
 package middleware
 
 import (
+	"errors"
+	"hr-server/internal/domain"
+	"hr-server/internal/logger"
+	"hr-server/internal/service"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthTokenMiddleware(authToken string) gin.HandlerFunc {
+// APIKeyContextKey is the gin context key of the authenticated *domain.APIKey
+const APIKeyContextKey = "api_key"
+
+func AuthTokenMiddleware(apiKeyService *service.APIKeyService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if c.Request.Header.Get("X-Auth-Token") != authToken {
+		if _, ok := c.Value(AdminContextKey).(*domain.Admin); ok {
+			c.Next()
+			return
+		}
+
+		key, err := apiKeyService.Authenticate(c, c.Request.Header.Get("X-Auth-Token"))
+		if err != nil {
+			if !errors.Is(err, service.ErrInvalidAPIKey) {
+				logger.FromContext(c).Error("error while authenticate API key: ", err)
+			}
+
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Invalid auth token"})
 			return
 		}
 
+		c.Set(APIKeyContextKey, key)
+		c.Next()
+	}
+}
+
+// RequireScope rejects requests whose API key or admin doesn't grant the scope
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if admin, ok := c.Value(AdminContextKey).(*domain.Admin); ok {
+			if !admin.HasScope(scope) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin lacks scope " + scope})
+				return
+			}
+
+			c.Next()
+			return
+		}
+
+		key, ok := c.Value(APIKeyContextKey).(*domain.APIKey)
+		if !ok || !key.HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key lacks scope " + scope})
+			return
+		}
+
 		c.Next()
 	}
 }
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"hr-server/internal/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QueryTimeoutMiddleware limits each repository call made with the request context by the timeout,
+// the calls are cancelled as well when the client goes away
+func QueryTimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(repository.WithQueryTimeout(c.Request.Context(), timeout))
+		c.Next()
+	}
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+const redactedValue = "[REDACTED]"
+
+// Redactor summarizes request bodies and headers for logs with sensitive values masked
+type Redactor struct {
+	fields      map[string]bool
+	headers     map[string]bool
+	maxBodySize int
+}
+
+// NewRedactor creates a redactor masking the JSON fields and the headers, field names are case-insensitive
+func NewRedactor(fields, headers []string, maxBodySize int) *Redactor {
+	r := &Redactor{
+		fields:      make(map[string]bool, len(fields)),
+		headers:     make(map[string]bool, len(headers)),
+		maxBodySize: maxBodySize,
+	}
+
+	for _, field := range fields {
+		r.fields[strings.ToLower(field)] = true
+	}
+
+	for _, header := range headers {
+		r.headers[http.CanonicalHeaderKey(header)] = true
+	}
+
+	return r
+}
+
+// RequestBody reads at most maxBodySize bytes of the request body, restores the body for the handler
+// and returns it with the sensitive fields masked. Only JSON bodies are returned,
+// multipart, oversized and other bodies are summarized by their type and size.
+func (r *Redactor) RequestBody(c *gin.Context) string {
+	if c.Request.Body == nil || c.Request.ContentLength == 0 {
+		return ""
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.Request.Header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		if c.Request.ContentLength < 0 {
+			return fmt.Sprintf("%s body", mediaType)
+		}
+		return fmt.Sprintf("%s body, %d bytes", mediaType, c.Request.ContentLength)
+	}
+
+	head, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(r.maxBodySize)+1))
+	c.Request.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), c.Request.Body), c.Request.Body}
+	if err != nil {
+		return "unreadable body"
+	}
+
+	if len(head) > r.maxBodySize {
+		return fmt.Sprintf("JSON body over %d bytes", r.maxBodySize)
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(head, &value); err != nil {
+		return fmt.Sprintf("invalid JSON body, %d bytes", len(head))
+	}
+
+	redacted, _ := json.Marshal(r.redactFields(value))
+
+	return string(redacted)
+}
+
+// Query returns the raw query with the values of sensitive fields masked
+func (r *Redactor) Query(rawQuery string) string {
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "invalid query"
+	}
+
+	for key, values := range query {
+		if r.fields[strings.ToLower(key)] {
+			for i := range values {
+				values[i] = redactedValue
+			}
+		}
+	}
+
+	return query.Encode()
+}
+
+// Headers returns the request headers with the sensitive ones masked
+func (r *Redactor) Headers(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for key, values := range header {
+		if r.headers[key] {
+			headers[key] = redactedValue
+		} else {
+			headers[key] = strings.Join(values, ", ")
+		}
+	}
+
+	return headers
+}
+
+// redactFields replaces values of sensitive fields in a decoded JSON value
+func (r *Redactor) redactFields(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if r.fields[strings.ToLower(key)] {
+				v[key] = redactedValue
+			} else {
+				v[key] = r.redactFields(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.redactFields(item)
+		}
+	}
+
+	return value
+}
+
+func truncate(s string, maxLength int) string {
+	if len(s) <= maxLength {
+		return s
+	}
+
+	s = s[:maxLength]
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+
+	return s + "…"
+}
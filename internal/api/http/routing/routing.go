@@ -1,20 +1,30 @@
 package routing
 
 import (
-	"bytes"
 	"hr-server/config"
+	"hr-server/internal/api/http/controllers/admin"
+	"hr-server/internal/api/http/controllers/apikey"
+	"hr-server/internal/api/http/controllers/audit"
+	"hr-server/internal/api/http/controllers/auth"
+	"hr-server/internal/api/http/controllers/campaign"
 	"hr-server/internal/api/http/controllers/channel"
+	"hr-server/internal/api/http/controllers/drip"
+	"hr-server/internal/api/http/controllers/health"
 	"hr-server/internal/api/http/controllers/notification"
+	"hr-server/internal/api/http/controllers/redirect"
+	"hr-server/internal/api/http/controllers/source"
 	"hr-server/internal/api/http/controllers/user"
 	_ "hr-server/internal/api/http/docs"
 	"hr-server/internal/api/http/middleware"
+	"hr-server/internal/domain"
+	"hr-server/internal/logger"
+	"hr-server/internal/metrics"
 	"hr-server/internal/service"
-	"io"
-	"net/http"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -23,19 +33,19 @@ import (
 var ignoredPrefixPaths = []string{
 	"/api/health",
 	"/api/swagger",
+	"/api/metrics",
 }
 
-func GinLogrusMiddleware() gin.HandlerFunc {
+// GinLogrusMiddleware logs requests with secrets and PII in bodies and headers masked
+func GinLogrusMiddleware(cfg *config.Config) gin.HandlerFunc {
+	redactor := middleware.NewRedactor(cfg.Logger.RedactFields, cfg.Logger.MaskHeaders, cfg.Logger.MaxBodySize)
+
 	return func(c *gin.Context) {
 		start := time.Now()
 
-		var requestBody []byte
-		if c.Request.Body != nil {
-			bodyBytes, err := io.ReadAll(c.Request.Body)
-			if err == nil {
-				requestBody = bodyBytes
-				c.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes)) // recover request body
-			}
+		var requestBody string
+		if !cfg.Logger.MetadataOnly {
+			requestBody = redactor.RequestBody(c)
 		}
 
 		c.Next()
@@ -50,20 +60,32 @@ func GinLogrusMiddleware() gin.HandlerFunc {
 			}
 		}
 
-		logrus.WithFields(logrus.Fields{
+		fields := logrus.Fields{
 			"status":    status,
 			"method":    c.Request.Method,
 			"path":      path,
-			"query":     c.Request.URL.RawQuery,
-			"body":      string(requestBody),
 			"latency":   duration,
 			"client_ip": c.ClientIP(),
-		}).Info("incoming request")
+		}
+
+		if !cfg.Logger.MetadataOnly {
+			fields["query"] = redactor.Query(c.Request.URL.RawQuery)
+			fields["body"] = requestBody
+			fields["headers"] = redactor.Headers(c.Request.Header)
+		}
+
+		logger.FromContext(c).WithFields(fields).Info("incoming request")
 	}
 }
 
-func SetGinMiddlewares(router *gin.Engine) {
-	router.Use(GinLogrusMiddleware())
+func SetGinMiddlewares(router *gin.Engine, cfg *config.Config) {
+	// lets handlers pass *gin.Context as context.Context carrying the request logger
+	router.ContextWithFallback = true
+
+	router.Use(middleware.RequestIDMiddleware())
+	router.Use(middleware.QueryTimeoutMiddleware(cfg.Postgres.QueryTimeout))
+	router.Use(metrics.GinMiddleware())
+	router.Use(GinLogrusMiddleware(cfg))
 	router.Use(gin.Recovery()) // recovery middleware
 }
 
@@ -74,43 +96,128 @@ func SetGinMiddlewares(router *gin.Engine) {
 // @securityDefinitions.apikey XAuthToken
 // @in header
 // @name X-Auth-Token
-// @description Enter your authentication token
+// @description Enter your API key or the legacy AUTH_TOKEN
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Enter "Bearer " followed by the admin access token
 func SetRouterHandler(
 	router *gin.Engine,
 	cfg *config.Config,
 	userService *service.UserService,
 	channelService *service.ChannelService,
+	campaignService *service.CampaignService,
+	sourceService *service.SourceService,
 	notificationService *service.NotificationService,
+	linkService *service.LinkService,
+	dripService *service.DripService,
+	apiKeyService *service.APIKeyService,
+	adminService *service.AdminService,
+	auditService *service.AuditService,
+	healthService *service.HealthService,
 ) {
+	// Tracked links are opened by the recipients, outside of the API
+	redirectController := redirect.NewRedirectController(linkService)
+	router.GET("/r/:token", redirectController.RedirectHandler())
+
 	apiGroup := router.Group("/api")
 
-	apiGroup.GET("/health", func(ctx *gin.Context) {
-		ctx.JSON(http.StatusOK, gin.H{
-			"status":  http.StatusOK,
-			"message": "pong",
-		})
-	})
+	healthController := health.NewHealthController(healthService)
+	apiGroup.GET("/health", healthController.LivenessHandler()) // kept for existing probes
+	apiGroup.GET("/health/live", healthController.LivenessHandler())
+	apiGroup.GET("/health/ready", healthController.ReadinessHandler())
 
 	apiGroup.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	apiGroup.Use(middleware.AuthTokenMiddleware(cfg.AuthToken))
+	apiGroup.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	// Auth routes are public
+	authGroup := apiGroup.Group("/auth")
+	authController := auth.NewAuthController(adminService)
+	authGroup.POST("/login", authController.LoginHandler())
+	authGroup.POST("/telegram", authController.TelegramLoginHandler())
+	authGroup.POST("/refresh", authController.RefreshHandler())
+	authGroup.POST("/logout", authController.LogoutHandler())
+
+	apiGroup.Use(middleware.AdminAuthMiddleware(adminService))
+	apiGroup.Use(middleware.AuthTokenMiddleware(apiKeyService))
+
+	scope := middleware.RequireScope
+	// audited records the action, it precedes the scope check so that denied attempts are recorded too
+	audited := func(action string) gin.HandlerFunc {
+		return middleware.Audit(auditService, action)
+	}
 
 	// User routes
 	userGroup := apiGroup.Group("/users")
 	userController := user.NewUserController(userService)
-	userGroup.GET("/", userController.GetUsersHandler())
-	userGroup.GET("/export", userController.ExportUsersHandler())
+	userGroup.GET("/", scope(domain.ScopeUsersRead), userController.GetUsersHandler())
+	userGroup.GET("/export", audited(domain.AuditActionUserExport), scope(domain.ScopeUsersExport), userController.ExportUsersHandler())
+	userGroup.POST("/import", audited(domain.AuditActionUserImport), scope(domain.ScopeUsersWrite), userController.ImportUsersHandler())
+	userGroup.GET("/:telegram_id", scope(domain.ScopeUsersRead), userController.GetUserHandler())
+	userGroup.PATCH("/:telegram_id", audited(domain.AuditActionUserUpdate), scope(domain.ScopeUsersWrite), userController.UpdateUserHandler())
+	userGroup.DELETE("/:telegram_id", audited(domain.AuditActionUserDelete), scope(domain.ScopeUsersWrite), userController.DeleteUserHandler())
+	userGroup.POST("/:telegram_id/erase", audited(domain.AuditActionUserErase), scope(domain.ScopeUsersWrite), userController.EraseUserHandler())
 
 	// Channel routes
 	channelGroup := apiGroup.Group("/channels")
 	channelController := channel.NewChannelController(channelService)
-	channelGroup.POST("/generate", channelController.GenerateChannelHandler())
-	channelGroup.GET("/:code", channelController.GetChannelByCodeHandler())
-	channelGroup.POST("/bulk", channelController.GenerateBulkChannelHandler())
-	channelGroup.GET("/all", channelController.GetChannelsHandler())
+	channelGroup.POST("/generate", audited(domain.AuditActionChannelGenerate), scope(domain.ScopeChannelsWrite), channelController.GenerateChannelHandler())
+	channelGroup.GET("/:code", scope(domain.ScopeChannelsRead), channelController.GetChannelByCodeHandler())
+	channelGroup.PUT("/:code", audited(domain.AuditActionChannelUpdate), scope(domain.ScopeChannelsWrite), channelController.UpdateChannelHandler())
+	channelGroup.POST("/bulk", audited(domain.AuditActionChannelBulk), scope(domain.ScopeChannelsWrite), channelController.GenerateBulkChannelHandler())
+	channelGroup.GET("/all", scope(domain.ScopeChannelsRead), channelController.GetChannelsHandler())
+
+	// Campaign routes
+	campaignGroup := apiGroup.Group("/campaigns")
+	campaignController := campaign.NewCampaignController(campaignService)
+	campaignGroup.POST("/", audited(domain.AuditActionCampaignCreate), scope(domain.ScopeChannelsWrite), campaignController.CreateCampaignHandler())
+	campaignGroup.GET("/", scope(domain.ScopeChannelsRead), campaignController.GetCampaignsHandler())
+	campaignGroup.GET("/stats", scope(domain.ScopeChannelsRead), campaignController.GetCampaignStatsHandler())
+
+	// Source routes
+	sourceGroup := apiGroup.Group("/sources")
+	sourceController := source.NewSourceController(sourceService)
+	sourceGroup.POST("/", audited(domain.AuditActionSourceCreate), scope(domain.ScopeChannelsWrite), sourceController.CreateSourceHandler())
+	sourceGroup.GET("/", scope(domain.ScopeChannelsRead), sourceController.GetSourcesHandler())
+	sourceGroup.GET("/stats", scope(domain.ScopeChannelsRead), sourceController.GetSourceStatsHandler())
 
 	// Notification routes
 	notificationGroup := apiGroup.Group("/notifications")
 	notificationController := notification.NewNotificationController(notificationService)
-	notificationGroup.POST("/", notificationController.SendNotificationHandler())
+	notificationGroup.POST("/", audited(domain.AuditActionNotificationSend), scope(domain.ScopeNotificationsSend), notificationController.SendNotificationHandler())
+	notificationGroup.GET("/broadcasts", scope(domain.ScopeNotificationsSend), notificationController.GetBroadcastsHandler())
+	notificationGroup.GET("/broadcasts/:id", scope(domain.ScopeNotificationsSend), notificationController.GetBroadcastHandler())
+	notificationGroup.GET("/broadcasts/:id/clicks", scope(domain.ScopeNotificationsSend), notificationController.GetBroadcastClicksHandler())
+	notificationGroup.GET("/broadcasts/:id/variants", scope(domain.ScopeNotificationsSend), notificationController.GetBroadcastVariantsHandler())
+	notificationGroup.POST("/broadcasts/:id/pause", audited(domain.AuditActionNotificationPause), scope(domain.ScopeNotificationsSend), notificationController.PauseBroadcastHandler())
+	notificationGroup.POST("/broadcasts/:id/resume", audited(domain.AuditActionNotificationResume), scope(domain.ScopeNotificationsSend), notificationController.ResumeBroadcastHandler())
+	notificationGroup.POST("/broadcasts/:id/cancel", audited(domain.AuditActionNotificationCancel), scope(domain.ScopeNotificationsSend), notificationController.CancelBroadcastHandler())
+
+	// Drip sequence routes
+	dripGroup := apiGroup.Group("/drips")
+	dripController := drip.NewDripController(dripService)
+	dripGroup.POST("/", audited(domain.AuditActionDripCreate), scope(domain.ScopeNotificationsSend), dripController.CreateDripSequenceHandler())
+	dripGroup.GET("/", scope(domain.ScopeNotificationsSend), dripController.GetDripSequencesHandler())
+	dripGroup.GET("/:id", scope(domain.ScopeNotificationsSend), dripController.GetDripSequenceHandler())
+	dripGroup.PATCH("/:id", audited(domain.AuditActionDripUpdate), scope(domain.ScopeNotificationsSend), dripController.UpdateDripSequenceHandler())
+	dripGroup.DELETE("/:id", audited(domain.AuditActionDripDelete), scope(domain.ScopeNotificationsSend), dripController.DeleteDripSequenceHandler())
+
+	// API key routes
+	apiKeyGroup := apiGroup.Group("/keys")
+	apiKeyController := apikey.NewAPIKeyController(apiKeyService)
+	apiKeyGroup.POST("/", audited(domain.AuditActionAPIKeyCreate), scope(domain.ScopeAPIKeysManage), apiKeyController.CreateAPIKeyHandler())
+	apiKeyGroup.GET("/", scope(domain.ScopeAPIKeysManage), apiKeyController.GetAPIKeysHandler())
+	apiKeyGroup.DELETE("/:id", audited(domain.AuditActionAPIKeyRevoke), scope(domain.ScopeAPIKeysManage), apiKeyController.RevokeAPIKeyHandler())
+
+	// Admin routes
+	adminGroup := apiGroup.Group("/admins")
+	adminController := admin.NewAdminController(adminService)
+	adminGroup.POST("/", audited(domain.AuditActionAdminCreate), scope(domain.ScopeAdminsManage), adminController.CreateAdminHandler())
+	adminGroup.GET("/", scope(domain.ScopeAdminsManage), adminController.GetAdminsHandler())
+	adminGroup.DELETE("/:id", audited(domain.AuditActionAdminDisable), scope(domain.ScopeAdminsManage), adminController.DisableAdminHandler())
+
+	// Audit routes
+	auditController := audit.NewAuditController(auditService)
+	apiGroup.GET("/audit", scope(domain.ScopeAuditRead), auditController.GetAuditHandler())
 }
@@ -0,0 +1,45 @@
+package routing_test
+
+import (
+	"hr-server/internal/api/http/controllers/controllertest"
+	"hr-server/internal/api/http/routing"
+	"hr-server/internal/service/servicetest"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetRouterHandler_LegacyToken(t *testing.T) {
+	cfg := servicetest.Config()
+	s := servicetest.NewServices(cfg)
+
+	router := controllertest.NewRouter()
+	routing.SetRouterHandler(router, cfg, s.User, s.Channel, s.Campaign, s.Source, s.Notification, s.Link, s.Drip,
+		s.APIKey, s.Admin, s.Audit, s.Health)
+
+	// every route the shared token could call before API keys, it has to keep working until it's removed
+	routes := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/api/users/"},
+		{http.MethodGet, "/api/users/export"},
+		{http.MethodPost, "/api/channels/generate"},
+		{http.MethodGet, "/api/channels/abc123"},
+		{http.MethodPost, "/api/channels/bulk"},
+		{http.MethodGet, "/api/channels/all"},
+		{http.MethodPost, "/api/notifications/"},
+	}
+
+	for _, route := range routes {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			recorder := controllertest.Do(t, router, route.method, route.path, nil, "X-Auth-Token", servicetest.AuthToken)
+			assert.NotEqual(t, http.StatusUnauthorized, recorder.Code)
+			assert.NotEqual(t, http.StatusForbidden, recorder.Code)
+		})
+	}
+
+	recorder := controllertest.Do(t, router, http.MethodGet, "/api/keys/", nil, "X-Auth-Token", servicetest.AuthToken)
+	assert.Equal(t, http.StatusForbidden, recorder.Code, "the shared token gets no scopes it didn't need")
+}
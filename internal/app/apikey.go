@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"hr-server/config"
+	"hr-server/internal/domain"
+	"hr-server/internal/infrastructure"
+	"hr-server/internal/repository"
+	"hr-server/internal/service"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+)
+
+const apiKeyUsage = "usage: apikey create NAME SCOPE[,SCOPE...]"
+
+// APIKey runs the apikey subcommand with its arguments, it creates the first keys without the API
+func APIKey(cfg *config.Config, args []string) error {
+	if err := InitLogger(cfg); err != nil {
+		return fmt.Errorf("failed to init logger: %w", err)
+	}
+
+	if len(args) != 3 || args[0] != "create" {
+		return fmt.Errorf("unknown command '%v': %s", args, apiKeyUsage)
+	}
+
+	name := strings.TrimSpace(args[1])
+	if name == "" {
+		return fmt.Errorf("empty key name: %s", apiKeyUsage)
+	}
+
+	scopes := strings.Split(args[2], ",")
+	for _, scope := range scopes {
+		if !slices.Contains(domain.AllScopes, scope) {
+			return fmt.Errorf("unknown scope '%s', available: %s", scope, strings.Join(domain.AllScopes, ", "))
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := infrastructure.NewPostgresDB(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create postgres database: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get postgres connection pool: %w", err)
+	}
+	defer sqlDB.Close()
+
+	apiKeyService := service.NewAPIKeyService(cfg, repository.NewAPIKeyRepository(db))
+
+	// the operator running the command has the database access, so any scope can be granted
+	key, token, err := apiKeyService.CreateAPIKey(repository.WithQueryTimeout(ctx, cfg.Postgres.QueryTimeout), name, scopes, domain.AllScopes, nil)
+	if err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{"id": key.ID, "name": key.Name, "scopes": key.Scopes}).Info("API key created")
+
+	// the token is printed alone on stdout so that scripts can capture it
+	fmt.Fprintln(os.Stdout, token)
+
+	return nil
+}
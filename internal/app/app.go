@@ -7,6 +7,8 @@ import (
 	"hr-server/config"
 	"hr-server/internal/api/http/routing"
 	"hr-server/internal/infrastructure"
+	"hr-server/internal/metrics"
+	"hr-server/internal/migrations"
 	"hr-server/internal/repository"
 	"hr-server/internal/service"
 	"net/http"
@@ -24,6 +26,8 @@ func Run(cfg *config.Config) error {
 		return fmt.Errorf("failed to init logger: %w", err)
 	}
 
+	logrus.WithFields(cfg.Redacted()).Info("config loaded")
+
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
@@ -32,27 +36,80 @@ func Run(cfg *config.Config) error {
 		return fmt.Errorf("failed to create postgres database: %w", err)
 	}
 
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get postgres connection pool: %w", err)
+	}
+
+	if err := metrics.RegisterDB(sqlDB, cfg.Postgres.DB); err != nil {
+		return fmt.Errorf("failed to register postgres metrics: %w", err)
+	}
+
+	migrator, err := migrations.NewMigrator(sqlDB)
+	if err != nil {
+		return err
+	}
+
+	if err := ensureSchema(ctx, cfg, migrator); err != nil {
+		return err
+	}
+
 	userRepository := repository.NewUserRepository(db)
 	channelRepository := repository.NewChannelRepository(db)
-
-	userService := service.NewUserService(userRepository)
-	channelService := service.NewChannelService(cfg, channelRepository)
+	campaignRepository := repository.NewCampaignRepository(db)
+	sourceRepository := repository.NewSourceRepository(db)
+	apiKeyRepository := repository.NewAPIKeyRepository(db)
+	adminRepository := repository.NewAdminRepository(db)
+	auditRepository := repository.NewAuditRepository(db)
+	linkRepository := repository.NewLinkRepository(db)
+	dripRepository := repository.NewDripRepository(db)
+	broadcastRepository := repository.NewBroadcastRepository(db)
+
+	channelService := service.NewChannelService(cfg, channelRepository, campaignRepository, sourceRepository)
+	campaignService := service.NewCampaignService(campaignRepository)
+	sourceService := service.NewSourceService(sourceRepository)
+	apiKeyService := service.NewAPIKeyService(cfg, apiKeyRepository)
+	adminService := service.NewAdminService(cfg, adminRepository)
+	auditService := service.NewAuditService(auditRepository)
+	userService := service.NewUserService(userRepository, channelService)
+	linkService := service.NewLinkService(cfg, linkRepository)
 
 	var wg sync.WaitGroup
-	wg.Add(1)
+	wg.Add(3)
 
 	telegramService, err := service.NewTelegramService(cfg, userService, channelService)
 	if err != nil {
 		return fmt.Errorf("failed to create telegram bot: %w", err)
 	}
 
-	notificationService := service.NewNotificationService(userRepository, telegramService)
+	notificationService := service.NewNotificationService(cfg, userRepository, broadcastRepository, telegramService, linkService)
+	telegramService.SetCallbackHandler(notificationService)
+	dripService := service.NewDripService(cfg, dripRepository, userRepository, channelService, telegramService)
+	telegramService.SetDripEnroller(dripService)
+	healthService := service.NewHealthService(sqlDB, telegramService, notificationService)
 
-	go telegramService.Run(ctx, &wg)
+	// the bot handles updates with the same query timeout as the API requests
+	go telegramService.Run(repository.WithQueryTimeout(ctx, cfg.Postgres.QueryTimeout), &wg)
+	go notificationService.Run(ctx, &wg)
+	go dripService.Run(repository.WithQueryTimeout(ctx, cfg.Postgres.QueryTimeout), &wg)
 
 	router := gin.New()
-	routing.SetGinMiddlewares(router)
-	routing.SetRouterHandler(router, cfg, userService, channelService, notificationService)
+	routing.SetGinMiddlewares(router, cfg)
+	routing.SetRouterHandler(
+		router,
+		cfg,
+		userService,
+		channelService,
+		campaignService,
+		sourceService,
+		notificationService,
+		linkService,
+		dripService,
+		apiKeyService,
+		adminService,
+		auditService,
+		healthService,
+	)
 
 	server := &http.Server{
 		Addr:    ":" + cfg.Http.Port,
@@ -6,10 +6,16 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
 func FormatFilePath(path string) string {
 	arr := strings.Split(path, "/")
 	return arr[len(arr)-1]
@@ -23,19 +29,32 @@ func InitLogger(cfg *config.Config) error {
 		return fmt.Errorf("can't load \"LOGL\": %s", err)
 	}
 
-	txtFormatter := &logrus.TextFormatter{
-		TimestampFormat:        "02-01-2006 15:04:05",
-		FullTimestamp:          true,
-		DisableLevelTruncation: true,
-		ForceColors:            true,
-		CallerPrettyfier: func(f *runtime.Frame) (string, string) {
-			return "", fmt.Sprintf("%s:%d", FormatFilePath(f.File), f.Line)
-		},
+	callerPrettyfier := func(f *runtime.Frame) (string, string) {
+		return "", fmt.Sprintf("%s:%d", FormatFilePath(f.File), f.Line)
+	}
+
+	var formatter logrus.Formatter
+	switch cfg.Logger.Format {
+	case "", LogFormatText:
+		formatter = &logrus.TextFormatter{
+			TimestampFormat:        "02-01-2006 15:04:05",
+			FullTimestamp:          true,
+			DisableLevelTruncation: true,
+			ForceColors:            true,
+			CallerPrettyfier:       callerPrettyfier,
+		}
+	case LogFormatJSON:
+		formatter = &logrus.JSONFormatter{
+			TimestampFormat:  time.RFC3339Nano,
+			CallerPrettyfier: callerPrettyfier,
+		}
+	default:
+		return fmt.Errorf("can't load \"LOG_FORMAT\": unknown format '%s'", cfg.Logger.Format)
 	}
 
 	logrus.SetLevel(logrusLvl)
 	logrus.SetOutput(os.Stdout)
-	logrus.SetFormatter(txtFormatter)
+	logrus.SetFormatter(formatter)
 
 	return nil
 }
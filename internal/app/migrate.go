@@ -0,0 +1,92 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"hr-server/config"
+	"hr-server/internal/infrastructure"
+	"hr-server/internal/migrations"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+)
+
+const migrateUsage = "usage: migrate up | down | down-to VERSION | status | version"
+
+// Migrate runs the migrate subcommand with its arguments
+func Migrate(cfg *config.Config, args []string) error {
+	if err := InitLogger(cfg); err != nil {
+		return fmt.Errorf("failed to init logger: %w", err)
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf(migrateUsage)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := infrastructure.NewPostgresDB(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create postgres database: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get postgres connection pool: %w", err)
+	}
+	defer sqlDB.Close()
+
+	migrator, err := migrations.NewMigrator(sqlDB)
+	if err != nil {
+		return err
+	}
+
+	switch command := args[0]; {
+	case command == "up" && len(args) == 1:
+		return migrator.Up(ctx)
+	case command == "down" && len(args) == 1:
+		return migrator.Down(ctx)
+	case command == "down-to" && len(args) == 2:
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid version '%s': %s", args[1], migrateUsage)
+		}
+		return migrator.DownTo(ctx, version)
+	case command == "status" && len(args) == 1:
+		return migrator.Status(ctx)
+	case command == "version" && len(args) == 1:
+		current, latest, err := migrator.Versions(ctx)
+		if err != nil {
+			return err
+		}
+		logrus.WithFields(logrus.Fields{"current": current, "latest": latest}).Info("database schema version")
+		return nil
+	default:
+		return fmt.Errorf("unknown command '%v': %s", args, migrateUsage)
+	}
+}
+
+// ensureSchema applies pending migrations if MIGRATE_ON_START is set, otherwise fails if there are any
+func ensureSchema(ctx context.Context, cfg *config.Config, migrator *migrations.Migrator) error {
+	if cfg.Postgres.MigrateOnStart {
+		return migrator.Up(ctx)
+	}
+
+	pending, err := migrator.HasPending(ctx)
+	if err != nil {
+		return err
+	}
+
+	if pending {
+		current, latest, err := migrator.Versions(ctx)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("database schema is at version %d, latest is %d: run \"migrate up\" first", current, latest)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// Admin represents an HR staff account of the admin panel
+type Admin struct {
+	ID          int        `json:"id"`
+	Username    string     `json:"username"`
+	TelegramID  *int64     `json:"telegram_id"` // enables the Telegram Login Widget
+	Scopes      []string   `json:"scopes"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+	DisabledAt  *time.Time `json:"disabled_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	PasswordHash string `json:"-"`
+}
+
+// HasScope reports whether the admin is granted the scope
+func (a *Admin) HasScope(scope string) bool {
+	return slices.Contains(a.Scopes, scope)
+}
+
+// AdminRefreshToken represents a refresh token of an admin session, only the hash of the token is stored
+type AdminRefreshToken struct {
+	ID        int
+	AdminID   int
+	Hash      string
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+// AdminTokens represents the tokens issued on admin login
+type AdminTokens struct {
+	AccessToken      string    `json:"access_token"`
+	AccessExpiresAt  time.Time `json:"access_expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	TokenType        string    `json:"token_type"`
+}
+
+// TelegramLoginData represents the data sent by the Telegram Login Widget
+type TelegramLoginData struct {
+	ID        int64
+	FirstName string
+	LastName  string
+	Username  string
+	PhotoURL  string
+	AuthDate  int64
+	Hash      string
+}
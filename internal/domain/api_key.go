@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// API key scopes
+const (
+	ScopeUsersRead         = "users:read"
+	ScopeUsersWrite        = "users:write"
+	ScopeUsersExport       = "users:export"
+	ScopeChannelsRead      = "channels:read"
+	ScopeChannelsWrite     = "channels:write"
+	ScopeNotificationsSend = "notifications:send"
+	ScopeAPIKeysManage     = "api_keys:manage"
+	ScopeAdminsManage      = "admins:manage"
+	ScopeAuditRead         = "audit:read"
+)
+
+// AllScopes lists every API key scope
+var AllScopes = []string{
+	ScopeUsersRead,
+	ScopeUsersWrite,
+	ScopeUsersExport,
+	ScopeChannelsRead,
+	ScopeChannelsWrite,
+	ScopeNotificationsSend,
+	ScopeAPIKeysManage,
+	ScopeAdminsManage,
+	ScopeAuditRead,
+}
+
+// LegacyTokenScopes are the scopes of the deprecated AUTH_TOKEN, the ones the shared token was used for
+var LegacyTokenScopes = []string{
+	ScopeUsersRead,
+	ScopeUsersExport,
+	ScopeChannelsRead,
+	ScopeChannelsWrite,
+	ScopeNotificationsSend,
+}
+
+// APIKey represents an API key, only the hash of the secret is stored
+type APIKey struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // public part of the key used to find it
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	Hash string `json:"-"`
+}
+
+// HasScope reports whether the key grants the scope
+func (k *APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// IsActive reports whether the key can be used at the given time
+func (k *APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
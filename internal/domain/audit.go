@@ -0,0 +1,61 @@
+package domain
+
+import "time"
+
+const (
+	AuditActorAPIKey = "api_key"
+	AuditActorAdmin  = "admin"
+
+	AuditResultSuccess = "success"
+	AuditResultFailure = "failure"
+)
+
+// Audit actions of the administrative endpoints
+const (
+	AuditActionUserExport         = "user.export"
+	AuditActionUserImport         = "user.import"
+	AuditActionUserUpdate         = "user.update"
+	AuditActionUserDelete         = "user.delete"
+	AuditActionUserErase          = "user.erase"
+	AuditActionChannelGenerate    = "channel.generate"
+	AuditActionChannelBulk        = "channel.bulk_generate"
+	AuditActionChannelUpdate      = "channel.update"
+	AuditActionCampaignCreate     = "campaign.create"
+	AuditActionSourceCreate       = "source.create"
+	AuditActionNotificationSend   = "notification.send"
+	AuditActionNotificationPause  = "notification.pause"
+	AuditActionNotificationResume = "notification.resume"
+	AuditActionNotificationCancel = "notification.cancel"
+	AuditActionDripCreate         = "drip.create"
+	AuditActionDripUpdate         = "drip.update"
+	AuditActionDripDelete         = "drip.delete"
+	AuditActionAPIKeyCreate       = "api_key.create"
+	AuditActionAPIKeyRevoke       = "api_key.revoke"
+	AuditActionAdminCreate        = "admin.create"
+	AuditActionAdminDisable       = "admin.disable"
+)
+
+// AuditEntry represents a record of an administrative action
+type AuditEntry struct {
+	ID        int       `json:"id"`
+	ActorType string    `json:"actor_type"` // api_key or admin
+	ActorID   int       `json:"actor_id"`   // 0 for the legacy AUTH_TOKEN
+	ActorName string    `json:"actor_name"`
+	Action    string    `json:"action"`
+	Target    string    `json:"target"`  // path parameter of the affected entity, if any
+	Request   string    `json:"request"` // method, path, query and a summary of the body
+	Status    int       `json:"status"`
+	Result    string    `json:"result"`
+	ClientIP  string    `json:"client_ip"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AuditFilter represents conditions of the audit log query
+type AuditFilter struct {
+	ActorType string
+	ActorID   *int
+	Action    string
+	Target    string
+	Result    string
+	CreatedAt TimeRange
+}
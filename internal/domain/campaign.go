@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// Campaign represents a recruiting campaign that groups channels, e.g. "spring-2026"
+type Campaign struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
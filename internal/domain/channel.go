@@ -2,12 +2,67 @@ package domain
 
 import "time"
 
+// Channel link types supported by Telegram deep links
+const (
+	ChannelLinkTypeStart    = "start"
+	ChannelLinkTypeStartApp = "startapp"
+)
+
+// Channel statuses, computed from the expiry and usage cap
+const (
+	ChannelStatusActive  = "active"
+	ChannelStatusExpired = "expired"
+	ChannelStatusFull    = "full"
+)
+
 // Channel represents a Telegram channel with channel code
 type Channel struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	Code      string    `json:"code"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Link      string    `json:"link"`
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Code       string     `json:"code"`
+	LinkType   string     `json:"link_type"`
+	CampaignID *int       `json:"campaign_id"`
+	SourceID   *int       `json:"source_id"`
+	Tags       []string   `json:"tags"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	MaxUsers   *int       `json:"max_users"`
+	Timezone   string     `json:"timezone"` // IANA name of the region of the audience, the default timezone of its users
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	Link       string     `json:"link"`
+
+	Status            string `json:"status"`
+	UsersCount        int64  `json:"users_count"`
+	RemainingCapacity *int64 `json:"remaining_capacity"` // nil if the channel has no usage cap
+}
+
+// IsExpired reports whether the channel code can no longer be used at the given time
+func (c *Channel) IsExpired(now time.Time) bool {
+	return c.ExpiresAt != nil && !now.Before(*c.ExpiresAt)
+}
+
+// IsFull reports whether the channel reached its usage cap
+func (c *Channel) IsFull() bool {
+	return c.MaxUsers != nil && c.UsersCount >= int64(*c.MaxUsers)
+}
+
+// ChannelAttributes represents the optional attributes of a channel set on creation or update
+type ChannelAttributes struct {
+	LinkType   string
+	CampaignID *int
+	SourceID   *int
+	Tags       []string
+	ExpiresAt  *time.Time
+	MaxUsers   *int
+	Timezone   string
+}
+
+// ChannelFilter represents the filters of the channel list, empty fields are ignored
+type ChannelFilter struct {
+	CampaignID *int
+	SourceID   *int
+	Tags       []string // channel must have all of the tags
+	CreatedAt  TimeRange
+	Name       string // case-insensitive substring match
+	Status     string
 }
@@ -0,0 +1,75 @@
+package domain
+
+import "time"
+
+// Conditions of drip steps, evaluated when the step is due
+const (
+	DripConditionAlways  = "always"
+	DripConditionNoReply = "no_reply" // skipped if the user replied to the bot since the enrollment
+	DripConditionReplied = "replied"  // sent only if the user replied to the bot since the enrollment
+)
+
+// Statuses of drip enrollments
+const (
+	DripEnrollmentActive    = "active"
+	DripEnrollmentCompleted = "completed"
+	DripEnrollmentCancelled = "cancelled" // the user blocked the bot or was erased
+)
+
+// Outcomes of drip steps
+const (
+	DripStepSent    = "sent"
+	DripStepSkipped = "skipped"
+	DripStepFailed  = "failed"
+)
+
+// MaxDripSteps limits the messages of a drip sequence
+const MaxDripSteps = 20
+
+// DripSequence represents automated messages sent to the users after their first /start.
+// The steps can't be changed once the sequence is created, since the enrollments refer to them by position.
+type DripSequence struct {
+	ID          int                  `json:"id"`
+	Name        string               `json:"name"`
+	ChannelID   *int                 `json:"channel_id"` // only the users who came through the channel are enrolled, all if nil
+	Active      bool                 `json:"active"`     // inactive sequences neither enroll users nor send messages
+	Steps       []DripStep           `json:"steps"`
+	Enrollments DripEnrollmentCounts `json:"enrollments"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
+
+// DripStep represents a message of a drip sequence
+type DripStep struct {
+	Position     int    `json:"position"`      // from 0 in the order of sending
+	DelayMinutes int    `json:"delay_minutes"` // since the enrollment, not since the previous step
+	Message      string `json:"message"`       // a template like the broadcast messages
+	Condition    string `json:"condition"`
+	Sent         int64  `json:"sent"`
+	Skipped      int64  `json:"skipped"`
+	Failed       int64  `json:"failed"`
+}
+
+// Delay returns the time between the enrollment and the step
+func (s DripStep) Delay() time.Duration {
+	return time.Duration(s.DelayMinutes) * time.Minute
+}
+
+// DripEnrollmentCounts represents the enrollments of a drip sequence by status
+type DripEnrollmentCounts struct {
+	Active    int64 `json:"active"`
+	Completed int64 `json:"completed"`
+	Cancelled int64 `json:"cancelled"`
+}
+
+// DripEnrollment represents the progress of a user through a drip sequence
+type DripEnrollment struct {
+	ID         int        `json:"id"`
+	SequenceID int        `json:"sequence_id"`
+	TelegramID int64      `json:"telegram_id"`
+	Status     string     `json:"status"`
+	NextStep   int        `json:"next_step"`
+	NextRunAt  *time.Time `json:"next_run_at"` // nil once the enrollment is completed or cancelled
+	RepliedAt  *time.Time `json:"replied_at"`  // last message or button press of the user since the enrollment
+	EnrolledAt time.Time  `json:"enrolled_at"`
+}
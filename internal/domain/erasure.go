@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Erasure modes of the right-to-erasure request
+const (
+	ErasureModeDelete    = "delete"    // the user and all related records are deleted
+	ErasureModeAnonymize = "anonymize" // identifying data and the channel are removed, the user is still counted in the totals
+)
+
+// ErasedName replaces the username and the first name of an erased user in the audit log
+const ErasedName = "[erased]"
+
+// ErasedUserReference replaces the Telegram ID of an erased user in the audit log, e.g. "user:5"
+func ErasedUserReference(userID int) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+// UserErasure represents the audit entry of an erased user, it holds no personal data
+type UserErasure struct {
+	ID          int       `json:"id"`
+	UserID      int       `json:"user_id"`
+	Mode        string    `json:"mode"`
+	Reason      string    `json:"reason"`
+	RequestedBy string    `json:"requested_by"` // admin or API key, e.g. "admin:3 hr-manager"
+	CreatedAt   time.Time `json:"created_at"`
+}
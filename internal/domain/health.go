@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+const (
+	HealthStatusOK          = "ok"
+	HealthStatusUnavailable = "unavailable"
+)
+
+// ComponentHealth represents the readiness of a dependency or a subsystem
+type ComponentHealth struct {
+	Status  string                 `json:"status"`
+	Error   string                 `json:"error,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// HealthReport represents the readiness of the service, it's ready only if all components are
+type HealthReport struct {
+	Status     string                      `json:"status"`
+	Components map[string]*ComponentHealth `json:"components"`
+	CheckedAt  time.Time                   `json:"checked_at"`
+}
+
+// BotState represents the state of the Telegram updates loop
+type BotState struct {
+	Running      bool
+	StartedAt    *time.Time
+	LastUpdateAt *time.Time
+}
+
+// BroadcastState represents the state of the notification workers
+type BroadcastState struct {
+	Running           bool // the workers are started and the app isn't shutting down
+	RunningBroadcasts int64
+	ActiveWorkers     int64
+	QueuedJobs        int64
+}
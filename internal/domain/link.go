@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// TrackedLink represents a link of a broadcast message replaced with a tracked redirect
+type TrackedLink struct {
+	ID          int       `json:"id"`
+	BroadcastID string    `json:"broadcast_id"`
+	Variant     string    `json:"variant"`
+	URL         string    `json:"url"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// LinkClick represents a click of a recipient on a tracked link
+type LinkClick struct {
+	ID         int       `json:"id"`
+	LinkID     int       `json:"link_id"`
+	TelegramID int64     `json:"telegram_id"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// LinkStats represents the clicks of a tracked link
+type LinkStats struct {
+	LinkID      int    `json:"link_id"`
+	URL         string `json:"url"`
+	Variant     string `json:"variant"`
+	Clicks      int64  `json:"clicks"`
+	UniqueUsers int64  `json:"unique_users"`
+}
+
+// BroadcastClickStats represents the clicks on the tracked links of a broadcast
+type BroadcastClickStats struct {
+	BroadcastID string       `json:"broadcast_id"`
+	Clicks      int64        `json:"clicks"`
+	UniqueUsers int64        `json:"unique_users"`
+	Links       []*LinkStats `json:"links"`
+}
@@ -1,7 +1,99 @@
 package domain
 
+import "time"
+
+// Priorities of notifications, transactional jobs are picked by the workers before the marketing ones
+const (
+	NotificationPriorityTransactional = "transactional"
+	NotificationPriorityMarketing     = "marketing"
+)
+
+// Delivery modes of notifications
+const (
+	NotificationDeliveryImmediate   = "immediate"
+	NotificationDeliveryLocalWindow = "local_window" // held until the quiet hours of the recipient end
+)
+
+// Statuses of broadcasts
+const (
+	BroadcastStatusRunning   = "running"
+	BroadcastStatusPaused    = "paused"
+	BroadcastStatusFinished  = "finished"
+	BroadcastStatusCancelled = "cancelled"
+	BroadcastStatusStopped   = "stopped" // interrupted by the shutdown
+)
+
+// MaxNotificationVariants limits the message versions of an A/B test
+const MaxNotificationVariants = 3
+
 // NotificationData represents the data to send a notification
 type NotificationData struct {
-	Message  string  `json:"message"`
-	ImageURL *string `json:"image_url,omitempty"`
+	Message    string  `json:"message"`
+	ImageURL   *string `json:"image_url,omitempty"`
+	ButtonText string  `json:"button_text,omitempty"` // inline button counting the responses, none if empty
+	ButtonURL  string  `json:"button_url,omitempty"`  // makes the button open the link instead of counting the responses
+
+	// Variants replace the message in A/B tests, recipients are split between them by weights
+	Variants []NotificationVariant `json:"variants,omitempty"`
+
+	Priority       string `json:"priority"`        // marketing by default
+	MaxConcurrency int    `json:"max_concurrency"` // messages of the broadcast sent at once, 0 uses all workers
+	Delivery       string `json:"delivery"`        // immediate by default
+
+	// TrackLinks replaces the links of the messages and the button with tracked redirects
+	TrackLinks bool `json:"track_links"`
+}
+
+// NotificationVariant represents a version of the message in an A/B test
+type NotificationVariant struct {
+	Message    string `json:"message"`
+	Weight     int    `json:"weight"`
+	ButtonText string `json:"button_text,omitempty"`
+	ButtonURL  string `json:"button_url,omitempty"`
+}
+
+// Broadcast represents a notification sent to all users
+type Broadcast struct {
+	ID             string             `json:"id"`
+	Priority       string             `json:"priority"`
+	MaxConcurrency int                `json:"max_concurrency"`
+	Delivery       string             `json:"delivery"`
+	Status         string             `json:"status"`
+	Sent           int64              `json:"sent"`
+	Failed         int64              `json:"failed"`
+	Held           int64              `json:"held"`     // recipients waiting for the end of their quiet hours
+	Variants       []BroadcastVariant `json:"variants"` // a single variant A without an A/B test
+	StartedAt      time.Time          `json:"started_at"`
+	FinishedAt     *time.Time         `json:"finished_at"`
+}
+
+// BroadcastVariant represents the delivery and response counts of a message variant
+type BroadcastVariant struct {
+	Name         string  `json:"name"` // A, B or C in the order of the request
+	Weight       int     `json:"weight"`
+	Sent         int64   `json:"sent"`
+	Failed       int64   `json:"failed"`
+	Responses    int64   `json:"responses"`     // recipients who pressed the button
+	ResponseRate float64 `json:"response_rate"` // responses per sent message
+}
+
+// BroadcastResponse represents the press of the broadcast button by a recipient, counted once per recipient
+type BroadcastResponse struct {
+	BroadcastID string    `json:"broadcast_id"`
+	Variant     string    `json:"variant"`
+	TelegramID  int64     `json:"telegram_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// IsActive reports whether the broadcast is running or paused
+func (b *Broadcast) IsActive() bool {
+	return b.Status == BroadcastStatusRunning || b.Status == BroadcastStatusPaused
+}
+
+// NotificationRecipient is the data of a recipient available to the message placeholders, e.g. {{.FirstName}}
+type NotificationRecipient struct {
+	TelegramID  int64
+	Username    string
+	FirstName   string
+	ChannelName string
 }
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Sort orders of list endpoints
+const (
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+
+// PageRequest represents a cursor-based page request of a list endpoint
+type PageRequest struct {
+	Cursor    string // opaque cursor returned with the previous page, empty for the first page
+	Limit     int
+	SortBy    string
+	SortOrder string
+}
+
+// Page represents a page of items with the total count of items matching the filters
+type Page[T any] struct {
+	Items      []T
+	TotalCount int64
+	NextCursor string // empty if there are no more items
+}
+
+// TimeRange represents an optional half-open [From, To) time range
+type TimeRange struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// ErrInvalidCursor is returned when a page cursor is malformed or doesn't match the requested sorting
+var ErrInvalidCursor = errors.New("invalid cursor")
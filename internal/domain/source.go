@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// Source represents a traffic source that groups channels, e.g. "hh.ru"
+type Source struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
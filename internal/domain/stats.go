@@ -0,0 +1,9 @@
+package domain
+
+// GroupStats represents channel and user counts rolled up by a campaign or a source
+type GroupStats struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	ChannelsCount int64  `json:"channels_count"`
+	UsersCount    int64  `json:"users_count"`
+}
@@ -2,23 +2,59 @@ package domain
 
 import "time"
 
+// User statuses
+const (
+	UserStatusActive  = "active"
+	UserStatusBlocked = "blocked" // the user blocked the bot, messages can't be delivered
+	UserStatusErased  = "erased"  // the user was anonymized on request, messages are never sent
+)
+
 // User represents a Telegram user
 type User struct {
-	ID         int       `json:"id"`
-	TelegramID int64     `json:"telegram_id"`
-	Username   string    `json:"username"`
-	ChannelID  *int      `json:"channel_id"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID         int        `json:"id"`
+	TelegramID int64      `json:"telegram_id"`
+	Username   string     `json:"username"`
+	FirstName  string     `json:"first_name"`
+	ChannelID  *int       `json:"channel_id"`
+	Status     string     `json:"status"`
+	Timezone   string     `json:"timezone"`     // IANA name set explicitly, empty to use the timezone of the channel
+	LastSeenAt *time.Time `json:"last_seen_at"` // last /start in the bot, nil for imported users who never started it
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
 }
 
 // UserWithChannel represents a Telegram user with channel information
 type UserWithChannel struct {
-	ID          int       `json:"id"`
-	TelegramID  int64     `json:"telegram_id"`
-	Username    string    `json:"username"`
-	ChannelID   *int      `json:"channel_id"`
-	ChannelName *string   `json:"channel_name"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          int        `json:"id"`
+	TelegramID  int64      `json:"telegram_id"`
+	Username    string     `json:"username"`
+	FirstName   string     `json:"first_name"`
+	ChannelID   *int       `json:"channel_id"`
+	ChannelName *string    `json:"channel_name"`
+	ChannelCode *string    `json:"channel_code"`
+	ChannelLink *string    `json:"channel_link"`
+	Status      string     `json:"status"`
+	Timezone    string     `json:"timezone"`
+	LastSeenAt  *time.Time `json:"last_seen_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	ChannelLinkType *string `json:"-"`
+	ChannelTimezone string  `json:"-"` // empty without a channel or if the channel has no timezone
+}
+
+// UserFilter represents the filters of the user list, empty fields are ignored
+type UserFilter struct {
+	ChannelID *int
+	CreatedAt TimeRange
+	Username  string // case-insensitive substring match
+	Status    string
+}
+
+// UserUpdate represents the changes of the user made by an admin, nil fields are left unchanged
+type UserUpdate struct {
+	ChannelID    *int
+	ClearChannel bool // removes the channel attribution, ChannelID must be nil
+	Status       *string
+	Timezone     *string // empty clears the explicit timezone
 }
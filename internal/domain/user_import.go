@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// Import row actions
+const (
+	ImportActionCreated = "created"
+	ImportActionUpdated = "updated"
+	ImportActionFailed  = "failed"
+)
+
+// UserImportRow represents a parsed row of the users import file
+type UserImportRow struct {
+	Row         int // 1-based line number in the file, the header is line 1
+	TelegramID  int64
+	Username    string
+	ChannelID   *int
+	ChannelRef  string // channel name or code, resolved when ChannelID is empty
+	CreatedAt   *time.Time
+	ParseErrors []string
+}
+
+// UserImportRowResult represents the outcome of a single import row
+type UserImportRowResult struct {
+	Row        int    `json:"row"`
+	TelegramID int64  `json:"telegram_id,omitempty"`
+	Action     string `json:"action"`
+	ChannelID  *int   `json:"channel_id,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// UserImportReport represents the outcome of the users import
+type UserImportReport struct {
+	DryRun  bool                   `json:"dry_run"`
+	Total   int                    `json:"total"`
+	Created int                    `json:"created"`
+	Updated int                    `json:"updated"`
+	Failed  int                    `json:"failed"`
+	Rows    []*UserImportRowResult `json:"rows"`
+}
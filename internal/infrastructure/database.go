@@ -3,8 +3,6 @@ package infrastructure
 import (
 	"fmt"
 	"hr-server/config"
-	"log"
-	"os"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -24,15 +22,7 @@ func NewPostgresDB(cfg *config.Config) (*gorm.DB, error) {
 		cfg.Postgres.SSLMODE,
 	)
 
-	newLogger := logger.New(
-		log.New(os.Stdout, "\r\n", log.LstdFlags), // io writer
-		logger.Config{
-			SlowThreshold:             time.Second,  // Slow SQL threshold
-			LogLevel:                  logger.Error, // Log level
-			IgnoreRecordNotFoundError: true,         // Ignore ErrRecordNotFound error for logger
-			Colorful:                  false,        // Disable color
-		},
-	)
+	newLogger := newGormLogger(logger.Error, time.Second)
 
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
 		Logger: newLogger,
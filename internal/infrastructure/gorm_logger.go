@@ -0,0 +1,82 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hr-server/internal/logger"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+)
+
+// gormLogger writes gorm logs through logrus with the fields of the query context, e.g. the request ID
+type gormLogger struct {
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+}
+
+func newGormLogger(level gormlogger.LogLevel, slowThreshold time.Duration) gormlogger.Interface {
+	return &gormLogger{
+		level:         level,
+		slowThreshold: slowThreshold,
+	}
+}
+
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	newLogger := *l
+	newLogger.level = level
+	return &newLogger
+}
+
+func (l *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		logger.FromContext(ctx).Infof(msg, args...)
+	}
+}
+
+func (l *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		logger.FromContext(ctx).Warnf(msg, args...)
+	}
+}
+
+func (l *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		logger.FromContext(ctx).Errorf(msg, args...)
+	}
+}
+
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	slow := l.slowThreshold != 0 && elapsed > l.slowThreshold
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound)
+
+	if !failed && !slow && l.level < gormlogger.Info {
+		return
+	}
+
+	sql, rows := fc()
+	entry := logger.FromContext(ctx).WithFields(logrus.Fields{
+		"sql":     sql,
+		"rows":    rows,
+		"latency": elapsed,
+		"source":  utils.FileWithLineNum(),
+	})
+
+	switch {
+	case failed && l.level >= gormlogger.Error:
+		entry.Error("sql error: ", err)
+	case slow && l.level >= gormlogger.Warn:
+		entry.Warn(fmt.Sprintf("slow sql >= %v", l.slowThreshold))
+	case l.level >= gormlogger.Info:
+		entry.Debug("sql")
+	}
+}
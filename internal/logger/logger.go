@@ -0,0 +1,38 @@
+// Package logger carries a logrus entry with request-scoped fields through context.Context,
+// so that a request or a broadcast can be traced across handlers, services and repositories.
+package logger
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	RequestIDField   = "request_id"
+	BroadcastIDField = "broadcast_id"
+)
+
+type entryKey struct{}
+
+// With returns a copy of ctx whose logger has the fields added
+func With(ctx context.Context, fields logrus.Fields) context.Context {
+	return context.WithValue(ctx, entryKey{}, FromContext(ctx).WithFields(fields))
+}
+
+// FromContext returns the logger of ctx or the standard logger if ctx has none
+func FromContext(ctx context.Context) *logrus.Entry {
+	if ctx != nil {
+		if entry, ok := ctx.Value(entryKey{}).(*logrus.Entry); ok {
+			return entry
+		}
+	}
+
+	return logrus.NewEntry(logrus.StandardLogger())
+}
+
+// RequestID returns the request ID of ctx or an empty string
+func RequestID(ctx context.Context) string {
+	requestID, _ := FromContext(ctx).Data[RequestIDField].(string)
+	return requestID
+}
@@ -0,0 +1,149 @@
+// Package metrics defines the Prometheus metrics of the service, exposed at /api/metrics
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "hr_server"
+
+const (
+	SendResultSuccess = "success"
+	SendResultFailure = "failure"
+
+	BotUpdateCommand  = "command"
+	BotUpdateMessage  = "message"
+	BotUpdateCallback = "callback"
+	BotUpdateOther    = "other"
+
+	unmatchedRoute   = "unmatched"
+	networkErrorCode = "network"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route, method and status.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	telegramSends = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "telegram_sends_total",
+		Help:      "Telegram messages sent by result and Telegram error code.",
+	}, []string{"result", "code"})
+
+	notificationQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "notification_queue_depth",
+		Help:      "Notification jobs queued and not yet picked up by a worker.",
+	})
+
+	notificationActiveWorkers = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "notification_active_workers",
+		Help:      "Running notification workers.",
+	})
+
+	botUpdates = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bot_updates_total",
+		Help:      "Telegram bot updates processed by type.",
+	}, []string{"type"})
+
+	dripSteps = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "drip_steps_total",
+		Help:      "Drip sequence steps by outcome: sent, skipped by the condition or failed.",
+	}, []string{"outcome"})
+
+	signups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "signups_total",
+		Help:      "Users registered through /start, by whether they are attributed to a channel.",
+	}, []string{"attributed"})
+)
+
+// GinMiddleware counts requests and observes their latency per route template
+func GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		httpRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		httpRequestDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// RegisterDB exposes the connection pool statistics of the database
+func RegisterDB(db *sql.DB, name string) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// ObserveTelegramSend counts a sent message, failures are labeled by the Telegram error code
+func ObserveTelegramSend(err error) {
+	if err == nil {
+		telegramSends.WithLabelValues(SendResultSuccess, "").Inc()
+		return
+	}
+
+	code := networkErrorCode
+	var tgErr *tgbotapi.Error
+	if errors.As(err, &tgErr) {
+		code = strconv.Itoa(tgErr.Code)
+	}
+
+	telegramSends.WithLabelValues(SendResultFailure, code).Inc()
+}
+
+// NotificationQueued is called when a job is queued, NotificationDequeued when a worker picks it up
+func NotificationQueued() {
+	notificationQueueDepth.Inc()
+}
+
+func NotificationDequeued() {
+	notificationQueueDepth.Dec()
+}
+
+func WorkerStarted() {
+	notificationActiveWorkers.Inc()
+}
+
+func WorkerStopped() {
+	notificationActiveWorkers.Dec()
+}
+
+func BotUpdateProcessed(updateType string) {
+	botUpdates.WithLabelValues(updateType).Inc()
+}
+
+func DripStepProcessed(outcome string) {
+	dripSteps.WithLabelValues(outcome).Inc()
+}
+
+// UserSignedUp counts a new user, the counts per channel are in the database since the channels are unbounded
+func UserSignedUp(attributed bool) {
+	signups.WithLabelValues(strconv.FormatBool(attributed)).Inc()
+}
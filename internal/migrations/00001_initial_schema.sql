@@ -0,0 +1,143 @@
+-- Baseline schema as previously created by gorm AutoMigrate.
+-- IF NOT EXISTS lets databases created before versioned migrations adopt this version. Their channels and users
+-- tables may predate the attribution and status columns, so those are added before the indexes on them.
+
+-- +goose Up
+CREATE TABLE IF NOT EXISTS campaigns (
+    id         BIGSERIAL PRIMARY KEY,
+    name       VARCHAR(255),
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_campaigns_name ON campaigns (name);
+
+CREATE TABLE IF NOT EXISTS sources (
+    id         BIGSERIAL PRIMARY KEY,
+    name       VARCHAR(255),
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sources_name ON sources (name);
+
+CREATE TABLE IF NOT EXISTS channels (
+    id          BIGSERIAL PRIMARY KEY,
+    name        VARCHAR(255),
+    code        VARCHAR(50),
+    link_type   VARCHAR(20) NOT NULL DEFAULT 'start',
+    campaign_id BIGINT,
+    source_id   BIGINT,
+    expires_at  TIMESTAMPTZ,
+    max_users   BIGINT,
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ
+);
+ALTER TABLE channels
+    ADD COLUMN IF NOT EXISTS link_type   VARCHAR(20) NOT NULL DEFAULT 'start',
+    ADD COLUMN IF NOT EXISTS campaign_id BIGINT,
+    ADD COLUMN IF NOT EXISTS source_id   BIGINT,
+    ADD COLUMN IF NOT EXISTS expires_at  TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS max_users   BIGINT;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_channels_code ON channels (code);
+CREATE INDEX IF NOT EXISTS idx_channels_campaign_id ON channels (campaign_id);
+CREATE INDEX IF NOT EXISTS idx_channels_source_id ON channels (source_id);
+
+CREATE TABLE IF NOT EXISTS channel_tags (
+    channel_id BIGINT,
+    tag        VARCHAR(100),
+    PRIMARY KEY (channel_id, tag)
+);
+CREATE INDEX IF NOT EXISTS idx_channel_tags_tag ON channel_tags (tag);
+
+CREATE TABLE IF NOT EXISTS users (
+    id          BIGSERIAL PRIMARY KEY,
+    telegram_id BIGINT,
+    username    VARCHAR(255),
+    channel_id  BIGINT,
+    status      VARCHAR(20) NOT NULL DEFAULT 'active',
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ
+);
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_telegram_id ON users (telegram_id);
+CREATE INDEX IF NOT EXISTS idx_users_channel_id ON users (channel_id);
+CREATE INDEX IF NOT EXISTS idx_users_status ON users (status);
+
+CREATE TABLE IF NOT EXISTS user_erasures (
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      BIGINT,
+    mode         VARCHAR(20),
+    reason       VARCHAR(1000),
+    requested_by VARCHAR(255),
+    created_at   TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_user_erasures_user_id ON user_erasures (user_id);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           BIGSERIAL PRIMARY KEY,
+    name         VARCHAR(255),
+    prefix       VARCHAR(32),
+    hash         VARCHAR(64),
+    scopes       VARCHAR(1000),
+    expires_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ,
+    updated_at   TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_prefix ON api_keys (prefix);
+
+CREATE TABLE IF NOT EXISTS admins (
+    id            BIGSERIAL PRIMARY KEY,
+    username      VARCHAR(255),
+    telegram_id   BIGINT,
+    password_hash VARCHAR(255),
+    scopes        VARCHAR(1000),
+    last_login_at TIMESTAMPTZ,
+    disabled_at   TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ,
+    updated_at    TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_admins_username ON admins (username);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_admins_telegram_id ON admins (telegram_id);
+
+CREATE TABLE IF NOT EXISTS admin_refresh_tokens (
+    id         BIGSERIAL PRIMARY KEY,
+    admin_id   BIGINT,
+    hash       VARCHAR(64),
+    expires_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_admin_refresh_tokens_admin_id ON admin_refresh_tokens (admin_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_admin_refresh_tokens_hash ON admin_refresh_tokens (hash);
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id         BIGSERIAL PRIMARY KEY,
+    actor_type VARCHAR(20),
+    actor_id   BIGINT,
+    actor_name VARCHAR(255),
+    action     VARCHAR(50),
+    target     VARCHAR(255),
+    request    TEXT,
+    status     BIGINT,
+    result     VARCHAR(20),
+    client_ip  VARCHAR(64),
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor_type, actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_action ON audit_log (action);
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log (target);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log (created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_log;
+DROP TABLE IF EXISTS admin_refresh_tokens;
+DROP TABLE IF EXISTS admins;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS user_erasures;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS channel_tags;
+DROP TABLE IF EXISTS channels;
+DROP TABLE IF EXISTS sources;
+DROP TABLE IF EXISTS campaigns;
@@ -0,0 +1,48 @@
+-- Foreign keys and NOT NULL constraints AutoMigrate never created, and indexes for list sorting.
+-- Rows referencing missing entities are detached first, so that the constraints can be added.
+
+-- +goose Up
+UPDATE users SET channel_id = NULL WHERE channel_id IS NOT NULL AND channel_id NOT IN (SELECT id FROM channels);
+UPDATE channels SET campaign_id = NULL WHERE campaign_id IS NOT NULL AND campaign_id NOT IN (SELECT id FROM campaigns);
+UPDATE channels SET source_id = NULL WHERE source_id IS NOT NULL AND source_id NOT IN (SELECT id FROM sources);
+DELETE FROM channel_tags WHERE channel_id NOT IN (SELECT id FROM channels);
+DELETE FROM admin_refresh_tokens WHERE admin_id IS NULL OR admin_id NOT IN (SELECT id FROM admins);
+
+ALTER TABLE users
+    ADD CONSTRAINT fk_users_channel FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE SET NULL,
+    ALTER COLUMN telegram_id SET NOT NULL;
+
+ALTER TABLE channels
+    ADD CONSTRAINT fk_channels_campaign FOREIGN KEY (campaign_id) REFERENCES campaigns (id) ON DELETE SET NULL,
+    ADD CONSTRAINT fk_channels_source FOREIGN KEY (source_id) REFERENCES sources (id) ON DELETE SET NULL,
+    ALTER COLUMN code SET NOT NULL;
+
+ALTER TABLE channel_tags
+    ADD CONSTRAINT fk_channel_tags_channel FOREIGN KEY (channel_id) REFERENCES channels (id) ON DELETE CASCADE;
+
+ALTER TABLE admin_refresh_tokens
+    ADD CONSTRAINT fk_admin_refresh_tokens_admin FOREIGN KEY (admin_id) REFERENCES admins (id) ON DELETE CASCADE,
+    ALTER COLUMN admin_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_channels_created_at ON channels (created_at, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_channels_created_at;
+DROP INDEX IF EXISTS idx_users_created_at;
+
+ALTER TABLE admin_refresh_tokens
+    DROP CONSTRAINT IF EXISTS fk_admin_refresh_tokens_admin,
+    ALTER COLUMN admin_id DROP NOT NULL;
+
+ALTER TABLE channel_tags
+    DROP CONSTRAINT IF EXISTS fk_channel_tags_channel;
+
+ALTER TABLE channels
+    DROP CONSTRAINT IF EXISTS fk_channels_campaign,
+    DROP CONSTRAINT IF EXISTS fk_channels_source,
+    ALTER COLUMN code DROP NOT NULL;
+
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS fk_users_channel,
+    ALTER COLUMN telegram_id DROP NOT NULL;
@@ -0,0 +1,8 @@
+-- Time of the last /start of the user, refreshed by the bot on every visit.
+
+-- +goose Up
+ALTER TABLE users ADD COLUMN last_seen_at TIMESTAMPTZ;
+UPDATE users SET last_seen_at = updated_at;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS last_seen_at;
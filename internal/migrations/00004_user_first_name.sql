@@ -0,0 +1,7 @@
+-- First name of the user from Telegram, used to personalize broadcasts.
+
+-- +goose Up
+ALTER TABLE users ADD COLUMN first_name VARCHAR(255) NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS first_name;
@@ -0,0 +1,27 @@
+-- Tracked redirect links of broadcasts and the clicks on them.
+
+-- +goose Up
+CREATE TABLE tracked_links (
+    id BIGSERIAL PRIMARY KEY,
+    broadcast_id VARCHAR(32) NOT NULL,
+    variant VARCHAR(1) NOT NULL,
+    url TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_tracked_links_broadcast_id ON tracked_links (broadcast_id);
+
+CREATE TABLE link_clicks (
+    id BIGSERIAL PRIMARY KEY,
+    link_id BIGINT NOT NULL REFERENCES tracked_links (id) ON DELETE CASCADE,
+    telegram_id BIGINT NOT NULL,
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_link_clicks_link_id ON link_clicks (link_id);
+CREATE INDEX idx_link_clicks_telegram_id ON link_clicks (telegram_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS link_clicks;
+DROP TABLE IF EXISTS tracked_links;
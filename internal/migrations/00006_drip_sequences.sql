@@ -0,0 +1,43 @@
+-- Drip sequences of messages sent after the first /start and the progress of the users through them.
+
+-- +goose Up
+CREATE TABLE drip_sequences (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    channel_id BIGINT REFERENCES channels (id) ON DELETE CASCADE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE drip_steps (
+    sequence_id BIGINT NOT NULL REFERENCES drip_sequences (id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    delay_minutes INTEGER NOT NULL,
+    message TEXT NOT NULL,
+    condition VARCHAR(20) NOT NULL DEFAULT 'always',
+    sent BIGINT NOT NULL DEFAULT 0,
+    skipped BIGINT NOT NULL DEFAULT 0,
+    failed BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (sequence_id, position)
+);
+
+CREATE TABLE drip_enrollments (
+    id BIGSERIAL PRIMARY KEY,
+    sequence_id BIGINT NOT NULL REFERENCES drip_sequences (id) ON DELETE CASCADE,
+    telegram_id BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    next_step INTEGER NOT NULL DEFAULT 0,
+    next_run_at TIMESTAMPTZ,
+    replied_at TIMESTAMPTZ,
+    enrolled_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (sequence_id, telegram_id)
+);
+
+CREATE INDEX idx_drip_enrollments_next_run_at ON drip_enrollments (next_run_at) WHERE status = 'active';
+CREATE INDEX idx_drip_enrollments_telegram_id ON drip_enrollments (telegram_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS drip_enrollments;
+DROP TABLE IF EXISTS drip_steps;
+DROP TABLE IF EXISTS drip_sequences;
@@ -0,0 +1,9 @@
+-- Timezones of the users and channels, used to hold broadcasts until the quiet hours of the recipients end.
+
+-- +goose Up
+ALTER TABLE users ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE channels ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE channels DROP COLUMN IF EXISTS timezone;
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,27 @@
+-- Delivery counts of the broadcast variants and the button presses of the recipients, kept after the broadcasts are forgotten.
+
+-- +goose Up
+CREATE TABLE broadcast_variants (
+    broadcast_id VARCHAR(32) NOT NULL,
+    variant VARCHAR(1) NOT NULL,
+    weight INTEGER NOT NULL,
+    sent BIGINT NOT NULL DEFAULT 0,
+    failed BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (broadcast_id, variant)
+);
+
+CREATE TABLE broadcast_responses (
+    broadcast_id VARCHAR(32) NOT NULL,
+    variant VARCHAR(1) NOT NULL,
+    telegram_id BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (broadcast_id, variant, telegram_id),
+    FOREIGN KEY (broadcast_id, variant) REFERENCES broadcast_variants (broadcast_id, variant) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_broadcast_responses_telegram_id ON broadcast_responses (telegram_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS broadcast_responses;
+DROP TABLE IF EXISTS broadcast_variants;
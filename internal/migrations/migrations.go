@@ -0,0 +1,145 @@
+// Package migrations contains the versioned SQL migrations of the database schema.
+// Files are named NNNNN_description.sql and have goose "-- +goose Up" and "-- +goose Down" sections.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/database"
+	"github.com/pressly/goose/v3/lock"
+	"github.com/sirupsen/logrus"
+)
+
+// TableName is the table recording applied migration versions
+const TableName = "schema_migrations"
+
+//go:embed *.sql
+var files embed.FS
+
+// Migrator applies the embedded migrations, concurrent runs are serialized with a Postgres advisory lock
+type Migrator struct {
+	provider *goose.Provider
+}
+
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	store, err := database.NewStore(database.DialectPostgres, TableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrations store: %w", err)
+	}
+
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrations lock: %w", err)
+	}
+
+	provider, err := goose.NewProvider("", db, files,
+		goose.WithStore(store),
+		goose.WithSessionLocker(locker),
+		goose.WithDisableGlobalRegistry(true),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrations provider: %w", err)
+	}
+
+	return &Migrator{provider}, nil
+}
+
+// Up applies all pending migrations
+func (m *Migrator) Up(ctx context.Context) error {
+	results, err := m.provider.Up(ctx)
+	logResults(results, err)
+	if err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
+	return nil
+}
+
+// Down rolls back the latest applied migration
+func (m *Migrator) Down(ctx context.Context) error {
+	result, err := m.provider.Down(ctx)
+	if result != nil {
+		logResults([]*goose.MigrationResult{result}, err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to roll back migration: %w", err)
+	}
+
+	return nil
+}
+
+// DownTo rolls back migrations until the version is the latest applied one, 0 rolls back all
+func (m *Migrator) DownTo(ctx context.Context, version int64) error {
+	results, err := m.provider.DownTo(ctx, version)
+	logResults(results, err)
+	if err != nil {
+		return fmt.Errorf("failed to roll back migrations to version %d: %w", version, err)
+	}
+
+	return nil
+}
+
+// Status logs every migration with its state
+func (m *Migrator) Status(ctx context.Context) error {
+	statuses, err := m.provider.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get migrations status: %w", err)
+	}
+
+	for _, status := range statuses {
+		fields := logrus.Fields{"version": status.Source.Version, "state": status.State}
+		if !status.AppliedAt.IsZero() {
+			fields["applied_at"] = status.AppliedAt
+		}
+		logrus.WithFields(fields).Info(status.Source.Path)
+	}
+
+	return nil
+}
+
+// Versions returns the current version of the database and the latest embedded version
+func (m *Migrator) Versions(ctx context.Context) (current, latest int64, err error) {
+	current, latest, err = m.provider.GetVersions(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get migration versions: %w", err)
+	}
+
+	return current, latest, nil
+}
+
+// HasPending reports whether there are migrations not applied yet
+func (m *Migrator) HasPending(ctx context.Context) (bool, error) {
+	pending, err := m.provider.HasPending(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to check pending migrations: %w", err)
+	}
+
+	return pending, nil
+}
+
+// logResults logs the applied migrations, on a partial failure results are taken from the error
+func logResults(results []*goose.MigrationResult, err error) {
+	var partialErr *goose.PartialError
+	if errors.As(err, &partialErr) {
+		results = append(partialErr.Applied, partialErr.Failed)
+	}
+
+	for _, result := range results {
+		entry := logrus.WithFields(logrus.Fields{
+			"version":   result.Source.Version,
+			"direction": result.Direction,
+			"duration":  result.Duration,
+		})
+
+		if result.Error != nil {
+			entry.Error("migration failed: ", result.Error)
+		} else {
+			entry.Info("migration applied: ", result.Source.Path)
+		}
+	}
+}
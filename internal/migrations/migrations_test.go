@@ -0,0 +1,145 @@
+package migrations_test
+
+import (
+	"fmt"
+	"hr-server/internal/migrations"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testDSNEnv names the Postgres connection string the migration tests run against, they are skipped without it
+const testDSNEnv = "TEST_POSTGRES_DSN"
+
+// baselineChannel and baselineUser are the tables as the AutoMigrate boot created them before versioned migrations
+type baselineChannel struct {
+	ID        int    `gorm:"primaryKey;autoIncrement"`
+	Name      string `gorm:"size:255"`
+	Code      string `gorm:"size:50;uniqueIndex"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (baselineChannel) TableName() string {
+	return "channels"
+}
+
+type baselineUser struct {
+	ID         int    `gorm:"primaryKey;autoIncrement"`
+	TelegramID int64  `gorm:"uniqueIndex"`
+	Username   string `gorm:"size:255"`
+	ChannelID  *int   `gorm:"index"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (baselineUser) TableName() string {
+	return "users"
+}
+
+// newSchemaDB connects to a new schema of the test database, the schema is dropped at the end of the test
+func newSchemaDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+
+	connConfig, err := pgx.ParseConfig(dsn)
+	require.NoError(t, err)
+
+	schema := fmt.Sprintf("migrations_test_%d", time.Now().UnixNano())
+	admin := stdlib.OpenDB(*connConfig)
+	t.Cleanup(func() { admin.Close() })
+
+	_, err = admin.ExecContext(t.Context(), "CREATE SCHEMA "+schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		assert.NoError(t, err)
+	})
+
+	connConfig.RuntimeParams["search_path"] = schema
+	sqlDB := stdlib.OpenDB(*connConfig)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+
+	return db
+}
+
+// columns returns the columns of the table in the schema of the connection
+func columns(t *testing.T, db *gorm.DB, table string) []string {
+	t.Helper()
+
+	var names []string
+	err := db.Raw(`SELECT column_name FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = ?`, table).Scan(&names).Error
+	require.NoError(t, err)
+
+	return names
+}
+
+func TestMigrator_UpFromBaseline(t *testing.T) {
+	db := newSchemaDB(t)
+
+	require.NoError(t, db.AutoMigrate(baselineChannel{}, baselineUser{}))
+
+	channel := baselineChannel{Name: "hh.ru", Code: "abc123"}
+	require.NoError(t, db.Create(&channel).Error)
+	require.NoError(t, db.Create(&baselineUser{TelegramID: 42, Username: "user", ChannelID: &channel.ID}).Error)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+
+	migrator, err := migrations.NewMigrator(sqlDB)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up(t.Context()))
+
+	current, latest, err := migrator.Versions(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, latest, current)
+
+	assert.Subset(t, columns(t, db, "channels"), []string{"link_type", "campaign_id", "source_id", "expires_at", "max_users", "timezone"})
+	assert.Subset(t, columns(t, db, "users"), []string{"status", "last_seen_at", "first_name", "timezone"})
+
+	var user struct {
+		Status    string
+		ChannelID int
+	}
+	require.NoError(t, db.Raw("SELECT status, channel_id FROM users WHERE telegram_id = 42").Scan(&user).Error)
+	assert.Equal(t, "active", user.Status, "existing users get the default status")
+	assert.Equal(t, channel.ID, user.ChannelID, "existing users keep their channel")
+
+	var linkType string
+	require.NoError(t, db.Raw("SELECT link_type FROM channels WHERE id = ?", channel.ID).Scan(&linkType).Error)
+	assert.Equal(t, "start", linkType)
+}
+
+func TestMigrator_UpDownFresh(t *testing.T) {
+	db := newSchemaDB(t)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+
+	migrator, err := migrations.NewMigrator(sqlDB)
+	require.NoError(t, err)
+
+	require.NoError(t, migrator.Up(t.Context()))
+	require.NoError(t, migrator.DownTo(t.Context(), 0))
+	require.NoError(t, migrator.Up(t.Context()))
+
+	pending, err := migrator.HasPending(t.Context())
+	require.NoError(t, err)
+	assert.False(t, pending)
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"hr-server/internal/domain"
+	"slices"
+	"time"
+)
+
+// AdminMemoryRepository is the in-memory counterpart of AdminRepository
+type AdminMemoryRepository struct {
+	db *MemoryDB
+}
+
+func NewAdminMemoryRepository(db *MemoryDB) *AdminMemoryRepository {
+	return &AdminMemoryRepository{db}
+}
+
+func (r *AdminMemoryRepository) Create(ctx context.Context, admin *domain.Admin) (*domain.Admin, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	duplicate := r.find(func(a *domain.Admin) bool {
+		return a.Username == admin.Username ||
+			(a.TelegramID != nil && admin.TelegramID != nil && *a.TelegramID == *admin.TelegramID)
+	})
+	if duplicate != nil {
+		return nil, fmt.Errorf("failed to create admin '%s': %w", admin.Username, ErrMemoryDuplicate)
+	}
+
+	now := time.Now()
+	created := cloneAdmin(admin)
+	created.ID = r.db.nextID(ADMINS_TABLE_NAME)
+	created.CreatedAt = now
+	created.UpdatedAt = now
+	r.db.admins = append(r.db.admins, created)
+
+	return cloneAdmin(created), nil
+}
+
+func (r *AdminMemoryRepository) GetByID(ctx context.Context, id int) (*domain.Admin, error) {
+	return r.getBy(func(a *domain.Admin) bool { return a.ID == id })
+}
+
+func (r *AdminMemoryRepository) GetByUsername(ctx context.Context, username string) (*domain.Admin, error) {
+	return r.getBy(func(a *domain.Admin) bool { return a.Username == username })
+}
+
+func (r *AdminMemoryRepository) GetByTelegramID(ctx context.Context, telegramID int64) (*domain.Admin, error) {
+	return r.getBy(func(a *domain.Admin) bool { return a.TelegramID != nil && *a.TelegramID == telegramID })
+}
+
+func (r *AdminMemoryRepository) getBy(match func(*domain.Admin) bool) (*domain.Admin, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	admin := r.find(match)
+	if admin == nil {
+		return nil, nil
+	}
+
+	return cloneAdmin(admin), nil
+}
+
+func (r *AdminMemoryRepository) GetAll(ctx context.Context) ([]*domain.Admin, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	admins := make([]*domain.Admin, 0, len(r.db.admins))
+	for _, admin := range r.db.admins {
+		admins = append(admins, cloneAdmin(admin))
+	}
+
+	return admins, nil
+}
+
+func (r *AdminMemoryRepository) UpdateLastLogin(ctx context.Context, id int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if admin := r.find(func(a *domain.Admin) bool { return a.ID == id }); admin != nil {
+		now := time.Now()
+		admin.LastLoginAt = &now
+		admin.UpdatedAt = now
+	}
+
+	return nil
+}
+
+// Disable disables the admin and revokes all sessions, returns false if the admin doesn't exist
+func (r *AdminMemoryRepository) Disable(ctx context.Context, id int) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+
+	admin := r.find(func(a *domain.Admin) bool { return a.ID == id })
+	if admin != nil {
+		admin.DisabledAt = &now
+		admin.UpdatedAt = now
+	}
+
+	for _, token := range r.db.refreshTokens {
+		if token.AdminID == id && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+
+	return admin != nil, nil
+}
+
+func (r *AdminMemoryRepository) CreateRefreshToken(ctx context.Context, token *domain.AdminRefreshToken) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, stored := range r.db.refreshTokens {
+		if stored.Hash == token.Hash {
+			return fmt.Errorf("failed to create refresh token of admin %d: %w", token.AdminID, ErrMemoryDuplicate)
+		}
+	}
+
+	r.db.refreshTokens = append(r.db.refreshTokens, &domain.AdminRefreshToken{
+		ID:        r.db.nextID(ADMIN_REFRESH_TOKENS_TABLE_NAME),
+		AdminID:   token.AdminID,
+		Hash:      token.Hash,
+		ExpiresAt: token.ExpiresAt,
+		CreatedAt: time.Now(),
+	})
+
+	return nil
+}
+
+// RevokeRefreshToken revokes the refresh token and returns it, nil if it doesn't exist or is already revoked
+func (r *AdminMemoryRepository) RevokeRefreshToken(ctx context.Context, hash string) (*domain.AdminRefreshToken, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, token := range r.db.refreshTokens {
+		if token.Hash == hash && token.RevokedAt == nil {
+			now := time.Now()
+			token.RevokedAt = &now
+
+			clone := *token
+			return &clone, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// find returns the first stored admin matching the condition, the lock must be held
+func (r *AdminMemoryRepository) find(match func(*domain.Admin) bool) *domain.Admin {
+	for _, admin := range r.db.admins {
+		if match(admin) {
+			return admin
+		}
+	}
+
+	return nil
+}
+
+func cloneAdmin(admin *domain.Admin) *domain.Admin {
+	clone := *admin
+	clone.Scopes = slices.Clone(admin.Scopes)
+	return &clone
+}
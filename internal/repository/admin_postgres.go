@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hr-server/internal/domain"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ADMINS_TABLE_NAME               = "admins"
+	ADMIN_REFRESH_TOKENS_TABLE_NAME = "admin_refresh_tokens"
+)
+
+type PostgresAdmin struct {
+	ID           int    `gorm:"primaryKey;autoIncrement"`
+	Username     string `gorm:"size:255;uniqueIndex"`
+	TelegramID   *int64 `gorm:"uniqueIndex"`
+	PasswordHash string `gorm:"size:255"`
+	Scopes       string `gorm:"size:1000"` // comma-separated
+	LastLoginAt  *time.Time
+	DisabledAt   *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type PostgresAdminRefreshToken struct {
+	ID        int    `gorm:"primaryKey;autoIncrement"`
+	AdminID   int    `gorm:"index"`
+	Hash      string `gorm:"size:64;uniqueIndex"`
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+func NewPostgresAdmin(admin *domain.Admin) PostgresAdmin {
+	return PostgresAdmin{
+		ID:           admin.ID,
+		Username:     admin.Username,
+		TelegramID:   admin.TelegramID,
+		PasswordHash: admin.PasswordHash,
+		Scopes:       strings.Join(admin.Scopes, ","),
+	}
+}
+
+func (pa PostgresAdmin) TableName() string {
+	return ADMINS_TABLE_NAME
+}
+
+func (pa PostgresAdmin) ToDomain() *domain.Admin {
+	scopes := []string{}
+	if pa.Scopes != "" {
+		scopes = strings.Split(pa.Scopes, ",")
+	}
+
+	return &domain.Admin{
+		ID:           pa.ID,
+		Username:     pa.Username,
+		TelegramID:   pa.TelegramID,
+		PasswordHash: pa.PasswordHash,
+		Scopes:       scopes,
+		LastLoginAt:  pa.LastLoginAt,
+		DisabledAt:   pa.DisabledAt,
+		CreatedAt:    pa.CreatedAt,
+		UpdatedAt:    pa.UpdatedAt,
+	}
+}
+
+func (pt PostgresAdminRefreshToken) TableName() string {
+	return ADMIN_REFRESH_TOKENS_TABLE_NAME
+}
+
+func (pt PostgresAdminRefreshToken) ToDomain() *domain.AdminRefreshToken {
+	return &domain.AdminRefreshToken{
+		ID:        pt.ID,
+		AdminID:   pt.AdminID,
+		Hash:      pt.Hash,
+		ExpiresAt: pt.ExpiresAt,
+		RevokedAt: pt.RevokedAt,
+		CreatedAt: pt.CreatedAt,
+	}
+}
+
+type AdminRepository struct {
+	db *gorm.DB
+}
+
+func NewAdminRepository(db *gorm.DB) *AdminRepository {
+	return &AdminRepository{db}
+}
+
+func (r *AdminRepository) Create(ctx context.Context, admin *domain.Admin) (*domain.Admin, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	postgresAdmin := NewPostgresAdmin(admin)
+	if err := r.db.WithContext(ctx).Table(ADMINS_TABLE_NAME).Create(&postgresAdmin).Error; err != nil {
+		return nil, fmt.Errorf("failed to create admin '%s': %w", admin.Username, err)
+	}
+
+	return postgresAdmin.ToDomain(), nil
+}
+
+func (r *AdminRepository) GetByID(ctx context.Context, id int) (*domain.Admin, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	return r.getBy(ctx, "id = ?", id)
+}
+
+func (r *AdminRepository) GetByUsername(ctx context.Context, username string) (*domain.Admin, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	return r.getBy(ctx, "username = ?", username)
+}
+
+func (r *AdminRepository) GetByTelegramID(ctx context.Context, telegramID int64) (*domain.Admin, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	return r.getBy(ctx, "telegram_id = ?", telegramID)
+}
+
+func (r *AdminRepository) getBy(ctx context.Context, condition string, value interface{}) (*domain.Admin, error) {
+	var postgresAdmin PostgresAdmin
+
+	if err := r.db.WithContext(ctx).Table(ADMINS_TABLE_NAME).First(&postgresAdmin, condition, value).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get admin by '%s': %w", condition, err)
+	}
+
+	return postgresAdmin.ToDomain(), nil
+}
+
+func (r *AdminRepository) GetAll(ctx context.Context) ([]*domain.Admin, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var postgresAdmins []PostgresAdmin
+
+	if err := r.db.WithContext(ctx).Table(ADMINS_TABLE_NAME).Order("id").Find(&postgresAdmins).Error; err != nil {
+		return nil, fmt.Errorf("failed to get all admins: %w", err)
+	}
+
+	admins := make([]*domain.Admin, 0, len(postgresAdmins))
+	for _, pa := range postgresAdmins {
+		admins = append(admins, pa.ToDomain())
+	}
+
+	return admins, nil
+}
+
+func (r *AdminRepository) UpdateLastLogin(ctx context.Context, id int) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	now := time.Now()
+
+	err := r.db.WithContext(ctx).Table(ADMINS_TABLE_NAME).Where("id = ?", id).
+		Updates(map[string]interface{}{"last_login_at": now, "updated_at": now}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update last login of admin %d: %w", id, err)
+	}
+
+	return nil
+}
+
+// Disable disables the admin and revokes all sessions, returns false if the admin doesn't exist
+func (r *AdminRepository) Disable(ctx context.Context, id int) (bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	now := time.Now()
+	var found bool
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Table(ADMINS_TABLE_NAME).Where("id = ?", id).
+			Updates(map[string]interface{}{"disabled_at": now, "updated_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		found = result.RowsAffected > 0
+
+		return tx.Table(ADMIN_REFRESH_TOKENS_TABLE_NAME).
+			Where("admin_id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", now).Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to disable admin %d: %w", id, err)
+	}
+
+	return found, nil
+}
+
+func (r *AdminRepository) CreateRefreshToken(ctx context.Context, token *domain.AdminRefreshToken) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	postgresToken := PostgresAdminRefreshToken{
+		AdminID:   token.AdminID,
+		Hash:      token.Hash,
+		ExpiresAt: token.ExpiresAt,
+	}
+
+	if err := r.db.WithContext(ctx).Table(ADMIN_REFRESH_TOKENS_TABLE_NAME).Create(&postgresToken).Error; err != nil {
+		return fmt.Errorf("failed to create refresh token of admin %d: %w", token.AdminID, err)
+	}
+
+	return nil
+}
+
+// RevokeRefreshToken revokes the refresh token and returns it, nil if it doesn't exist or is already revoked.
+// The update is atomic, so a token can be exchanged only once.
+func (r *AdminRepository) RevokeRefreshToken(ctx context.Context, hash string) (*domain.AdminRefreshToken, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var postgresTokens []PostgresAdminRefreshToken
+
+	err := r.db.WithContext(ctx).Raw(
+		"UPDATE admin_refresh_tokens SET revoked_at = ? WHERE hash = ? AND revoked_at IS NULL RETURNING *",
+		time.Now(), hash,
+	).Scan(&postgresTokens).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	if len(postgresTokens) == 0 {
+		return nil, nil
+	}
+
+	return postgresTokens[0].ToDomain(), nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"hr-server/internal/domain"
+	"slices"
+	"time"
+)
+
+// APIKeyMemoryRepository is the in-memory counterpart of APIKeyRepository
+type APIKeyMemoryRepository struct {
+	db *MemoryDB
+}
+
+func NewAPIKeyMemoryRepository(db *MemoryDB) *APIKeyMemoryRepository {
+	return &APIKeyMemoryRepository{db}
+}
+
+func (r *APIKeyMemoryRepository) Create(ctx context.Context, key *domain.APIKey) (*domain.APIKey, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if r.find(func(k *domain.APIKey) bool { return k.Prefix == key.Prefix }) != nil {
+		return nil, fmt.Errorf("failed to create API key '%s': %w", key.Name, ErrMemoryDuplicate)
+	}
+
+	created := cloneAPIKey(key)
+	created.ID = r.db.nextID(API_KEYS_TABLE_NAME)
+	created.CreatedAt = time.Now()
+	r.db.apiKeys = append(r.db.apiKeys, created)
+
+	return cloneAPIKey(created), nil
+}
+
+func (r *APIKeyMemoryRepository) GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	key := r.find(func(k *domain.APIKey) bool { return k.Prefix == prefix })
+	if key == nil {
+		return nil, nil
+	}
+
+	return cloneAPIKey(key), nil
+}
+
+func (r *APIKeyMemoryRepository) GetAll(ctx context.Context) ([]*domain.APIKey, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	keys := make([]*domain.APIKey, 0, len(r.db.apiKeys))
+	for _, key := range r.db.apiKeys {
+		keys = append(keys, cloneAPIKey(key))
+	}
+
+	return keys, nil
+}
+
+// Revoke revokes the key, returns false if the key doesn't exist or is already revoked
+func (r *APIKeyMemoryRepository) Revoke(ctx context.Context, id int) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	key := r.find(func(k *domain.APIKey) bool { return k.ID == id && k.RevokedAt == nil })
+	if key == nil {
+		return false, nil
+	}
+
+	now := time.Now()
+	key.RevokedAt = &now
+
+	return true, nil
+}
+
+// TouchLastUsed sets the last usage time of the key unless it was set less than the interval ago
+func (r *APIKeyMemoryRepository) TouchLastUsed(ctx context.Context, id int, interval time.Duration) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	key := r.find(func(k *domain.APIKey) bool { return k.ID == id })
+	if key != nil && (key.LastUsedAt == nil || key.LastUsedAt.Before(now.Add(-interval))) {
+		key.LastUsedAt = &now
+	}
+
+	return nil
+}
+
+// find returns the first stored key matching the condition, the lock must be held
+func (r *APIKeyMemoryRepository) find(match func(*domain.APIKey) bool) *domain.APIKey {
+	for _, key := range r.db.apiKeys {
+		if match(key) {
+			return key
+		}
+	}
+
+	return nil
+}
+
+func cloneAPIKey(key *domain.APIKey) *domain.APIKey {
+	clone := *key
+	clone.Scopes = slices.Clone(key.Scopes)
+	return &clone
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hr-server/internal/domain"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const API_KEYS_TABLE_NAME = "api_keys"
+
+type PostgresAPIKey struct {
+	ID         int    `gorm:"primaryKey;autoIncrement"`
+	Name       string `gorm:"size:255"`
+	Prefix     string `gorm:"size:32;uniqueIndex"`
+	Hash       string `gorm:"size:64"`
+	Scopes     string `gorm:"size:1000"` // comma-separated
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func NewPostgresAPIKey(key *domain.APIKey) PostgresAPIKey {
+	return PostgresAPIKey{
+		ID:        key.ID,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Hash:      key.Hash,
+		Scopes:    strings.Join(key.Scopes, ","),
+		ExpiresAt: key.ExpiresAt,
+	}
+}
+
+func (pk PostgresAPIKey) TableName() string {
+	return API_KEYS_TABLE_NAME
+}
+
+func (pk PostgresAPIKey) ToDomain() *domain.APIKey {
+	scopes := []string{}
+	if pk.Scopes != "" {
+		scopes = strings.Split(pk.Scopes, ",")
+	}
+
+	return &domain.APIKey{
+		ID:         pk.ID,
+		Name:       pk.Name,
+		Prefix:     pk.Prefix,
+		Hash:       pk.Hash,
+		Scopes:     scopes,
+		ExpiresAt:  pk.ExpiresAt,
+		LastUsedAt: pk.LastUsedAt,
+		RevokedAt:  pk.RevokedAt,
+		CreatedAt:  pk.CreatedAt,
+	}
+}
+
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db}
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, key *domain.APIKey) (*domain.APIKey, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	postgresKey := NewPostgresAPIKey(key)
+	if err := r.db.WithContext(ctx).Table(API_KEYS_TABLE_NAME).Create(&postgresKey).Error; err != nil {
+		return nil, fmt.Errorf("failed to create API key '%s': %w", key.Name, err)
+	}
+
+	return postgresKey.ToDomain(), nil
+}
+
+func (r *APIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var postgresKey PostgresAPIKey
+
+	if err := r.db.WithContext(ctx).Table(API_KEYS_TABLE_NAME).First(&postgresKey, "prefix = ?", prefix).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get API key by prefix '%s': %w", prefix, err)
+	}
+
+	return postgresKey.ToDomain(), nil
+}
+
+func (r *APIKeyRepository) GetAll(ctx context.Context) ([]*domain.APIKey, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var postgresKeys []PostgresAPIKey
+
+	if err := r.db.WithContext(ctx).Table(API_KEYS_TABLE_NAME).Order("id").Find(&postgresKeys).Error; err != nil {
+		return nil, fmt.Errorf("failed to get all API keys: %w", err)
+	}
+
+	keys := make([]*domain.APIKey, 0, len(postgresKeys))
+	for _, pk := range postgresKeys {
+		keys = append(keys, pk.ToDomain())
+	}
+
+	return keys, nil
+}
+
+// Revoke revokes the key, returns false if the key doesn't exist or is already revoked
+func (r *APIKeyRepository) Revoke(ctx context.Context, id int) (bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	now := time.Now()
+
+	result := r.db.WithContext(ctx).Table(API_KEYS_TABLE_NAME).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": now, "updated_at": now})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to revoke API key %d: %w", id, result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// TouchLastUsed sets the last usage time of the key unless it was set less than the interval ago
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id int, interval time.Duration) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	now := time.Now()
+
+	err := r.db.WithContext(ctx).Table(API_KEYS_TABLE_NAME).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-interval)).
+		Update("last_used_at", now).Error
+	if err != nil {
+		return fmt.Errorf("failed to update last usage of API key %d: %w", id, err)
+	}
+
+	return nil
+}
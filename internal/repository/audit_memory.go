@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"hr-server/internal/domain"
+	"time"
+)
+
+// AuditMemoryRepository is the in-memory counterpart of AuditRepository
+type AuditMemoryRepository struct {
+	db *MemoryDB
+}
+
+func NewAuditMemoryRepository(db *MemoryDB) *AuditMemoryRepository {
+	return &AuditMemoryRepository{db}
+}
+
+func (r *AuditMemoryRepository) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	created := *entry
+	created.ID = r.db.nextID(AUDIT_LOG_TABLE_NAME)
+	created.CreatedAt = time.Now()
+	r.db.auditLog = append(r.db.auditLog, &created)
+
+	return nil
+}
+
+var auditMemoryKeyset = memoryKeyset[*domain.AuditEntry]{
+	id: func(e *domain.AuditEntry) int { return e.ID },
+	columns: map[string]func(a, b *domain.AuditEntry) int{
+		"created_at": func(a, b *domain.AuditEntry) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	},
+	defaultSortBy: "created_at",
+}
+
+// GetPage returns a page of audit entries matching the filter
+func (r *AuditMemoryRepository) GetPage(ctx context.Context, filter domain.AuditFilter, page domain.PageRequest) (*domain.Page[*domain.AuditEntry], error) {
+	r.db.mu.RLock()
+	entries := r.filtered(filter)
+	r.db.mu.RUnlock()
+
+	items, nextCursor, err := auditMemoryKeyset.fetch(entries, page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page of audit entries: %w", err)
+	}
+
+	return &domain.Page[*domain.AuditEntry]{
+		Items:      items,
+		TotalCount: int64(len(entries)),
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// filtered returns copies of the audit entries matching the filter, the lock must be held
+func (r *AuditMemoryRepository) filtered(filter domain.AuditFilter) []*domain.AuditEntry {
+	entries := []*domain.AuditEntry{}
+
+	for _, entry := range r.db.auditLog {
+		switch {
+		case filter.ActorType != "" && entry.ActorType != filter.ActorType,
+			filter.ActorID != nil && entry.ActorID != *filter.ActorID,
+			filter.Action != "" && entry.Action != filter.Action,
+			filter.Target != "" && entry.Target != filter.Target,
+			filter.Result != "" && entry.Result != filter.Result,
+			filter.CreatedAt.From != nil && entry.CreatedAt.Before(*filter.CreatedAt.From),
+			filter.CreatedAt.To != nil && !entry.CreatedAt.Before(*filter.CreatedAt.To):
+			continue
+		}
+
+		clone := *entry
+		entries = append(entries, &clone)
+	}
+
+	return entries
+}
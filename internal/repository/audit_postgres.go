@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"hr-server/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const AUDIT_LOG_TABLE_NAME = "audit_log"
+
+type PostgresAuditEntry struct {
+	ID        int    `gorm:"primaryKey;autoIncrement"`
+	ActorType string `gorm:"size:20;index:idx_audit_log_actor"`
+	ActorID   int    `gorm:"index:idx_audit_log_actor"`
+	ActorName string `gorm:"size:255"`
+	Action    string `gorm:"size:50;index"`
+	Target    string `gorm:"size:255;index"`
+	Request   string `gorm:"type:text"`
+	Status    int
+	Result    string    `gorm:"size:20"`
+	ClientIP  string    `gorm:"size:64"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+func NewPostgresAuditEntry(entry *domain.AuditEntry) PostgresAuditEntry {
+	return PostgresAuditEntry{
+		ID:        entry.ID,
+		ActorType: entry.ActorType,
+		ActorID:   entry.ActorID,
+		ActorName: entry.ActorName,
+		Action:    entry.Action,
+		Target:    entry.Target,
+		Request:   entry.Request,
+		Status:    entry.Status,
+		Result:    entry.Result,
+		ClientIP:  entry.ClientIP,
+	}
+}
+
+func (pe PostgresAuditEntry) TableName() string {
+	return AUDIT_LOG_TABLE_NAME
+}
+
+func (pe PostgresAuditEntry) ToDomain() *domain.AuditEntry {
+	return &domain.AuditEntry{
+		ID:        pe.ID,
+		ActorType: pe.ActorType,
+		ActorID:   pe.ActorID,
+		ActorName: pe.ActorName,
+		Action:    pe.Action,
+		Target:    pe.Target,
+		Request:   pe.Request,
+		Status:    pe.Status,
+		Result:    pe.Result,
+		ClientIP:  pe.ClientIP,
+		CreatedAt: pe.CreatedAt,
+	}
+}
+
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db}
+}
+
+func (r *AuditRepository) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	postgresEntry := NewPostgresAuditEntry(entry)
+	if err := r.db.WithContext(ctx).Table(AUDIT_LOG_TABLE_NAME).Create(&postgresEntry).Error; err != nil {
+		return fmt.Errorf("failed to create audit entry '%s': %w", entry.Action, err)
+	}
+
+	return nil
+}
+
+var auditKeyset = keyset[PostgresAuditEntry]{
+	idColumn: "audit_log.id",
+	id:       func(pe PostgresAuditEntry) int { return pe.ID },
+	columns: map[string]sortColumn[PostgresAuditEntry]{
+		"created_at": {
+			column: "audit_log.created_at",
+			cast:   "timestamptz",
+			value:  func(pe PostgresAuditEntry) string { return pe.CreatedAt.Format(time.RFC3339Nano) },
+		},
+	},
+	defaultSortBy: "created_at",
+}
+
+// GetPage returns a page of audit entries matching the filter
+func (r *AuditRepository) GetPage(ctx context.Context, filter domain.AuditFilter, page domain.PageRequest) (*domain.Page[*domain.AuditEntry], error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var total int64
+	if err := r.filtered(ctx, filter).Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+
+	postgresEntries, nextCursor, err := auditKeyset.fetch(r.filtered(ctx, filter), page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page of audit entries: %w", err)
+	}
+
+	entries := make([]*domain.AuditEntry, 0, len(postgresEntries))
+	for _, pe := range postgresEntries {
+		entries = append(entries, pe.ToDomain())
+	}
+
+	return &domain.Page[*domain.AuditEntry]{
+		Items:      entries,
+		TotalCount: total,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// filtered returns a new audit log query with the filter applied
+func (r *AuditRepository) filtered(ctx context.Context, filter domain.AuditFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Table(AUDIT_LOG_TABLE_NAME)
+
+	if filter.ActorType != "" {
+		query = query.Where("audit_log.actor_type = ?", filter.ActorType)
+	}
+
+	if filter.ActorID != nil {
+		query = query.Where("audit_log.actor_id = ?", *filter.ActorID)
+	}
+
+	if filter.Action != "" {
+		query = query.Where("audit_log.action = ?", filter.Action)
+	}
+
+	if filter.Target != "" {
+		query = query.Where("audit_log.target = ?", filter.Target)
+	}
+
+	if filter.Result != "" {
+		query = query.Where("audit_log.result = ?", filter.Result)
+	}
+
+	if filter.CreatedAt.From != nil {
+		query = query.Where("audit_log.created_at >= ?", *filter.CreatedAt.From)
+	}
+
+	if filter.CreatedAt.To != nil {
+		query = query.Where("audit_log.created_at < ?", *filter.CreatedAt.To)
+	}
+
+	return query
+}
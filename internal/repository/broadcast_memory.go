@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"hr-server/internal/domain"
+	"slices"
+	"time"
+)
+
+// memoryBroadcastVariant is a stored variant with its broadcast, the responses are counted on read
+type memoryBroadcastVariant struct {
+	broadcastID string
+	variant     domain.BroadcastVariant
+}
+
+// BroadcastMemoryRepository is the in-memory counterpart of BroadcastRepository
+type BroadcastMemoryRepository struct {
+	db *MemoryDB
+}
+
+func NewBroadcastMemoryRepository(db *MemoryDB) *BroadcastMemoryRepository {
+	return &BroadcastMemoryRepository{db}
+}
+
+func (r *BroadcastMemoryRepository) CreateVariants(ctx context.Context, broadcastID string, variants []domain.BroadcastVariant) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, variant := range variants {
+		if r.findVariant(broadcastID, variant.Name) != nil {
+			return ErrMemoryDuplicate
+		}
+	}
+
+	for _, variant := range variants {
+		r.db.broadcastVariants = append(r.db.broadcastVariants, &memoryBroadcastVariant{
+			broadcastID: broadcastID,
+			variant:     domain.BroadcastVariant{Name: variant.Name, Weight: variant.Weight},
+		})
+	}
+
+	return nil
+}
+
+func (r *BroadcastMemoryRepository) IncrementVariant(ctx context.Context, broadcastID, variant string, sent, failed int64) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if stored := r.findVariant(broadcastID, variant); stored != nil {
+		stored.variant.Sent += sent
+		stored.variant.Failed += failed
+	}
+
+	return nil
+}
+
+func (r *BroadcastMemoryRepository) CreateResponse(ctx context.Context, broadcastID, variant string, telegramID int64) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, response := range r.db.broadcastResponses {
+		if response.BroadcastID == broadcastID && response.Variant == variant && response.TelegramID == telegramID {
+			return false, nil
+		}
+	}
+
+	r.db.broadcastResponses = append(r.db.broadcastResponses, &domain.BroadcastResponse{
+		BroadcastID: broadcastID,
+		Variant:     variant,
+		TelegramID:  telegramID,
+		CreatedAt:   time.Now(),
+	})
+
+	return true, nil
+}
+
+func (r *BroadcastMemoryRepository) GetVariants(ctx context.Context, broadcastID string) ([]domain.BroadcastVariant, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	variants := []domain.BroadcastVariant{}
+	for _, stored := range r.db.broadcastVariants {
+		if stored.broadcastID != broadcastID {
+			continue
+		}
+
+		variant := stored.variant
+		for _, response := range r.db.broadcastResponses {
+			if response.BroadcastID == broadcastID && response.Variant == variant.Name {
+				variant.Responses++
+			}
+		}
+		variants = append(variants, variant)
+	}
+
+	slices.SortFunc(variants, func(a, b domain.BroadcastVariant) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return variants, nil
+}
+
+// findVariant returns the stored variant or nil, the lock must be held
+func (r *BroadcastMemoryRepository) findVariant(broadcastID, variant string) *memoryBroadcastVariant {
+	for _, stored := range r.db.broadcastVariants {
+		if stored.broadcastID == broadcastID && stored.variant.Name == variant {
+			return stored
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"hr-server/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	BROADCAST_VARIANTS_TABLE_NAME  = "broadcast_variants"
+	BROADCAST_RESPONSES_TABLE_NAME = "broadcast_responses"
+)
+
+type PostgresBroadcastVariant struct {
+	BroadcastID string `gorm:"primaryKey;size:32"`
+	Variant     string `gorm:"primaryKey;size:1"`
+	Weight      int
+	Sent        int64
+	Failed      int64
+	CreatedAt   time.Time
+}
+
+func (pv PostgresBroadcastVariant) TableName() string {
+	return BROADCAST_VARIANTS_TABLE_NAME
+}
+
+type PostgresBroadcastResponse struct {
+	BroadcastID string `gorm:"primaryKey;size:32"`
+	Variant     string `gorm:"primaryKey;size:1"`
+	TelegramID  int64  `gorm:"primaryKey;index"`
+	CreatedAt   time.Time
+}
+
+func (pr PostgresBroadcastResponse) TableName() string {
+	return BROADCAST_RESPONSES_TABLE_NAME
+}
+
+// BroadcastRepository stores the delivery counts and the responses of the broadcast variants,
+// so they outlive the broadcasts kept in memory
+type BroadcastRepository struct {
+	db *gorm.DB
+}
+
+func NewBroadcastRepository(db *gorm.DB) *BroadcastRepository {
+	return &BroadcastRepository{db}
+}
+
+// CreateVariants stores the variants of a new broadcast by their names and weights
+func (r *BroadcastRepository) CreateVariants(ctx context.Context, broadcastID string, variants []domain.BroadcastVariant) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	postgresVariants := make([]PostgresBroadcastVariant, 0, len(variants))
+	for _, variant := range variants {
+		postgresVariants = append(postgresVariants, PostgresBroadcastVariant{
+			BroadcastID: broadcastID,
+			Variant:     variant.Name,
+			Weight:      variant.Weight,
+		})
+	}
+
+	if err := r.db.WithContext(ctx).Table(BROADCAST_VARIANTS_TABLE_NAME).Create(&postgresVariants).Error; err != nil {
+		return fmt.Errorf("failed to create variants of broadcast '%s': %w", broadcastID, err)
+	}
+
+	return nil
+}
+
+// IncrementVariant adds the sent and failed messages to the counters of the variant
+func (r *BroadcastRepository) IncrementVariant(ctx context.Context, broadcastID, variant string, sent, failed int64) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	err := r.db.WithContext(ctx).Table(BROADCAST_VARIANTS_TABLE_NAME).
+		Where("broadcast_id = ? AND variant = ?", broadcastID, variant).
+		Updates(map[string]interface{}{
+			"sent":   gorm.Expr("sent + ?", sent),
+			"failed": gorm.Expr("failed + ?", failed),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to count messages of variant %s of broadcast '%s': %w", variant, broadcastID, err)
+	}
+
+	return nil
+}
+
+// CreateResponse records the press of the variant button by the user, returns false if it's already recorded
+func (r *BroadcastRepository) CreateResponse(ctx context.Context, broadcastID, variant string, telegramID int64) (bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	result := r.db.WithContext(ctx).Exec(`
+		INSERT INTO broadcast_responses (broadcast_id, variant, telegram_id, created_at)
+		VALUES (?, ?, ?, now())
+		ON CONFLICT (broadcast_id, variant, telegram_id) DO NOTHING`,
+		broadcastID, variant, telegramID,
+	)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to record response of user %d to broadcast '%s': %w", telegramID, broadcastID, result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// GetVariants returns the variants of the broadcast with their counters in the order of the names,
+// an empty slice if the broadcast has none
+func (r *BroadcastRepository) GetVariants(ctx context.Context, broadcastID string) ([]domain.BroadcastVariant, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	variants := []domain.BroadcastVariant{}
+
+	err := r.db.WithContext(ctx).
+		Table(BROADCAST_VARIANTS_TABLE_NAME).
+		Select(`broadcast_variants.variant AS name, broadcast_variants.weight, broadcast_variants.sent,
+			broadcast_variants.failed, COUNT(broadcast_responses.telegram_id) AS responses`).
+		Joins(`LEFT JOIN broadcast_responses ON broadcast_responses.broadcast_id = broadcast_variants.broadcast_id
+			AND broadcast_responses.variant = broadcast_variants.variant`).
+		Where("broadcast_variants.broadcast_id = ?", broadcastID).
+		Group("broadcast_variants.broadcast_id, broadcast_variants.variant").
+		Order("broadcast_variants.variant").
+		Scan(&variants).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variants of broadcast '%s': %w", broadcastID, err)
+	}
+
+	return variants, nil
+}
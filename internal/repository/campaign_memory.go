@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"hr-server/internal/domain"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CampaignMemoryRepository is the in-memory counterpart of CampaignRepository
+type CampaignMemoryRepository struct {
+	db *MemoryDB
+}
+
+func NewCampaignMemoryRepository(db *MemoryDB) *CampaignMemoryRepository {
+	return &CampaignMemoryRepository{db}
+}
+
+func (r *CampaignMemoryRepository) Create(ctx context.Context, name string) (*domain.Campaign, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, campaign := range r.db.campaigns {
+		if campaign.Name == name {
+			return nil, fmt.Errorf("failed to create campaign with name '%s': %w", name, ErrMemoryDuplicate)
+		}
+	}
+
+	now := time.Now()
+	campaign := &domain.Campaign{
+		ID:        r.db.nextID(CAMPAIGNS_TABLE_NAME),
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	r.db.campaigns = append(r.db.campaigns, campaign)
+
+	clone := *campaign
+	return &clone, nil
+}
+
+func (r *CampaignMemoryRepository) GetByID(ctx context.Context, id int) (*domain.Campaign, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, campaign := range r.db.campaigns {
+		if campaign.ID == id {
+			clone := *campaign
+			return &clone, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *CampaignMemoryRepository) GetAll(ctx context.Context) ([]*domain.Campaign, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var campaigns []*domain.Campaign
+	for _, campaign := range r.sorted() {
+		clone := *campaign
+		campaigns = append(campaigns, &clone)
+	}
+
+	return campaigns, nil
+}
+
+// GetStats returns the number of channels and users of every campaign
+func (r *CampaignMemoryRepository) GetStats(ctx context.Context) ([]*domain.GroupStats, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var stats []*domain.GroupStats
+	for _, campaign := range r.sorted() {
+		stats = append(stats, r.db.groupStats(campaign.ID, campaign.Name, func(c *domain.Channel) *int { return c.CampaignID }))
+	}
+
+	return stats, nil
+}
+
+// sorted returns the stored campaigns ordered by name, the lock must be held
+func (r *CampaignMemoryRepository) sorted() []*domain.Campaign {
+	return slices.SortedStableFunc(slices.Values(r.db.campaigns), func(a, b *domain.Campaign) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+}
+
+// groupStats counts the channels of the group and their users, the lock must be held
+func (db *MemoryDB) groupStats(id int, name string, groupID func(*domain.Channel) *int) *domain.GroupStats {
+	stats := &domain.GroupStats{ID: id, Name: name}
+
+	for _, channel := range db.channels {
+		if channelGroupID := groupID(channel); channelGroupID == nil || *channelGroupID != id {
+			continue
+		}
+
+		stats.ChannelsCount++
+		for _, user := range db.users {
+			if user.ChannelID != nil && *user.ChannelID == channel.ID {
+				stats.UsersCount++
+			}
+		}
+	}
+
+	return stats
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hr-server/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const CAMPAIGNS_TABLE_NAME = "campaigns"
+
+type PostgresCampaign struct {
+	ID        int    `gorm:"primaryKey;autoIncrement"`
+	Name      string `gorm:"size:255;uniqueIndex"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (pc PostgresCampaign) TableName() string {
+	return CAMPAIGNS_TABLE_NAME
+}
+
+func (pc PostgresCampaign) ToDomain() *domain.Campaign {
+	return &domain.Campaign{
+		ID:        pc.ID,
+		Name:      pc.Name,
+		CreatedAt: pc.CreatedAt,
+		UpdatedAt: pc.UpdatedAt,
+	}
+}
+
+type CampaignRepository struct {
+	db *gorm.DB
+}
+
+func NewCampaignRepository(db *gorm.DB) *CampaignRepository {
+	return &CampaignRepository{db}
+}
+
+func (r *CampaignRepository) Create(ctx context.Context, name string) (*domain.Campaign, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	postgresCampaign := PostgresCampaign{Name: name}
+	if err := r.db.WithContext(ctx).Table(CAMPAIGNS_TABLE_NAME).Create(&postgresCampaign).Error; err != nil {
+		return nil, fmt.Errorf("failed to create campaign with name '%s': %w", name, err)
+	}
+
+	return postgresCampaign.ToDomain(), nil
+}
+
+func (r *CampaignRepository) GetByID(ctx context.Context, id int) (*domain.Campaign, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var postgresCampaign PostgresCampaign
+
+	if err := r.db.WithContext(ctx).Table(CAMPAIGNS_TABLE_NAME).First(&postgresCampaign, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get campaign by ID %d: %w", id, err)
+	}
+
+	return postgresCampaign.ToDomain(), nil
+}
+
+func (r *CampaignRepository) GetAll(ctx context.Context) ([]*domain.Campaign, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var postgresCampaigns []PostgresCampaign
+
+	if err := r.db.WithContext(ctx).Table(CAMPAIGNS_TABLE_NAME).Order("name").Find(&postgresCampaigns).Error; err != nil {
+		return nil, fmt.Errorf("failed to get all campaigns: %w", err)
+	}
+
+	var campaigns []*domain.Campaign
+	for _, pc := range postgresCampaigns {
+		campaigns = append(campaigns, pc.ToDomain())
+	}
+
+	return campaigns, nil
+}
+
+// GetStats returns the number of channels and users of every campaign
+func (r *CampaignRepository) GetStats(ctx context.Context) ([]*domain.GroupStats, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var stats []*domain.GroupStats
+
+	err := r.db.WithContext(ctx).Table(CAMPAIGNS_TABLE_NAME).
+		Select("campaigns.id, campaigns.name, " +
+			"COUNT(DISTINCT channels.id) as channels_count, COUNT(users.id) as users_count").
+		Joins("LEFT JOIN channels ON channels.campaign_id = campaigns.id").
+		Joins("LEFT JOIN users ON users.channel_id = channels.id").
+		Group("campaigns.id, campaigns.name").
+		Order("campaigns.name").Scan(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get campaign stats: %w", err)
+	}
+
+	return stats, nil
+}
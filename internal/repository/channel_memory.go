@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"hr-server/internal/domain"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ChannelMemoryRepository is the in-memory counterpart of ChannelRepository
+type ChannelMemoryRepository struct {
+	db *MemoryDB
+}
+
+func NewChannelMemoryRepository(db *MemoryDB) *ChannelMemoryRepository {
+	return &ChannelMemoryRepository{db}
+}
+
+func (r *ChannelMemoryRepository) Create(ctx context.Context, channel *domain.Channel) (*domain.Channel, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if r.find(func(c *domain.Channel) bool { return c.Code == channel.Code }) != nil {
+		return nil, fmt.Errorf("failed to create channel with name '%s' and code '%s': %w", channel.Name, channel.Code, ErrMemoryDuplicate)
+	}
+
+	now := time.Now()
+	created := &domain.Channel{
+		ID:         r.db.nextID(CHANNELS_TABLE_NAME),
+		Name:       channel.Name,
+		Code:       channel.Code,
+		LinkType:   channel.LinkType,
+		CampaignID: channel.CampaignID,
+		SourceID:   channel.SourceID,
+		Tags:       append([]string{}, channel.Tags...),
+		ExpiresAt:  channel.ExpiresAt,
+		MaxUsers:   channel.MaxUsers,
+		Timezone:   channel.Timezone,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if created.LinkType == "" {
+		created.LinkType = domain.ChannelLinkTypeStart
+	}
+	r.db.channels = append(r.db.channels, created)
+
+	result := *created
+	result.Tags = slices.Clone(created.Tags)
+
+	return &result, nil
+}
+
+// Update updates the name, grouping, expiry, usage cap and timezone of the channel
+func (r *ChannelMemoryRepository) Update(ctx context.Context, channel *domain.Channel) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	stored := r.find(func(c *domain.Channel) bool { return c.ID == channel.ID })
+	if stored == nil {
+		return nil
+	}
+
+	stored.Name = channel.Name
+	stored.CampaignID = channel.CampaignID
+	stored.SourceID = channel.SourceID
+	stored.ExpiresAt = channel.ExpiresAt
+	stored.MaxUsers = channel.MaxUsers
+	stored.Timezone = channel.Timezone
+	stored.Tags = append([]string{}, channel.Tags...)
+	stored.UpdatedAt = time.Now()
+
+	return nil
+}
+
+func (r *ChannelMemoryRepository) GetByCode(ctx context.Context, code string) (*domain.Channel, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	return r.clone(r.find(func(c *domain.Channel) bool { return c.Code == code })), nil
+}
+
+// GetByName returns channels with exactly this name, names are not unique
+func (r *ChannelMemoryRepository) GetByName(ctx context.Context, name string) ([]*domain.Channel, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var channels []*domain.Channel
+	for _, channel := range r.db.channels {
+		if channel.Name == name {
+			channels = append(channels, r.clone(channel))
+		}
+	}
+
+	return channels, nil
+}
+
+func (r *ChannelMemoryRepository) GetByID(ctx context.Context, id int) (*domain.Channel, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	return r.clone(r.find(func(c *domain.Channel) bool { return c.ID == id })), nil
+}
+
+var channelMemoryKeyset = memoryKeyset[*domain.Channel]{
+	id: func(c *domain.Channel) int { return c.ID },
+	columns: map[string]func(a, b *domain.Channel) int{
+		"created_at": func(a, b *domain.Channel) int { return a.CreatedAt.Compare(b.CreatedAt) },
+		"name":       func(a, b *domain.Channel) int { return strings.Compare(a.Name, b.Name) },
+	},
+	defaultSortBy: "created_at",
+}
+
+// GetPage returns a page of channels matching the filter
+func (r *ChannelMemoryRepository) GetPage(ctx context.Context, filter domain.ChannelFilter, page domain.PageRequest) (*domain.Page[*domain.Channel], error) {
+	r.db.mu.RLock()
+	channels := r.filtered(filter)
+	r.db.mu.RUnlock()
+
+	items, nextCursor, err := channelMemoryKeyset.fetch(channels, page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page of channels: %w", err)
+	}
+
+	return &domain.Page[*domain.Channel]{
+		Items:      items,
+		TotalCount: int64(len(channels)),
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// CountUsers returns the number of users attributed to each of the given channels
+func (r *ChannelMemoryRepository) CountUsers(ctx context.Context, channelIDs ...int) (map[int]int64, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	counts := make(map[int]int64, len(channelIDs))
+	for _, id := range channelIDs {
+		if count := r.countUsers(id); count > 0 {
+			counts[id] = count
+		}
+	}
+
+	return counts, nil
+}
+
+// filtered returns copies of the channels matching the filter, the lock must be held
+func (r *ChannelMemoryRepository) filtered(filter domain.ChannelFilter) []*domain.Channel {
+	channels := []*domain.Channel{}
+	now := time.Now()
+
+	for _, channel := range r.db.channels {
+		switch {
+		case filter.CampaignID != nil && (channel.CampaignID == nil || *channel.CampaignID != *filter.CampaignID),
+			filter.SourceID != nil && (channel.SourceID == nil || *channel.SourceID != *filter.SourceID),
+			!containsAll(channel.Tags, filter.Tags),
+			filter.CreatedAt.From != nil && channel.CreatedAt.Before(*filter.CreatedAt.From),
+			filter.CreatedAt.To != nil && !channel.CreatedAt.Before(*filter.CreatedAt.To),
+			filter.Name != "" && !containsFold(channel.Name, filter.Name):
+			continue
+		}
+
+		expired := channel.IsExpired(now)
+		full := channel.MaxUsers != nil && r.countUsers(channel.ID) >= int64(*channel.MaxUsers)
+
+		switch filter.Status {
+		case domain.ChannelStatusExpired:
+			if !expired {
+				continue
+			}
+		case domain.ChannelStatusFull:
+			if !full {
+				continue
+			}
+		case domain.ChannelStatusActive:
+			if expired || full {
+				continue
+			}
+		}
+
+		channels = append(channels, r.clone(channel))
+	}
+
+	return channels
+}
+
+// countUsers returns the number of users attributed to the channel, the lock must be held
+func (r *ChannelMemoryRepository) countUsers(channelID int) int64 {
+	var count int64
+	for _, user := range r.db.users {
+		if user.ChannelID != nil && *user.ChannelID == channelID {
+			count++
+		}
+	}
+
+	return count
+}
+
+// find returns the first stored channel matching the condition, the lock must be held
+func (r *ChannelMemoryRepository) find(match func(*domain.Channel) bool) *domain.Channel {
+	for _, channel := range r.db.channels {
+		if match(channel) {
+			return channel
+		}
+	}
+
+	return nil
+}
+
+// clone copies the stored channel with the tags sorted as loadTags does
+func (r *ChannelMemoryRepository) clone(channel *domain.Channel) *domain.Channel {
+	if channel == nil {
+		return nil
+	}
+
+	clone := *channel
+	clone.Tags = slices.Sorted(slices.Values(channel.Tags))
+	if clone.Tags == nil {
+		clone.Tags = []string{}
+	}
+
+	return &clone
+}
+
+func containsAll(tags, required []string) bool {
+	for _, tag := range required {
+		if !slices.Contains(tags, tag) {
+			return false
+		}
+	}
+
+	return true
+}
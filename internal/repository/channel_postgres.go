@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"hr-server/internal/domain"
@@ -9,21 +10,41 @@ import (
 	"gorm.io/gorm"
 )
 
-const CHANNELS_TABLE_NAME = "channels"
+const (
+	CHANNELS_TABLE_NAME     = "channels"
+	CHANNEL_TAGS_TABLE_NAME = "channel_tags"
+)
 
 type PostgresChannel struct {
-	ID        int    `gorm:"primaryKey;autoIncrement"`
-	Name      string `gorm:"size:255"`
-	Code      string `gorm:"size:50;uniqueIndex"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID         int    `gorm:"primaryKey;autoIncrement"`
+	Name       string `gorm:"size:255"`
+	Code       string `gorm:"size:50;uniqueIndex"`
+	LinkType   string `gorm:"size:20;not null;default:start"`
+	CampaignID *int   `gorm:"index"`
+	SourceID   *int   `gorm:"index"`
+	ExpiresAt  *time.Time
+	MaxUsers   *int
+	Timezone   string `gorm:"size:64;not null;default:''"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type PostgresChannelTag struct {
+	ChannelID int    `gorm:"primaryKey"`
+	Tag       string `gorm:"primaryKey;size:100;index"`
 }
 
 func NewPostgresChannel(channel *domain.Channel) PostgresChannel {
 	return PostgresChannel{
-		ID:   channel.ID,
-		Name: channel.Name,
-		Code: channel.Code,
+		ID:         channel.ID,
+		Name:       channel.Name,
+		Code:       channel.Code,
+		LinkType:   channel.LinkType,
+		CampaignID: channel.CampaignID,
+		SourceID:   channel.SourceID,
+		ExpiresAt:  channel.ExpiresAt,
+		MaxUsers:   channel.MaxUsers,
+		Timezone:   channel.Timezone,
 	}
 }
 
@@ -33,77 +54,324 @@ func (pc PostgresChannel) TableName() string {
 
 func (pc PostgresChannel) ToDomain() *domain.Channel {
 	return &domain.Channel{
-		ID:        pc.ID,
-		Name:      pc.Name,
-		Code:      pc.Code,
-		CreatedAt: pc.CreatedAt,
-		UpdatedAt: pc.UpdatedAt,
+		ID:         pc.ID,
+		Name:       pc.Name,
+		Code:       pc.Code,
+		LinkType:   pc.LinkType,
+		CampaignID: pc.CampaignID,
+		SourceID:   pc.SourceID,
+		Tags:       []string{},
+		ExpiresAt:  pc.ExpiresAt,
+		MaxUsers:   pc.MaxUsers,
+		Timezone:   pc.Timezone,
+		CreatedAt:  pc.CreatedAt,
+		UpdatedAt:  pc.UpdatedAt,
 	}
 }
 
+func (pct PostgresChannelTag) TableName() string {
+	return CHANNEL_TAGS_TABLE_NAME
+}
+
 type ChannelRepository struct {
 	db *gorm.DB
 }
 
 func NewChannelRepository(db *gorm.DB) *ChannelRepository {
-	if err := db.AutoMigrate(PostgresChannel{}); err != nil {
-		panic(err)
-	}
-
 	return &ChannelRepository{db}
 }
 
-func (r *ChannelRepository) Create(name, code string) (*domain.Channel, error) {
-	channel := &domain.Channel{
-		Name: name,
-		Code: code,
-	}
+func (r *ChannelRepository) Create(ctx context.Context, channel *domain.Channel) (*domain.Channel, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
 
 	postgresChannel := NewPostgresChannel(channel)
-	if err := r.db.Table(CHANNELS_TABLE_NAME).Create(&postgresChannel).Error; err != nil {
-		return nil, fmt.Errorf("failed to create channel with name '%s' and code '%s': %w", name, code, err)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table(CHANNELS_TABLE_NAME).Create(&postgresChannel).Error; err != nil {
+			return err
+		}
+
+		return replaceTags(tx, postgresChannel.ID, channel.Tags)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create channel with name '%s' and code '%s': %w", channel.Name, channel.Code, err)
 	}
 
-	return postgresChannel.ToDomain(), nil
+	created := postgresChannel.ToDomain()
+	created.Tags = append(created.Tags, channel.Tags...)
+
+	return created, nil
 }
 
-func (r *ChannelRepository) GetByCode(code string) (*domain.Channel, error) {
+// Update updates the name, grouping, expiry, usage cap and timezone of the channel
+func (r *ChannelRepository) Update(ctx context.Context, channel *domain.Channel) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Table(CHANNELS_TABLE_NAME).Where("id = ?", channel.ID).Updates(map[string]interface{}{
+			"name":        channel.Name,
+			"campaign_id": channel.CampaignID,
+			"source_id":   channel.SourceID,
+			"expires_at":  channel.ExpiresAt,
+			"max_users":   channel.MaxUsers,
+			"timezone":    channel.Timezone,
+			"updated_at":  time.Now(),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return replaceTags(tx, channel.ID, channel.Tags)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update channel %d: %w", channel.ID, err)
+	}
+
+	return nil
+}
+
+func (r *ChannelRepository) GetByCode(ctx context.Context, code string) (*domain.Channel, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	var postgresChannel PostgresChannel
 
-	if err := r.db.Table(CHANNELS_TABLE_NAME).First(&postgresChannel, "code = ?", code).Error; err != nil {
+	if err := r.db.WithContext(ctx).Table(CHANNELS_TABLE_NAME).First(&postgresChannel, "code = ?", code).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("failed to get channel by code '%s': %w", code, err)
 	}
 
-	return postgresChannel.ToDomain(), nil
+	channel := postgresChannel.ToDomain()
+	if err := r.loadTags(ctx, channel); err != nil {
+		return nil, err
+	}
+
+	return channel, nil
 }
 
-func (r *ChannelRepository) GetByID(id int) (*domain.Channel, error) {
+// GetByName returns channels with exactly this name, names are not unique
+func (r *ChannelRepository) GetByName(ctx context.Context, name string) ([]*domain.Channel, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var postgresChannels []PostgresChannel
+
+	if err := r.db.WithContext(ctx).Table(CHANNELS_TABLE_NAME).Where("name = ?", name).Find(&postgresChannels).Error; err != nil {
+		return nil, fmt.Errorf("failed to get channels by name '%s': %w", name, err)
+	}
+
+	var channels []*domain.Channel
+	for _, pc := range postgresChannels {
+		channels = append(channels, pc.ToDomain())
+	}
+
+	return channels, nil
+}
+
+func (r *ChannelRepository) GetByID(ctx context.Context, id int) (*domain.Channel, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	var postgresChannel PostgresChannel
 
-	if err := r.db.Table(CHANNELS_TABLE_NAME).First(&postgresChannel, "id = ?", id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Table(CHANNELS_TABLE_NAME).First(&postgresChannel, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("failed to get channel by ID %d: %w", id, err)
 	}
 
-	return postgresChannel.ToDomain(), nil
+	channel := postgresChannel.ToDomain()
+	if err := r.loadTags(ctx, channel); err != nil {
+		return nil, err
+	}
+
+	return channel, nil
 }
 
-func (r *ChannelRepository) GetAll() ([]*domain.Channel, error) {
-	var postgresChannels []PostgresChannel
+var channelKeyset = keyset[PostgresChannel]{
+	idColumn: "channels.id",
+	id:       func(pc PostgresChannel) int { return pc.ID },
+	columns: map[string]sortColumn[PostgresChannel]{
+		"created_at": {
+			column: "channels.created_at",
+			cast:   "timestamptz",
+			value:  func(pc PostgresChannel) string { return pc.CreatedAt.Format(time.RFC3339Nano) },
+		},
+		"name": {
+			column: "channels.name",
+			cast:   "text",
+			value:  func(pc PostgresChannel) string { return pc.Name },
+		},
+	},
+	defaultSortBy: "created_at",
+}
 
-	if err := r.db.Table(CHANNELS_TABLE_NAME).Find(&postgresChannels).Error; err != nil {
-		return nil, fmt.Errorf("failed to get all channels: %w", err)
+// GetPage returns a page of channels matching the filter
+func (r *ChannelRepository) GetPage(ctx context.Context, filter domain.ChannelFilter, page domain.PageRequest) (*domain.Page[*domain.Channel], error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var total int64
+	if err := r.filtered(ctx, filter).Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count channels: %w", err)
 	}
 
-	var channels []*domain.Channel
+	postgresChannels, nextCursor, err := channelKeyset.fetch(r.filtered(ctx, filter), page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page of channels: %w", err)
+	}
+
+	channels := make([]*domain.Channel, 0, len(postgresChannels))
 	for _, pc := range postgresChannels {
 		channels = append(channels, pc.ToDomain())
 	}
 
-	return channels, nil
+	if err := r.loadTags(ctx, channels...); err != nil {
+		return nil, err
+	}
+
+	return &domain.Page[*domain.Channel]{
+		Items:      channels,
+		TotalCount: total,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+const channelUsersCountSQL = "(SELECT COUNT(*) FROM users WHERE users.channel_id = channels.id)"
+
+// filtered returns a new channels query with the filter applied
+func (r *ChannelRepository) filtered(ctx context.Context, filter domain.ChannelFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Table(CHANNELS_TABLE_NAME)
+
+	if filter.CampaignID != nil {
+		query = query.Where("channels.campaign_id = ?", *filter.CampaignID)
+	}
+
+	if filter.SourceID != nil {
+		query = query.Where("channels.source_id = ?", *filter.SourceID)
+	}
+
+	if len(filter.Tags) > 0 {
+		query = query.Where(
+			"channels.id IN (?)",
+			r.db.WithContext(ctx).Table(CHANNEL_TAGS_TABLE_NAME).
+				Select("channel_id").
+				Where("tag IN ?", filter.Tags).
+				Group("channel_id").
+				Having("COUNT(DISTINCT tag) = ?", len(filter.Tags)),
+		)
+	}
+
+	if filter.CreatedAt.From != nil {
+		query = query.Where("channels.created_at >= ?", *filter.CreatedAt.From)
+	}
+
+	if filter.CreatedAt.To != nil {
+		query = query.Where("channels.created_at < ?", *filter.CreatedAt.To)
+	}
+
+	if filter.Name != "" {
+		query = query.Where("channels.name ILIKE ?", likePattern(filter.Name))
+	}
+
+	now := time.Now()
+	switch filter.Status {
+	case domain.ChannelStatusExpired:
+		query = query.Where("channels.expires_at IS NOT NULL AND channels.expires_at <= ?", now)
+	case domain.ChannelStatusFull:
+		query = query.Where("channels.max_users IS NOT NULL AND " + channelUsersCountSQL + " >= channels.max_users")
+	case domain.ChannelStatusActive:
+		query = query.
+			Where("channels.expires_at IS NULL OR channels.expires_at > ?", now).
+			Where("channels.max_users IS NULL OR " + channelUsersCountSQL + " < channels.max_users")
+	}
+
+	return query
+}
+
+// CountUsers returns the number of users attributed to each of the given channels
+func (r *ChannelRepository) CountUsers(ctx context.Context, channelIDs ...int) (map[int]int64, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	counts := make(map[int]int64, len(channelIDs))
+	if len(channelIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		ChannelID  int
+		UsersCount int64
+	}
+
+	err := r.db.WithContext(ctx).Table(USERS_TABLE_NAME).
+		Select("channel_id, COUNT(*) as users_count").
+		Where("channel_id IN ?", channelIDs).
+		Group("channel_id").Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count users of channels: %w", err)
+	}
+
+	for _, row := range rows {
+		counts[row.ChannelID] = row.UsersCount
+	}
+
+	return counts, nil
+}
+
+// loadTags fills the tags of the given channels with a single query
+func (r *ChannelRepository) loadTags(ctx context.Context, channels ...*domain.Channel) error {
+	if len(channels) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*domain.Channel, len(channels))
+	ids := make([]int, 0, len(channels))
+	for _, channel := range channels {
+		byID[channel.ID] = channel
+		ids = append(ids, channel.ID)
+	}
+
+	var postgresTags []PostgresChannelTag
+	err := r.db.WithContext(ctx).Table(CHANNEL_TAGS_TABLE_NAME).
+		Where("channel_id IN ?", ids).
+		Order("tag").Find(&postgresTags).Error
+	if err != nil {
+		return fmt.Errorf("failed to get channel tags: %w", err)
+	}
+
+	for _, pt := range postgresTags {
+		if channel, ok := byID[pt.ChannelID]; ok {
+			channel.Tags = append(channel.Tags, pt.Tag)
+		}
+	}
+
+	return nil
+}
+
+// replaceTags replaces all tags of the channel within the transaction
+func replaceTags(tx *gorm.DB, channelID int, tags []string) error {
+	if err := tx.Table(CHANNEL_TAGS_TABLE_NAME).Where("channel_id = ?", channelID).Delete(&PostgresChannelTag{}).Error; err != nil {
+		return fmt.Errorf("failed to delete tags of channel %d: %w", channelID, err)
+	}
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	postgresTags := make([]PostgresChannelTag, 0, len(tags))
+	for _, tag := range tags {
+		postgresTags = append(postgresTags, PostgresChannelTag{ChannelID: channelID, Tag: tag})
+	}
+
+	if err := tx.Table(CHANNEL_TAGS_TABLE_NAME).Create(&postgresTags).Error; err != nil {
+		return fmt.Errorf("failed to create tags of channel %d: %w", channelID, err)
+	}
+
+	return nil
 }
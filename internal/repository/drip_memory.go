@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"hr-server/internal/domain"
+	"slices"
+	"time"
+)
+
+// DripMemoryRepository is the in-memory counterpart of DripRepository
+type DripMemoryRepository struct {
+	db *MemoryDB
+}
+
+func NewDripMemoryRepository(db *MemoryDB) *DripMemoryRepository {
+	return &DripMemoryRepository{db}
+}
+
+func (r *DripMemoryRepository) CreateSequence(ctx context.Context, sequence *domain.DripSequence) (*domain.DripSequence, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+	created := &domain.DripSequence{
+		ID:        r.db.nextID(DRIP_SEQUENCES_TABLE_NAME),
+		Name:      sequence.Name,
+		ChannelID: sequence.ChannelID,
+		Active:    sequence.Active,
+		Steps:     make([]domain.DripStep, 0, len(sequence.Steps)),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	for i, step := range sequence.Steps {
+		step.Position = i
+		created.Steps = append(created.Steps, step)
+	}
+	r.db.dripSequences = append(r.db.dripSequences, created)
+
+	return r.withEnrollments(created), nil
+}
+
+func (r *DripMemoryRepository) GetSequences(ctx context.Context) ([]*domain.DripSequence, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	sequences := make([]*domain.DripSequence, 0, len(r.db.dripSequences))
+	for _, sequence := range r.db.dripSequences {
+		sequences = append(sequences, r.withEnrollments(sequence))
+	}
+
+	return sequences, nil
+}
+
+func (r *DripMemoryRepository) GetSequence(ctx context.Context, id int) (*domain.DripSequence, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	if sequence := r.findSequence(id); sequence != nil {
+		return r.withEnrollments(sequence), nil
+	}
+
+	return nil, nil
+}
+
+func (r *DripMemoryRepository) SetSequenceActive(ctx context.Context, id int, active bool) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	sequence := r.findSequence(id)
+	if sequence == nil {
+		return false, nil
+	}
+
+	sequence.Active = active
+	sequence.UpdatedAt = time.Now()
+
+	return true, nil
+}
+
+func (r *DripMemoryRepository) DeleteSequence(ctx context.Context, id int) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if r.findSequence(id) == nil {
+		return false, nil
+	}
+
+	r.db.dripSequences = slices.DeleteFunc(r.db.dripSequences, func(s *domain.DripSequence) bool { return s.ID == id })
+	r.db.dripEnrollments = slices.DeleteFunc(r.db.dripEnrollments, func(e *domain.DripEnrollment) bool { return e.SequenceID == id })
+
+	return true, nil
+}
+
+func (r *DripMemoryRepository) Enroll(ctx context.Context, telegramID int64, channelID *int, enrolledAt time.Time) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	enrolled := 0
+	for _, sequence := range r.db.dripSequences {
+		if !sequence.Active || len(sequence.Steps) == 0 {
+			continue
+		}
+		if sequence.ChannelID != nil && (channelID == nil || *sequence.ChannelID != *channelID) {
+			continue
+		}
+
+		exists := slices.ContainsFunc(r.db.dripEnrollments, func(e *domain.DripEnrollment) bool {
+			return e.SequenceID == sequence.ID && e.TelegramID == telegramID
+		})
+		if exists {
+			continue
+		}
+
+		nextRunAt := enrolledAt.Add(sequence.Steps[0].Delay())
+		r.db.dripEnrollments = append(r.db.dripEnrollments, &domain.DripEnrollment{
+			ID:         r.db.nextID(DRIP_ENROLLMENTS_TABLE_NAME),
+			SequenceID: sequence.ID,
+			TelegramID: telegramID,
+			Status:     domain.DripEnrollmentActive,
+			NextRunAt:  &nextRunAt,
+			EnrolledAt: enrolledAt,
+		})
+		enrolled++
+	}
+
+	return enrolled, nil
+}
+
+// ClaimDueEnrollments claims the due enrollments until leaseUntil like the Postgres repository
+func (r *DripMemoryRepository) ClaimDueEnrollments(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*domain.DripEnrollment, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	due := []*domain.DripEnrollment{}
+	for _, enrollment := range r.db.dripEnrollments {
+		if enrollment.Status != domain.DripEnrollmentActive || enrollment.NextRunAt == nil || enrollment.NextRunAt.After(now) {
+			continue
+		}
+		if sequence := r.findSequence(enrollment.SequenceID); sequence == nil || !sequence.Active {
+			continue
+		}
+
+		due = append(due, enrollment)
+	}
+
+	slices.SortStableFunc(due, func(a, b *domain.DripEnrollment) int {
+		return cmp.Or(a.NextRunAt.Compare(*b.NextRunAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	enrollments := make([]*domain.DripEnrollment, 0, min(limit, len(due)))
+	for _, enrollment := range due[:min(limit, len(due))] {
+		lease := leaseUntil
+		enrollment.NextRunAt = &lease
+
+		clone := *enrollment
+		enrollments = append(enrollments, &clone)
+	}
+
+	return enrollments, nil
+}
+
+func (r *DripMemoryRepository) UpdateEnrollment(ctx context.Context, enrollment *domain.DripEnrollment, position int, outcome string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, stored := range r.db.dripEnrollments {
+		if stored.ID == enrollment.ID {
+			stored.Status = enrollment.Status
+			stored.NextStep = enrollment.NextStep
+			stored.NextRunAt = enrollment.NextRunAt
+		}
+	}
+
+	sequence := r.findSequence(enrollment.SequenceID)
+	if sequence == nil || position < 0 || position >= len(sequence.Steps) {
+		return nil
+	}
+
+	step := &sequence.Steps[position]
+	switch outcome {
+	case domain.DripStepSent:
+		step.Sent++
+	case domain.DripStepSkipped:
+		step.Skipped++
+	case domain.DripStepFailed:
+		step.Failed++
+	}
+
+	return nil
+}
+
+func (r *DripMemoryRepository) RecordReply(ctx context.Context, telegramID int64, at time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, enrollment := range r.db.dripEnrollments {
+		if enrollment.TelegramID == telegramID && enrollment.Status == domain.DripEnrollmentActive {
+			repliedAt := at
+			enrollment.RepliedAt = &repliedAt
+		}
+	}
+
+	return nil
+}
+
+// findSequence returns the stored sequence, the lock must be held
+func (r *DripMemoryRepository) findSequence(id int) *domain.DripSequence {
+	for _, sequence := range r.db.dripSequences {
+		if sequence.ID == id {
+			return sequence
+		}
+	}
+
+	return nil
+}
+
+// withEnrollments returns a copy of the sequence with the enrollment counts, the lock must be held
+func (r *DripMemoryRepository) withEnrollments(sequence *domain.DripSequence) *domain.DripSequence {
+	clone := *sequence
+	clone.Steps = slices.Clone(sequence.Steps)
+
+	for _, enrollment := range r.db.dripEnrollments {
+		if enrollment.SequenceID != sequence.ID {
+			continue
+		}
+		switch enrollment.Status {
+		case domain.DripEnrollmentActive:
+			clone.Enrollments.Active++
+		case domain.DripEnrollmentCompleted:
+			clone.Enrollments.Completed++
+		case domain.DripEnrollmentCancelled:
+			clone.Enrollments.Cancelled++
+		}
+	}
+
+	return &clone
+}
@@ -0,0 +1,390 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"hr-server/internal/domain"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DRIP_SEQUENCES_TABLE_NAME   = "drip_sequences"
+	DRIP_STEPS_TABLE_NAME       = "drip_steps"
+	DRIP_ENROLLMENTS_TABLE_NAME = "drip_enrollments"
+)
+
+// dripStepCounters maps the outcomes of the steps to their counter columns
+var dripStepCounters = map[string]string{
+	domain.DripStepSent:    "sent",
+	domain.DripStepSkipped: "skipped",
+	domain.DripStepFailed:  "failed",
+}
+
+type PostgresDripSequence struct {
+	ID        int    `gorm:"primaryKey;autoIncrement"`
+	Name      string `gorm:"size:255"`
+	ChannelID *int   `gorm:"index"`
+	Active    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (ps PostgresDripSequence) TableName() string {
+	return DRIP_SEQUENCES_TABLE_NAME
+}
+
+func (ps PostgresDripSequence) ToDomain() *domain.DripSequence {
+	return &domain.DripSequence{
+		ID:        ps.ID,
+		Name:      ps.Name,
+		ChannelID: ps.ChannelID,
+		Active:    ps.Active,
+		Steps:     []domain.DripStep{},
+		CreatedAt: ps.CreatedAt,
+		UpdatedAt: ps.UpdatedAt,
+	}
+}
+
+type PostgresDripStep struct {
+	SequenceID   int `gorm:"primaryKey"`
+	Position     int `gorm:"primaryKey"`
+	DelayMinutes int
+	Message      string `gorm:"type:text"`
+	Condition    string `gorm:"size:20"`
+	Sent         int64
+	Skipped      int64
+	Failed       int64
+}
+
+func (ps PostgresDripStep) TableName() string {
+	return DRIP_STEPS_TABLE_NAME
+}
+
+func (ps PostgresDripStep) ToDomain() domain.DripStep {
+	return domain.DripStep{
+		Position:     ps.Position,
+		DelayMinutes: ps.DelayMinutes,
+		Message:      ps.Message,
+		Condition:    ps.Condition,
+		Sent:         ps.Sent,
+		Skipped:      ps.Skipped,
+		Failed:       ps.Failed,
+	}
+}
+
+type PostgresDripEnrollment struct {
+	ID         int    `gorm:"primaryKey;autoIncrement"`
+	SequenceID int    `gorm:"uniqueIndex:idx_drip_enrollments_sequence_user"`
+	TelegramID int64  `gorm:"uniqueIndex:idx_drip_enrollments_sequence_user;index"`
+	Status     string `gorm:"size:20"`
+	NextStep   int
+	NextRunAt  *time.Time `gorm:"index"`
+	RepliedAt  *time.Time
+	EnrolledAt time.Time
+}
+
+func (pe PostgresDripEnrollment) TableName() string {
+	return DRIP_ENROLLMENTS_TABLE_NAME
+}
+
+func (pe PostgresDripEnrollment) ToDomain() *domain.DripEnrollment {
+	return &domain.DripEnrollment{
+		ID:         pe.ID,
+		SequenceID: pe.SequenceID,
+		TelegramID: pe.TelegramID,
+		Status:     pe.Status,
+		NextStep:   pe.NextStep,
+		NextRunAt:  pe.NextRunAt,
+		RepliedAt:  pe.RepliedAt,
+		EnrolledAt: pe.EnrolledAt,
+	}
+}
+
+type DripRepository struct {
+	db *gorm.DB
+}
+
+func NewDripRepository(db *gorm.DB) *DripRepository {
+	return &DripRepository{db}
+}
+
+// CreateSequence stores the sequence with its steps in one transaction
+func (r *DripRepository) CreateSequence(ctx context.Context, sequence *domain.DripSequence) (*domain.DripSequence, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	postgresSequence := PostgresDripSequence{
+		Name:      sequence.Name,
+		ChannelID: sequence.ChannelID,
+		Active:    sequence.Active,
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table(DRIP_SEQUENCES_TABLE_NAME).Create(&postgresSequence).Error; err != nil {
+			return err
+		}
+
+		postgresSteps := make([]PostgresDripStep, 0, len(sequence.Steps))
+		for i, step := range sequence.Steps {
+			postgresSteps = append(postgresSteps, PostgresDripStep{
+				SequenceID:   postgresSequence.ID,
+				Position:     i,
+				DelayMinutes: step.DelayMinutes,
+				Message:      step.Message,
+				Condition:    step.Condition,
+			})
+		}
+
+		return tx.Table(DRIP_STEPS_TABLE_NAME).Create(&postgresSteps).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create drip sequence '%s': %w", sequence.Name, err)
+	}
+
+	created := postgresSequence.ToDomain()
+	for i, step := range sequence.Steps {
+		step.Position = i
+		created.Steps = append(created.Steps, step)
+	}
+
+	return created, nil
+}
+
+// GetSequences returns all drip sequences with their steps and enrollment counts
+func (r *DripRepository) GetSequences(ctx context.Context) ([]*domain.DripSequence, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var postgresSequences []PostgresDripSequence
+	if err := r.db.WithContext(ctx).Table(DRIP_SEQUENCES_TABLE_NAME).Order("id").Find(&postgresSequences).Error; err != nil {
+		return nil, fmt.Errorf("failed to get drip sequences: %w", err)
+	}
+
+	sequences := make([]*domain.DripSequence, 0, len(postgresSequences))
+	for _, ps := range postgresSequences {
+		sequences = append(sequences, ps.ToDomain())
+	}
+
+	if err := r.fillSequences(ctx, sequences); err != nil {
+		return nil, err
+	}
+
+	return sequences, nil
+}
+
+// GetSequence returns the drip sequence with its steps and enrollment counts, nil if it doesn't exist
+func (r *DripRepository) GetSequence(ctx context.Context, id int) (*domain.DripSequence, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var postgresSequence PostgresDripSequence
+	if err := r.db.WithContext(ctx).Table(DRIP_SEQUENCES_TABLE_NAME).First(&postgresSequence, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get drip sequence %d: %w", id, err)
+	}
+
+	sequence := postgresSequence.ToDomain()
+	if err := r.fillSequences(ctx, []*domain.DripSequence{sequence}); err != nil {
+		return nil, err
+	}
+
+	return sequence, nil
+}
+
+// fillSequences loads the steps and the enrollment counts of the sequences
+func (r *DripRepository) fillSequences(ctx context.Context, sequences []*domain.DripSequence) error {
+	if len(sequences) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*domain.DripSequence, len(sequences))
+	ids := make([]int, 0, len(sequences))
+	for _, sequence := range sequences {
+		byID[sequence.ID] = sequence
+		ids = append(ids, sequence.ID)
+	}
+
+	var postgresSteps []PostgresDripStep
+	err := r.db.WithContext(ctx).Table(DRIP_STEPS_TABLE_NAME).
+		Where("sequence_id IN ?", ids).
+		Order("sequence_id, position").
+		Find(&postgresSteps).Error
+	if err != nil {
+		return fmt.Errorf("failed to get drip steps: %w", err)
+	}
+
+	for _, ps := range postgresSteps {
+		byID[ps.SequenceID].Steps = append(byID[ps.SequenceID].Steps, ps.ToDomain())
+	}
+
+	var counts []struct {
+		SequenceID int
+		Status     string
+		Count      int64
+	}
+	err = r.db.WithContext(ctx).Table(DRIP_ENROLLMENTS_TABLE_NAME).
+		Select("sequence_id, status, COUNT(*) AS count").
+		Where("sequence_id IN ?", ids).
+		Group("sequence_id, status").
+		Scan(&counts).Error
+	if err != nil {
+		return fmt.Errorf("failed to count drip enrollments: %w", err)
+	}
+
+	for _, count := range counts {
+		enrollments := &byID[count.SequenceID].Enrollments
+		switch count.Status {
+		case domain.DripEnrollmentActive:
+			enrollments.Active = count.Count
+		case domain.DripEnrollmentCompleted:
+			enrollments.Completed = count.Count
+		case domain.DripEnrollmentCancelled:
+			enrollments.Cancelled = count.Count
+		}
+	}
+
+	return nil
+}
+
+// SetSequenceActive enables or disables the sequence, returns false if it doesn't exist
+func (r *DripRepository) SetSequenceActive(ctx context.Context, id int, active bool) (bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	result := r.db.WithContext(ctx).Table(DRIP_SEQUENCES_TABLE_NAME).Where("id = ?", id).Updates(map[string]interface{}{
+		"active":     active,
+		"updated_at": time.Now(),
+	})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update drip sequence %d: %w", id, result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteSequence deletes the sequence with its steps and enrollments, returns false if it doesn't exist
+func (r *DripRepository) DeleteSequence(ctx context.Context, id int) (bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	result := r.db.WithContext(ctx).Table(DRIP_SEQUENCES_TABLE_NAME).Where("id = ?", id).Delete(&PostgresDripSequence{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete drip sequence %d: %w", id, result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// Enroll enrolls the user into the active sequences of the channel and the ones without a channel,
+// the first step is due after its delay. A user is enrolled into a sequence once. Returns the new enrollments.
+func (r *DripRepository) Enroll(ctx context.Context, telegramID int64, channelID *int, enrolledAt time.Time) (int, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	result := r.db.WithContext(ctx).Exec(`
+		INSERT INTO drip_enrollments (sequence_id, telegram_id, status, next_step, next_run_at, enrolled_at)
+		SELECT drip_sequences.id, ?, ?, 0, ?::timestamptz + make_interval(mins => drip_steps.delay_minutes), ?
+		FROM drip_sequences
+		JOIN drip_steps ON drip_steps.sequence_id = drip_sequences.id AND drip_steps.position = 0
+		WHERE drip_sequences.active AND (drip_sequences.channel_id IS NULL OR drip_sequences.channel_id = ?)
+		ON CONFLICT (sequence_id, telegram_id) DO NOTHING`,
+		telegramID, domain.DripEnrollmentActive, enrolledAt, enrolledAt, channelID,
+	)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to enroll user %d into drip sequences: %w", telegramID, result.Error)
+	}
+
+	return int(result.RowsAffected), nil
+}
+
+// ClaimDueEnrollments claims the active enrollments of the active sequences whose next step is due, the oldest first.
+// The claimed enrollments are due again at leaseUntil, so concurrent runs and other replicas skip them
+// and an enrollment left by a crash is retried once the lease ends.
+func (r *DripRepository) ClaimDueEnrollments(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*domain.DripEnrollment, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var postgresEnrollments []PostgresDripEnrollment
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE drip_enrollments SET next_run_at = ?
+		WHERE id IN (
+			SELECT drip_enrollments.id FROM drip_enrollments
+			JOIN drip_sequences ON drip_sequences.id = drip_enrollments.sequence_id
+			WHERE drip_enrollments.status = ? AND drip_enrollments.next_run_at <= ? AND drip_sequences.active
+			ORDER BY drip_enrollments.next_run_at, drip_enrollments.id
+			LIMIT ?
+			FOR UPDATE OF drip_enrollments SKIP LOCKED
+		)
+		RETURNING *`,
+		leaseUntil, domain.DripEnrollmentActive, now, limit,
+	).Scan(&postgresEnrollments).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due drip enrollments: %w", err)
+	}
+
+	enrollments := make([]*domain.DripEnrollment, 0, len(postgresEnrollments))
+	for _, pe := range postgresEnrollments {
+		enrollments = append(enrollments, pe.ToDomain())
+	}
+
+	// RETURNING doesn't keep the order of the subquery
+	slices.SortFunc(enrollments, func(a, b *domain.DripEnrollment) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return enrollments, nil
+}
+
+// UpdateEnrollment stores the progress of the enrollment and counts the outcome of the step at the position,
+// an empty outcome isn't counted
+func (r *DripRepository) UpdateEnrollment(ctx context.Context, enrollment *domain.DripEnrollment, position int, outcome string) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Table(DRIP_ENROLLMENTS_TABLE_NAME).Where("id = ?", enrollment.ID).Updates(map[string]interface{}{
+			"status":      enrollment.Status,
+			"next_step":   enrollment.NextStep,
+			"next_run_at": enrollment.NextRunAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		counter, ok := dripStepCounters[outcome]
+		if !ok {
+			return nil
+		}
+
+		return tx.Table(DRIP_STEPS_TABLE_NAME).
+			Where("sequence_id = ? AND position = ?", enrollment.SequenceID, position).
+			Update(counter, gorm.Expr(counter+" + 1")).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update drip enrollment %d: %w", enrollment.ID, err)
+	}
+
+	return nil
+}
+
+// RecordReply sets the reply time of the active enrollments of the user
+func (r *DripRepository) RecordReply(ctx context.Context, telegramID int64, at time.Time) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	err := r.db.WithContext(ctx).Table(DRIP_ENROLLMENTS_TABLE_NAME).
+		Where("telegram_id = ? AND status = ?", telegramID, domain.DripEnrollmentActive).
+		Update("replied_at", at).Error
+	if err != nil {
+		return fmt.Errorf("failed to record reply of user %d: %w", telegramID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// uniqueViolationCode is the Postgres error code of a unique constraint violation
+const uniqueViolationCode = "23505"
+
+// IsDuplicate reports whether the error is a unique constraint violation of Postgres or of the in-memory repositories
+func IsDuplicate(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == uniqueViolationCode
+	}
+
+	return errors.Is(err, ErrMemoryDuplicate)
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsDuplicate(t *testing.T) {
+	assert.True(t, IsDuplicate(fmt.Errorf("failed to create campaign: %w", &pgconn.PgError{Code: "23505"})))
+	assert.True(t, IsDuplicate(fmt.Errorf("failed to create source: %w", ErrMemoryDuplicate)))
+	assert.False(t, IsDuplicate(fmt.Errorf("failed to create campaign: %w", &pgconn.PgError{Code: "23503"})))
+	assert.False(t, IsDuplicate(errors.New("connection refused")))
+	assert.False(t, IsDuplicate(nil))
+}
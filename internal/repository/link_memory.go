@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"hr-server/internal/domain"
+	"time"
+)
+
+// LinkMemoryRepository is the in-memory counterpart of LinkRepository
+type LinkMemoryRepository struct {
+	db *MemoryDB
+}
+
+func NewLinkMemoryRepository(db *MemoryDB) *LinkMemoryRepository {
+	return &LinkMemoryRepository{db}
+}
+
+func (r *LinkMemoryRepository) CreateLinks(ctx context.Context, links []*domain.TrackedLink) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, link := range links {
+		link.ID = r.db.nextID(TRACKED_LINKS_TABLE_NAME)
+		link.CreatedAt = time.Now()
+
+		created := *link
+		r.db.trackedLinks = append(r.db.trackedLinks, &created)
+	}
+
+	return nil
+}
+
+func (r *LinkMemoryRepository) GetByID(ctx context.Context, id int) (*domain.TrackedLink, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, link := range r.db.trackedLinks {
+		if link.ID == id {
+			clone := *link
+			return &clone, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *LinkMemoryRepository) CreateClick(ctx context.Context, click *domain.LinkClick) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	click.ID = r.db.nextID(LINK_CLICKS_TABLE_NAME)
+	click.CreatedAt = time.Now()
+
+	created := *click
+	r.db.linkClicks = append(r.db.linkClicks, &created)
+
+	return nil
+}
+
+func (r *LinkMemoryRepository) GetBroadcastStats(ctx context.Context, broadcastID string) (*domain.BroadcastClickStats, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	stats := &domain.BroadcastClickStats{
+		BroadcastID: broadcastID,
+		Links:       []*domain.LinkStats{},
+	}
+	users := make(map[int64]struct{})
+
+	for _, link := range r.db.trackedLinks {
+		if link.BroadcastID != broadcastID {
+			continue
+		}
+
+		linkStats := &domain.LinkStats{LinkID: link.ID, URL: link.URL, Variant: link.Variant}
+		linkUsers := make(map[int64]struct{})
+		for _, click := range r.db.linkClicks {
+			if click.LinkID == link.ID {
+				linkStats.Clicks++
+				linkUsers[click.TelegramID] = struct{}{}
+				users[click.TelegramID] = struct{}{}
+			}
+		}
+		linkStats.UniqueUsers = int64(len(linkUsers))
+
+		stats.Clicks += linkStats.Clicks
+		stats.Links = append(stats.Links, linkStats)
+	}
+	stats.UniqueUsers = int64(len(users))
+
+	return stats, nil
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hr-server/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	TRACKED_LINKS_TABLE_NAME = "tracked_links"
+	LINK_CLICKS_TABLE_NAME   = "link_clicks"
+)
+
+type PostgresTrackedLink struct {
+	ID          int    `gorm:"primaryKey;autoIncrement"`
+	BroadcastID string `gorm:"size:32;index"`
+	Variant     string `gorm:"size:1"`
+	URL         string `gorm:"type:text"`
+	CreatedAt   time.Time
+}
+
+func NewPostgresTrackedLink(link *domain.TrackedLink) PostgresTrackedLink {
+	return PostgresTrackedLink{
+		ID:          link.ID,
+		BroadcastID: link.BroadcastID,
+		Variant:     link.Variant,
+		URL:         link.URL,
+	}
+}
+
+func (pl PostgresTrackedLink) TableName() string {
+	return TRACKED_LINKS_TABLE_NAME
+}
+
+func (pl PostgresTrackedLink) ToDomain() *domain.TrackedLink {
+	return &domain.TrackedLink{
+		ID:          pl.ID,
+		BroadcastID: pl.BroadcastID,
+		Variant:     pl.Variant,
+		URL:         pl.URL,
+		CreatedAt:   pl.CreatedAt,
+	}
+}
+
+type PostgresLinkClick struct {
+	ID         int    `gorm:"primaryKey;autoIncrement"`
+	LinkID     int    `gorm:"index"`
+	TelegramID int64  `gorm:"index"`
+	UserAgent  string `gorm:"size:512"`
+	CreatedAt  time.Time
+}
+
+func (pc PostgresLinkClick) TableName() string {
+	return LINK_CLICKS_TABLE_NAME
+}
+
+type LinkRepository struct {
+	db *gorm.DB
+}
+
+func NewLinkRepository(db *gorm.DB) *LinkRepository {
+	return &LinkRepository{db}
+}
+
+// CreateLinks stores the links of a broadcast in one insert and sets their IDs
+func (r *LinkRepository) CreateLinks(ctx context.Context, links []*domain.TrackedLink) error {
+	if len(links) == 0 {
+		return nil
+	}
+
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	postgresLinks := make([]PostgresTrackedLink, 0, len(links))
+	for _, link := range links {
+		postgresLinks = append(postgresLinks, NewPostgresTrackedLink(link))
+	}
+
+	if err := r.db.WithContext(ctx).Table(TRACKED_LINKS_TABLE_NAME).Create(&postgresLinks).Error; err != nil {
+		return fmt.Errorf("failed to create tracked links: %w", err)
+	}
+
+	for i, pl := range postgresLinks {
+		links[i].ID = pl.ID
+		links[i].CreatedAt = pl.CreatedAt
+	}
+
+	return nil
+}
+
+// GetByID returns the tracked link or nil if it doesn't exist
+func (r *LinkRepository) GetByID(ctx context.Context, id int) (*domain.TrackedLink, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var postgresLink PostgresTrackedLink
+
+	if err := r.db.WithContext(ctx).Table(TRACKED_LINKS_TABLE_NAME).First(&postgresLink, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get tracked link %d: %w", id, err)
+	}
+
+	return postgresLink.ToDomain(), nil
+}
+
+func (r *LinkRepository) CreateClick(ctx context.Context, click *domain.LinkClick) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	postgresClick := PostgresLinkClick{
+		LinkID:     click.LinkID,
+		TelegramID: click.TelegramID,
+		UserAgent:  click.UserAgent,
+	}
+	if err := r.db.WithContext(ctx).Table(LINK_CLICKS_TABLE_NAME).Create(&postgresClick).Error; err != nil {
+		return fmt.Errorf("failed to create click of link %d: %w", click.LinkID, err)
+	}
+
+	click.ID = postgresClick.ID
+	click.CreatedAt = postgresClick.CreatedAt
+
+	return nil
+}
+
+// GetBroadcastStats returns the clicks on each tracked link of the broadcast and the totals
+func (r *LinkRepository) GetBroadcastStats(ctx context.Context, broadcastID string) (*domain.BroadcastClickStats, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	stats := &domain.BroadcastClickStats{
+		BroadcastID: broadcastID,
+		Links:       []*domain.LinkStats{},
+	}
+
+	err := r.db.WithContext(ctx).
+		Table(TRACKED_LINKS_TABLE_NAME).
+		Select(`tracked_links.id AS link_id, tracked_links.url, tracked_links.variant,
+			COUNT(link_clicks.id) AS clicks, COUNT(DISTINCT link_clicks.telegram_id) AS unique_users`).
+		Joins("LEFT JOIN link_clicks ON link_clicks.link_id = tracked_links.id").
+		Where("tracked_links.broadcast_id = ?", broadcastID).
+		Group("tracked_links.id").
+		Order("tracked_links.id").
+		Scan(&stats.Links).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get link stats of broadcast '%s': %w", broadcastID, err)
+	}
+
+	// Users who clicked several links are counted once in the totals
+	var totals struct {
+		Clicks      int64
+		UniqueUsers int64
+	}
+	err = r.db.WithContext(ctx).
+		Table(LINK_CLICKS_TABLE_NAME).
+		Select("COUNT(link_clicks.id) AS clicks, COUNT(DISTINCT link_clicks.telegram_id) AS unique_users").
+		Joins("JOIN tracked_links ON tracked_links.id = link_clicks.link_id").
+		Where("tracked_links.broadcast_id = ?", broadcastID).
+		Scan(&totals).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get click totals of broadcast '%s': %w", broadcastID, err)
+	}
+
+	stats.Clicks = totals.Clicks
+	stats.UniqueUsers = totals.UniqueUsers
+
+	return stats, nil
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"hr-server/internal/domain"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrMemoryDuplicate is returned by the in-memory repositories when a unique constraint is violated
+var ErrMemoryDuplicate = errors.New("duplicate key value violates unique constraint")
+
+// MemoryDB holds the tables of the in-memory repositories, they are meant for tests and runs without Postgres.
+// Rows are stored and returned as copies, so callers can't change the stored data.
+type MemoryDB struct {
+	mu sync.RWMutex
+
+	users         []*domain.User
+	erasures      []*domain.UserErasure
+	channels      []*domain.Channel
+	campaigns     []*domain.Campaign
+	sources       []*domain.Source
+	apiKeys       []*domain.APIKey
+	admins        []*domain.Admin
+	refreshTokens []*domain.AdminRefreshToken
+	auditLog      []*domain.AuditEntry
+	trackedLinks  []*domain.TrackedLink
+	linkClicks    []*domain.LinkClick
+
+	dripSequences   []*domain.DripSequence
+	dripEnrollments []*domain.DripEnrollment
+
+	broadcastVariants  []*memoryBroadcastVariant
+	broadcastResponses []*domain.BroadcastResponse
+
+	lastIDs map[string]int
+}
+
+func NewMemoryDB() *MemoryDB {
+	return &MemoryDB{lastIDs: make(map[string]int)}
+}
+
+// nextID returns the next serial ID of the table, the lock must be held
+func (db *MemoryDB) nextID(table string) int {
+	db.lastIDs[table]++
+	return db.lastIDs[table]
+}
+
+// memoryKeyset mirrors keyset for in-memory rows, the cursor keeps the ID of the last row of the page
+type memoryKeyset[T any] struct {
+	id            func(T) int
+	columns       map[string]func(a, b T) int
+	defaultSortBy string
+}
+
+// fetch sorts the rows and returns the page items and the next cursor
+func (k memoryKeyset[T]) fetch(rows []T, page domain.PageRequest) ([]T, string, error) {
+	sortBy := page.SortBy
+	if sortBy == "" {
+		sortBy = k.defaultSortBy
+	}
+
+	compare, ok := k.columns[sortBy]
+	if !ok {
+		return nil, "", fmt.Errorf("unknown sort field '%s'", sortBy)
+	}
+
+	desc := page.SortOrder != domain.SortOrderAsc
+	slices.SortStableFunc(rows, func(a, b T) int {
+		result := cmp.Or(compare(a, b), cmp.Compare(k.id(a), k.id(b)))
+		if desc {
+			return -result
+		}
+		return result
+	})
+
+	limit := page.Limit
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	limit = min(limit, MaxPageLimit)
+
+	if page.Cursor != "" {
+		c, err := decodeCursor(page.Cursor)
+		if err != nil || c.SortBy != sortBy {
+			return nil, "", domain.ErrInvalidCursor
+		}
+
+		last := slices.IndexFunc(rows, func(row T) bool { return k.id(row) == c.ID })
+		if last < 0 {
+			return nil, "", domain.ErrInvalidCursor
+		}
+		rows = rows[last+1:]
+	}
+
+	if len(rows) <= limit {
+		return slices.Clone(rows), "", nil
+	}
+
+	items := slices.Clone(rows[:limit])
+	lastID := k.id(items[limit-1])
+
+	return items, encodeCursor(cursor{SortBy: sortBy, Value: strconv.Itoa(lastID), ID: lastID}), nil
+}
+
+// containsFold reports whether the substring is within the string ignoring case, like ILIKE with likePattern
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hr-server/internal/domain"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 500
+)
+
+// sortColumn describes a column that a list can be sorted by
+type sortColumn[T any] struct {
+	column string         // qualified column name
+	cast   string         // SQL type the cursor value is cast to
+	value  func(T) string // value of the column of a row, stored in the cursor
+}
+
+// keyset implements cursor-based pagination over (sort column, id) pairs
+type keyset[T any] struct {
+	idColumn      string
+	id            func(T) int
+	columns       map[string]sortColumn[T]
+	defaultSortBy string
+}
+
+type cursor struct {
+	SortBy string `json:"s"`
+	Value  string `json:"v"`
+	ID     int    `json:"id"`
+}
+
+// fetch applies sorting, the cursor and the limit to the query and returns the page items and the next cursor
+func (k keyset[T]) fetch(query *gorm.DB, page domain.PageRequest) ([]T, string, error) {
+	sortBy := page.SortBy
+	if sortBy == "" {
+		sortBy = k.defaultSortBy
+	}
+
+	column, ok := k.columns[sortBy]
+	if !ok {
+		return nil, "", fmt.Errorf("unknown sort field '%s'", sortBy)
+	}
+
+	desc := page.SortOrder != domain.SortOrderAsc
+	order, op := "ASC", ">"
+	if desc {
+		order, op = "DESC", "<"
+	}
+
+	limit := page.Limit
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	limit = min(limit, MaxPageLimit)
+
+	if page.Cursor != "" {
+		c, err := decodeCursor(page.Cursor)
+		if err != nil || c.SortBy != sortBy {
+			return nil, "", domain.ErrInvalidCursor
+		}
+
+		query = query.Where(
+			fmt.Sprintf("(%s, %s) %s (CAST(? AS %s), ?)", column.column, k.idColumn, op, column.cast),
+			c.Value, c.ID,
+		)
+	}
+
+	items := []T{}
+	err := query.
+		Order(fmt.Sprintf("%s %s, %s %s", column.column, order, k.idColumn, order)).
+		Limit(limit + 1).
+		Scan(&items).Error
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(items) <= limit {
+		return items, "", nil
+	}
+
+	items = items[:limit]
+	last := items[limit-1]
+
+	return items, encodeCursor(cursor{SortBy: sortBy, Value: column.value(last), ID: k.id(last)}), nil
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// likePattern builds a substring pattern for ILIKE with the wildcards of the search escaped
+func likePattern(search string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + replacer.Replace(search) + "%"
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+type queryTimeoutKey struct{}
+
+// WithQueryTimeout returns the context with the timeout applied to each repository call made with it
+func WithQueryTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, queryTimeoutKey{}, timeout)
+}
+
+// queryContext returns the context with the query timeout of the context applied, if it's set
+func queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout, ok := ctx.Value(queryTimeoutKey{}).(time.Duration)
+	if !ok || timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
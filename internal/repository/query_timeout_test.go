@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryContext(t *testing.T) {
+	ctx, cancel := queryContext(context.Background())
+	_, ok := ctx.Deadline()
+	assert.False(t, ok, "no timeout without WithQueryTimeout")
+	cancel()
+	assert.Error(t, ctx.Err(), "cancel releases the query context")
+
+	ctx, cancel = queryContext(WithQueryTimeout(context.Background(), time.Minute))
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+
+	parent, cancelParent := context.WithCancel(WithQueryTimeout(context.Background(), time.Minute))
+	ctx, cancel = queryContext(parent)
+	defer cancel()
+
+	cancelParent()
+	assert.ErrorIs(t, ctx.Err(), context.Canceled, "the request cancellation reaches the query")
+}
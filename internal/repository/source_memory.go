@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"hr-server/internal/domain"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SourceMemoryRepository is the in-memory counterpart of SourceRepository
+type SourceMemoryRepository struct {
+	db *MemoryDB
+}
+
+func NewSourceMemoryRepository(db *MemoryDB) *SourceMemoryRepository {
+	return &SourceMemoryRepository{db}
+}
+
+func (r *SourceMemoryRepository) Create(ctx context.Context, name string) (*domain.Source, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, source := range r.db.sources {
+		if source.Name == name {
+			return nil, fmt.Errorf("failed to create source with name '%s': %w", name, ErrMemoryDuplicate)
+		}
+	}
+
+	now := time.Now()
+	source := &domain.Source{
+		ID:        r.db.nextID(SOURCES_TABLE_NAME),
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	r.db.sources = append(r.db.sources, source)
+
+	clone := *source
+	return &clone, nil
+}
+
+func (r *SourceMemoryRepository) GetByID(ctx context.Context, id int) (*domain.Source, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, source := range r.db.sources {
+		if source.ID == id {
+			clone := *source
+			return &clone, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *SourceMemoryRepository) GetAll(ctx context.Context) ([]*domain.Source, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var sources []*domain.Source
+	for _, source := range r.sorted() {
+		clone := *source
+		sources = append(sources, &clone)
+	}
+
+	return sources, nil
+}
+
+// GetStats returns the number of channels and users of every source
+func (r *SourceMemoryRepository) GetStats(ctx context.Context) ([]*domain.GroupStats, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var stats []*domain.GroupStats
+	for _, source := range r.sorted() {
+		stats = append(stats, r.db.groupStats(source.ID, source.Name, func(c *domain.Channel) *int { return c.SourceID }))
+	}
+
+	return stats, nil
+}
+
+// sorted returns the stored sources ordered by name, the lock must be held
+func (r *SourceMemoryRepository) sorted() []*domain.Source {
+	return slices.SortedStableFunc(slices.Values(r.db.sources), func(a, b *domain.Source) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hr-server/internal/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const SOURCES_TABLE_NAME = "sources"
+
+type PostgresSource struct {
+	ID        int    `gorm:"primaryKey;autoIncrement"`
+	Name      string `gorm:"size:255;uniqueIndex"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (pc PostgresSource) TableName() string {
+	return SOURCES_TABLE_NAME
+}
+
+func (pc PostgresSource) ToDomain() *domain.Source {
+	return &domain.Source{
+		ID:        pc.ID,
+		Name:      pc.Name,
+		CreatedAt: pc.CreatedAt,
+		UpdatedAt: pc.UpdatedAt,
+	}
+}
+
+type SourceRepository struct {
+	db *gorm.DB
+}
+
+func NewSourceRepository(db *gorm.DB) *SourceRepository {
+	return &SourceRepository{db}
+}
+
+func (r *SourceRepository) Create(ctx context.Context, name string) (*domain.Source, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	postgresSource := PostgresSource{Name: name}
+	if err := r.db.WithContext(ctx).Table(SOURCES_TABLE_NAME).Create(&postgresSource).Error; err != nil {
+		return nil, fmt.Errorf("failed to create source with name '%s': %w", name, err)
+	}
+
+	return postgresSource.ToDomain(), nil
+}
+
+func (r *SourceRepository) GetByID(ctx context.Context, id int) (*domain.Source, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var postgresSource PostgresSource
+
+	if err := r.db.WithContext(ctx).Table(SOURCES_TABLE_NAME).First(&postgresSource, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get source by ID %d: %w", id, err)
+	}
+
+	return postgresSource.ToDomain(), nil
+}
+
+func (r *SourceRepository) GetAll(ctx context.Context) ([]*domain.Source, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var postgresSources []PostgresSource
+
+	if err := r.db.WithContext(ctx).Table(SOURCES_TABLE_NAME).Order("name").Find(&postgresSources).Error; err != nil {
+		return nil, fmt.Errorf("failed to get all sources: %w", err)
+	}
+
+	var sources []*domain.Source
+	for _, pc := range postgresSources {
+		sources = append(sources, pc.ToDomain())
+	}
+
+	return sources, nil
+}
+
+// GetStats returns the number of channels and users of every source
+func (r *SourceRepository) GetStats(ctx context.Context) ([]*domain.GroupStats, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var stats []*domain.GroupStats
+
+	err := r.db.WithContext(ctx).Table(SOURCES_TABLE_NAME).
+		Select("sources.id, sources.name, " +
+			"COUNT(DISTINCT channels.id) as channels_count, COUNT(users.id) as users_count").
+		Joins("LEFT JOIN channels ON channels.source_id = sources.id").
+		Joins("LEFT JOIN users ON users.channel_id = channels.id").
+		Group("sources.id, sources.name").
+		Order("sources.name").Scan(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source stats: %w", err)
+	}
+
+	return stats, nil
+}
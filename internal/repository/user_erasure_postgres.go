@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"hr-server/internal/domain"
+	"time"
+)
+
+const USER_ERASURES_TABLE_NAME = "user_erasures"
+
+type PostgresUserErasure struct {
+	ID          int    `gorm:"primaryKey;autoIncrement"`
+	UserID      int    `gorm:"index"`
+	Mode        string `gorm:"size:20"`
+	Reason      string `gorm:"size:1000"`
+	RequestedBy string `gorm:"size:255"`
+	CreatedAt   time.Time
+}
+
+func NewPostgresUserErasure(erasure *domain.UserErasure) PostgresUserErasure {
+	return PostgresUserErasure{
+		ID:          erasure.ID,
+		UserID:      erasure.UserID,
+		Mode:        erasure.Mode,
+		Reason:      erasure.Reason,
+		RequestedBy: erasure.RequestedBy,
+	}
+}
+
+func (pe PostgresUserErasure) TableName() string {
+	return USER_ERASURES_TABLE_NAME
+}
+
+func (pe PostgresUserErasure) ToDomain() *domain.UserErasure {
+	return &domain.UserErasure{
+		ID:          pe.ID,
+		UserID:      pe.UserID,
+		Mode:        pe.Mode,
+		Reason:      pe.Reason,
+		RequestedBy: pe.RequestedBy,
+		CreatedAt:   pe.CreatedAt,
+	}
+}
@@ -0,0 +1,475 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"hr-server/internal/domain"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// UserMemoryRepository is the in-memory counterpart of UserRepository
+type UserMemoryRepository struct {
+	db *MemoryDB
+}
+
+func NewUserMemoryRepository(db *MemoryDB) *UserMemoryRepository {
+	return &UserMemoryRepository{db}
+}
+
+// Upsert registers the user who started the bot, an existing user keeps the channel attribution,
+// gets the names and last seen time refreshed and is reactivated if blocked. A new user is attributed
+// to the channel only while it's below max_users. Returns true if the user was created and true if the channel was full.
+func (r *UserMemoryRepository) Upsert(ctx context.Context, telegramID int64, username, firstName string, channelID *int) (bool, bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+
+	if existing := r.find(telegramID); existing != nil {
+		existing.Username = username
+		existing.FirstName = firstName
+		if existing.Status == domain.UserStatusBlocked {
+			existing.Status = domain.UserStatusActive
+		}
+		existing.LastSeenAt = &now
+		existing.UpdatedAt = now
+
+		return false, false, nil
+	}
+
+	full := false
+	if channelID != nil {
+		if channel := r.findChannel(*channelID); channel == nil {
+			channelID = nil
+		} else if channel.MaxUsers != nil && r.countChannelUsers(*channelID) >= int64(*channel.MaxUsers) {
+			channelID, full = nil, true
+		}
+	}
+
+	r.db.users = append(r.db.users, &domain.User{
+		ID:         r.db.nextID(USERS_TABLE_NAME),
+		TelegramID: telegramID,
+		Username:   username,
+		FirstName:  firstName,
+		ChannelID:  channelID,
+		Status:     domain.UserStatusActive,
+		LastSeenAt: &now,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+
+	return true, full, nil
+}
+
+func (r *UserMemoryRepository) GetByTelegramID(ctx context.Context, telegramID int64) (*domain.User, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	user := r.find(telegramID)
+	if user == nil {
+		return nil, nil
+	}
+
+	clone := *user
+	return &clone, nil
+}
+
+func (r *UserMemoryRepository) GetWithChannelByTelegramID(ctx context.Context, telegramID int64) (*domain.UserWithChannel, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	user := r.find(telegramID)
+	if user == nil {
+		return nil, nil
+	}
+
+	return r.withChannel(user), nil
+}
+
+// UpsertImported inserts the imported user or updates the existing one by Telegram ID.
+// Empty username and channel keep the existing values. Returns true if the user was created.
+func (r *UserMemoryRepository) UpsertImported(ctx context.Context, user *domain.User) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	now := time.Now()
+
+	if existing := r.find(user.TelegramID); existing != nil {
+		if user.Username != "" {
+			existing.Username = user.Username
+		}
+		if user.ChannelID != nil {
+			existing.ChannelID = user.ChannelID
+		}
+		existing.UpdatedAt = now
+
+		return false, nil
+	}
+
+	createdAt := user.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = now
+	}
+
+	r.db.users = append(r.db.users, &domain.User{
+		ID:         r.db.nextID(USERS_TABLE_NAME),
+		TelegramID: user.TelegramID,
+		Username:   user.Username,
+		ChannelID:  user.ChannelID,
+		Status:     domain.UserStatusActive,
+		CreatedAt:  createdAt,
+		UpdatedAt:  now,
+	})
+
+	return true, nil
+}
+
+// Update applies the changes to the user, returns false if the user doesn't exist
+func (r *UserMemoryRepository) Update(ctx context.Context, telegramID int64, update domain.UserUpdate) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	user := r.find(telegramID)
+	if user == nil {
+		return false, nil
+	}
+
+	if update.ChannelID != nil {
+		channelID := *update.ChannelID
+		user.ChannelID = &channelID
+	} else if update.ClearChannel {
+		user.ChannelID = nil
+	}
+
+	if update.Status != nil {
+		user.Status = *update.Status
+	}
+
+	if update.Timezone != nil {
+		user.Timezone = *update.Timezone
+	}
+
+	user.UpdatedAt = time.Now()
+
+	return true, nil
+}
+
+// Delete deletes the user, returns false if the user doesn't exist
+func (r *UserMemoryRepository) Delete(ctx context.Context, telegramID int64) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	user := r.find(telegramID)
+	if user == nil {
+		return false, nil
+	}
+
+	r.db.users = slices.DeleteFunc(r.db.users, func(u *domain.User) bool { return u.ID == user.ID })
+
+	return true, nil
+}
+
+// Erase deletes or anonymizes the user and writes the erasure audit entry. Returns nil if the user doesn't exist.
+func (r *UserMemoryRepository) Erase(ctx context.Context, telegramID int64, erasure *domain.UserErasure) (*domain.UserErasure, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	user := r.find(telegramID)
+	if user == nil {
+		return nil, nil
+	}
+
+	// the names are scrubbed before they are cleared by the anonymization
+	r.scrubAuditLog(telegramID, user.ID, user.Username, user.FirstName)
+
+	switch erasure.Mode {
+	case domain.ErasureModeAnonymize:
+		user.TelegramID = -int64(user.ID)
+		user.Username = ""
+		user.FirstName = ""
+		user.Timezone = ""
+		user.ChannelID = nil
+		user.Status = domain.UserStatusErased
+		user.UpdatedAt = time.Now()
+	default:
+		r.db.users = slices.DeleteFunc(r.db.users, func(u *domain.User) bool { return u.ID == user.ID })
+	}
+
+	r.db.linkClicks = slices.DeleteFunc(r.db.linkClicks, func(c *domain.LinkClick) bool { return c.TelegramID == telegramID })
+	r.db.dripEnrollments = slices.DeleteFunc(r.db.dripEnrollments, func(e *domain.DripEnrollment) bool { return e.TelegramID == telegramID })
+	r.db.broadcastResponses = slices.DeleteFunc(r.db.broadcastResponses, func(response *domain.BroadcastResponse) bool { return response.TelegramID == telegramID })
+
+	created := *erasure
+	created.ID = r.db.nextID(USER_ERASURES_TABLE_NAME)
+	created.UserID = user.ID
+	created.CreatedAt = time.Now()
+	r.db.erasures = append(r.db.erasures, &created)
+
+	result := created
+	return &result, nil
+}
+
+// scrubAuditLog replaces the Telegram ID and the names of the user in the audit log like the Postgres repository,
+// the lock must be held
+func (r *UserMemoryRepository) scrubAuditLog(telegramID int64, userID int, names ...string) {
+	reference := domain.ErasedUserReference(userID)
+	target := strconv.FormatInt(telegramID, 10)
+
+	for _, entry := range r.db.auditLog {
+		userTarget := strings.HasPrefix(entry.Action, "user.") && entry.Target == target
+		if !userTarget && replaceWord(entry.Request, target, reference) == entry.Request {
+			continue
+		}
+
+		if userTarget {
+			entry.Target = reference
+		}
+		entry.Request = replaceWord(entry.Request, target, reference)
+		for _, name := range names {
+			if name != "" {
+				entry.Request = replaceWord(entry.Request, name, domain.ErasedName)
+			}
+		}
+	}
+}
+
+// replaceWord replaces the occurrences of the word that aren't a part of a longer word
+func replaceWord(text, word, replacement string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(text, word)
+		if i < 0 {
+			break
+		}
+
+		before, _ := utf8.DecodeLastRuneInString(text[:i])
+		after, _ := utf8.DecodeRuneInString(text[i+len(word):])
+		if i > 0 && isWordRune(before) || i+len(word) < len(text) && isWordRune(after) {
+			b.WriteString(text[:i+len(word)])
+		} else {
+			b.WriteString(text[:i])
+			b.WriteString(replacement)
+		}
+		text = text[i+len(word):]
+	}
+	b.WriteString(text)
+
+	return b.String()
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func (r *UserMemoryRepository) GetAll(ctx context.Context) ([]*domain.User, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var users []*domain.User
+	for _, user := range r.db.users {
+		clone := *user
+		users = append(users, &clone)
+	}
+
+	return users, nil
+}
+
+var userMemoryKeyset = memoryKeyset[*domain.UserWithChannel]{
+	id: func(u *domain.UserWithChannel) int { return u.ID },
+	columns: map[string]func(a, b *domain.UserWithChannel) int{
+		"created_at":  func(a, b *domain.UserWithChannel) int { return a.CreatedAt.Compare(b.CreatedAt) },
+		"username":    func(a, b *domain.UserWithChannel) int { return strings.Compare(a.Username, b.Username) },
+		"telegram_id": func(a, b *domain.UserWithChannel) int { return cmp.Compare(a.TelegramID, b.TelegramID) },
+	},
+	defaultSortBy: "created_at",
+}
+
+// GetPageWithChannel returns a page of users with channel names matching the filter
+func (r *UserMemoryRepository) GetPageWithChannel(
+	ctx context.Context,
+	filter domain.UserFilter,
+	page domain.PageRequest,
+) (*domain.Page[*domain.UserWithChannel], error) {
+	r.db.mu.RLock()
+	users := r.filtered(filter)
+	r.db.mu.RUnlock()
+
+	items, nextCursor, err := userMemoryKeyset.fetch(users, page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page of users with channel names: %w", err)
+	}
+
+	return &domain.Page[*domain.UserWithChannel]{
+		Items:      items,
+		TotalCount: int64(len(users)),
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// GetAllWithChannelInBatches iterates over users with channel names matching the filter in batches ordered by ID
+func (r *UserMemoryRepository) GetAllWithChannelInBatches(
+	ctx context.Context,
+	filter domain.UserFilter,
+	batchSize int,
+	callback func([]*domain.UserWithChannel) error,
+) error {
+	r.db.mu.RLock()
+	users := r.filtered(filter)
+	r.db.mu.RUnlock()
+
+	for batch := 1; len(users) > 0; batch++ {
+		size := min(batchSize, len(users))
+		if err := callback(users[:size]); err != nil {
+			return fmt.Errorf("callback error in batch %d: %w", batch, err)
+		}
+		users = users[size:]
+	}
+
+	return nil
+}
+
+func (r *UserMemoryRepository) UpdateStatus(ctx context.Context, telegramID int64, status string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if user := r.find(telegramID); user != nil {
+		user.Status = status
+		user.UpdatedAt = time.Now()
+	}
+
+	return nil
+}
+
+func (r *UserMemoryRepository) GetByChannel(ctx context.Context, channelID int) ([]*domain.User, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var users []*domain.User
+	for _, user := range r.db.users {
+		if user.ChannelID != nil && *user.ChannelID == channelID {
+			clone := *user
+			users = append(users, &clone)
+		}
+	}
+
+	return users, nil
+}
+
+// GetAllInBatches iterates over all users except erased ones with channel names in batches, it stops when the context is done
+func (r *UserMemoryRepository) GetAllInBatches(ctx context.Context, batchSize int, callback func([]*domain.UserWithChannel) error) error {
+	r.db.mu.RLock()
+	var users []*domain.UserWithChannel
+	for _, user := range r.db.users {
+		if user.Status != domain.UserStatusErased {
+			users = append(users, r.withChannel(user))
+		}
+	}
+	r.db.mu.RUnlock()
+
+	for batch := 1; len(users) > 0; batch++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		size := min(batchSize, len(users))
+		if err := callback(users[:size]); err != nil {
+			return fmt.Errorf("callback error in batch %d: %w", batch, err)
+		}
+		users = users[size:]
+	}
+
+	return nil
+}
+
+// findChannel returns the stored channel or nil, the lock must be held
+func (r *UserMemoryRepository) findChannel(id int) *domain.Channel {
+	for _, channel := range r.db.channels {
+		if channel.ID == id {
+			return channel
+		}
+	}
+
+	return nil
+}
+
+// countChannelUsers counts the users attributed to the channel, the lock must be held
+func (r *UserMemoryRepository) countChannelUsers(channelID int) int64 {
+	var count int64
+	for _, user := range r.db.users {
+		if user.ChannelID != nil && *user.ChannelID == channelID {
+			count++
+		}
+	}
+
+	return count
+}
+
+// find returns the stored user, the lock must be held
+func (r *UserMemoryRepository) find(telegramID int64) *domain.User {
+	for _, user := range r.db.users {
+		if user.TelegramID == telegramID {
+			return user
+		}
+	}
+
+	return nil
+}
+
+// filtered returns copies of the users matching the filter ordered by ID, the lock must be held
+func (r *UserMemoryRepository) filtered(filter domain.UserFilter) []*domain.UserWithChannel {
+	users := []*domain.UserWithChannel{}
+
+	for _, user := range r.db.users {
+		switch {
+		case filter.ChannelID != nil && (user.ChannelID == nil || *user.ChannelID != *filter.ChannelID),
+			filter.CreatedAt.From != nil && user.CreatedAt.Before(*filter.CreatedAt.From),
+			filter.CreatedAt.To != nil && !user.CreatedAt.Before(*filter.CreatedAt.To),
+			filter.Username != "" && !containsFold(user.Username, filter.Username),
+			filter.Status != "" && user.Status != filter.Status:
+			continue
+		}
+
+		users = append(users, r.withChannel(user))
+	}
+
+	return users
+}
+
+// withChannel returns a copy of the user joined with the channel, the lock must be held
+func (r *UserMemoryRepository) withChannel(user *domain.User) *domain.UserWithChannel {
+	result := &domain.UserWithChannel{
+		ID:         user.ID,
+		TelegramID: user.TelegramID,
+		Username:   user.Username,
+		FirstName:  user.FirstName,
+		ChannelID:  user.ChannelID,
+		Status:     user.Status,
+		Timezone:   user.Timezone,
+		LastSeenAt: user.LastSeenAt,
+		CreatedAt:  user.CreatedAt,
+		UpdatedAt:  user.UpdatedAt,
+	}
+
+	if user.ChannelID == nil {
+		return result
+	}
+
+	for _, channel := range r.db.channels {
+		if channel.ID == *user.ChannelID {
+			name, code, linkType := channel.Name, channel.Code, channel.LinkType
+			result.ChannelName = &name
+			result.ChannelCode = &code
+			result.ChannelLinkType = &linkType
+			result.ChannelTimezone = channel.Timezone
+			break
+		}
+	}
+
+	return result
+}
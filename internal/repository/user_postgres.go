@@ -1,9 +1,12 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"hr-server/internal/domain"
+	"regexp"
+	"strconv"
 	"time"
 
 	"gorm.io/gorm"
@@ -11,11 +14,19 @@ import (
 
 const USERS_TABLE_NAME = "users"
 
+// userWithChannelColumns selects the users joined with their channels into domain.UserWithChannel
+const userWithChannelColumns = "users.*, channels.name as channel_name, channels.code as channel_code, " +
+	"channels.link_type as channel_link_type, COALESCE(channels.timezone, '') as channel_timezone"
+
 type PostgresUser struct {
 	ID         int    `gorm:"primaryKey;autoIncrement"`
 	TelegramID int64  `gorm:"uniqueIndex"`
 	Username   string `gorm:"size:255"`
+	FirstName  string `gorm:"size:255;not null;default:''"`
 	ChannelID  *int   `gorm:"index"`
+	Status     string `gorm:"size:20;not null;default:active;index"`
+	Timezone   string `gorm:"size:64;not null;default:''"`
+	LastSeenAt *time.Time
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
 }
@@ -30,7 +41,11 @@ func NewPostgresUser(user *domain.User) PostgresUser {
 		ID:         user.ID,
 		TelegramID: user.TelegramID,
 		Username:   user.Username,
+		FirstName:  user.FirstName,
 		ChannelID:  user.ChannelID,
+		Status:     user.Status,
+		Timezone:   user.Timezone,
+		LastSeenAt: user.LastSeenAt,
 	}
 }
 
@@ -43,7 +58,11 @@ func (pu PostgresUser) ToDomain() *domain.User {
 		ID:         pu.ID,
 		TelegramID: pu.TelegramID,
 		Username:   pu.Username,
+		FirstName:  pu.FirstName,
 		ChannelID:  pu.ChannelID,
+		Status:     pu.Status,
+		Timezone:   pu.Timezone,
+		LastSeenAt: pu.LastSeenAt,
 		CreatedAt:  pu.CreatedAt,
 		UpdatedAt:  pu.UpdatedAt,
 	}
@@ -54,32 +73,73 @@ type UserRepository struct {
 }
 
 func NewUserRepository(db *gorm.DB) *UserRepository {
-	if err := db.AutoMigrate(PostgresUser{}); err != nil {
-		panic(err)
-	}
-
 	return &UserRepository{db}
 }
 
-func (r *UserRepository) Create(telegramID int64, username string, channelID *int) error {
-	user := &domain.User{
-		TelegramID: telegramID,
-		Username:   username,
-		ChannelID:  channelID,
+// Upsert registers the user who started the bot in one transaction, so concurrent starts don't conflict.
+// An existing user keeps the channel attribution, gets the names and last seen time refreshed
+// and is reactivated if blocked. A new user is attributed to the channel only while it's below max_users,
+// the channel row is locked while the users are counted, so concurrent starts can't exceed the cap.
+// Returns true if the user was created and true if the channel was full.
+func (r *UserRepository) Upsert(ctx context.Context, telegramID int64, username, firstName string, channelID *int) (bool, bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var result struct {
+		Inserted bool
 	}
+	full := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if channelID != nil {
+			var channel PostgresChannel
+			if err := tx.Raw("SELECT id, max_users FROM channels WHERE id = ? FOR UPDATE", *channelID).Scan(&channel).Error; err != nil {
+				return fmt.Errorf("failed to lock channel %d: %w", *channelID, err)
+			}
+
+			switch {
+			case channel.ID == 0:
+				// deleted meanwhile
+				channelID = nil
+			case channel.MaxUsers != nil:
+				var count int64
+				if err := tx.Table(USERS_TABLE_NAME).Where("channel_id = ?", *channelID).Count(&count).Error; err != nil {
+					return fmt.Errorf("failed to count users of channel %d: %w", *channelID, err)
+				}
+				if count >= int64(*channel.MaxUsers) {
+					channelID, full = nil, true
+				}
+			}
+		}
 
-	postgresUser := NewPostgresUser(user)
-	if err := r.db.Table(USERS_TABLE_NAME).Create(&postgresUser).Error; err != nil {
-		return fmt.Errorf("failed to create user in database: %w", err)
+		now := time.Now()
+		return tx.Raw(`
+			INSERT INTO users (telegram_id, username, first_name, channel_id, status, last_seen_at, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (telegram_id) DO UPDATE SET
+				username = EXCLUDED.username,
+				first_name = EXCLUDED.first_name,
+				status = CASE WHEN users.status = ? THEN EXCLUDED.status ELSE users.status END,
+				last_seen_at = EXCLUDED.last_seen_at,
+				updated_at = EXCLUDED.updated_at
+			RETURNING (xmax = 0) AS inserted`,
+			telegramID, username, firstName, channelID, domain.UserStatusActive, now, now, now, domain.UserStatusBlocked,
+		).Scan(&result).Error
+	})
+	if err != nil {
+		return false, false, fmt.Errorf("failed to upsert user %d: %w", telegramID, err)
 	}
 
-	return nil
+	return result.Inserted, full, nil
 }
 
-func (r *UserRepository) GetByTelegramID(telegramID int64) (*domain.User, error) {
+func (r *UserRepository) GetByTelegramID(ctx context.Context, telegramID int64) (*domain.User, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	var postgresUser PostgresUser
 
-	if err := r.db.Table(USERS_TABLE_NAME).First(&postgresUser, "telegram_id = ?", telegramID).Error; err != nil {
+	if err := r.db.WithContext(ctx).Table(USERS_TABLE_NAME).First(&postgresUser, "telegram_id = ?", telegramID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -89,10 +149,201 @@ func (r *UserRepository) GetByTelegramID(telegramID int64) (*domain.User, error)
 	return postgresUser.ToDomain(), nil
 }
 
-func (r *UserRepository) GetAll() ([]*domain.User, error) {
+func (r *UserRepository) GetWithChannelByTelegramID(ctx context.Context, telegramID int64) (*domain.UserWithChannel, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var users []*domain.UserWithChannel
+
+	err := r.db.WithContext(ctx).Table(USERS_TABLE_NAME).
+		Select(userWithChannelColumns).
+		Joins("LEFT JOIN channels ON users.channel_id = channels.id").
+		Where("users.telegram_id = ?", telegramID).
+		Limit(1).Scan(&users).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user with channel name by telegram ID %d: %w", telegramID, err)
+	}
+
+	if len(users) == 0 {
+		return nil, nil
+	}
+
+	return users[0], nil
+}
+
+// UpsertImported inserts the imported user or updates the existing one by Telegram ID.
+// Empty username and channel keep the existing values. Returns true if the user was created.
+func (r *UserRepository) UpsertImported(ctx context.Context, user *domain.User) (bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	now := time.Now()
+	createdAt := user.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = now
+	}
+
+	var result struct {
+		Inserted bool
+	}
+
+	err := r.db.WithContext(ctx).Raw(`
+		INSERT INTO users (telegram_id, username, channel_id, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (telegram_id) DO UPDATE SET
+			username = COALESCE(NULLIF(EXCLUDED.username, ''), users.username),
+			channel_id = COALESCE(EXCLUDED.channel_id, users.channel_id),
+			updated_at = EXCLUDED.updated_at
+		RETURNING (xmax = 0) AS inserted`,
+		user.TelegramID, user.Username, user.ChannelID, domain.UserStatusActive, createdAt, now,
+	).Scan(&result).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to upsert user %d: %w", user.TelegramID, err)
+	}
+
+	return result.Inserted, nil
+}
+
+// Update applies the changes to the user, returns false if the user doesn't exist
+func (r *UserRepository) Update(ctx context.Context, telegramID int64, update domain.UserUpdate) (bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	fields := map[string]interface{}{"updated_at": time.Now()}
+
+	if update.ChannelID != nil {
+		fields["channel_id"] = *update.ChannelID
+	} else if update.ClearChannel {
+		fields["channel_id"] = nil
+	}
+
+	if update.Status != nil {
+		fields["status"] = *update.Status
+	}
+
+	if update.Timezone != nil {
+		fields["timezone"] = *update.Timezone
+	}
+
+	result := r.db.WithContext(ctx).Table(USERS_TABLE_NAME).Where("telegram_id = ?", telegramID).Updates(fields)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update user %d: %w", telegramID, result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// Delete deletes the user, returns false if the user doesn't exist
+func (r *UserRepository) Delete(ctx context.Context, telegramID int64) (bool, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	result := r.db.WithContext(ctx).Table(USERS_TABLE_NAME).Where("telegram_id = ?", telegramID).Delete(&PostgresUser{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete user %d: %w", telegramID, result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// Erase deletes or anonymizes the user with all related records and writes the erasure audit entry
+// in one transaction. Returns nil if the user doesn't exist.
+func (r *UserRepository) Erase(ctx context.Context, telegramID int64, erasure *domain.UserErasure) (*domain.UserErasure, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var postgresErasure PostgresUserErasure
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var postgresUser PostgresUser
+		if err := tx.Table(USERS_TABLE_NAME).First(&postgresUser, "telegram_id = ?", telegramID).Error; err != nil {
+			return err
+		}
+
+		switch erasure.Mode {
+		case domain.ErasureModeAnonymize:
+			// Negative IDs never collide with real Telegram IDs and keep the unique index satisfied.
+			// The channel is cleared too, a personal invite channel would identify the user.
+			err := tx.Table(USERS_TABLE_NAME).Where("id = ?", postgresUser.ID).Updates(map[string]interface{}{
+				"telegram_id": -int64(postgresUser.ID),
+				"username":    "",
+				"first_name":  "",
+				"timezone":    "",
+				"channel_id":  nil,
+				"status":      domain.UserStatusErased,
+				"updated_at":  time.Now(),
+			}).Error
+			if err != nil {
+				return fmt.Errorf("failed to anonymize user: %w", err)
+			}
+		default:
+			if err := tx.Table(USERS_TABLE_NAME).Where("id = ?", postgresUser.ID).Delete(&PostgresUser{}).Error; err != nil {
+				return fmt.Errorf("failed to delete user: %w", err)
+			}
+		}
+
+		// Clicks, drip enrollments and broadcast responses are kept by the Telegram ID, so they are deleted in both modes
+		if err := tx.Table(LINK_CLICKS_TABLE_NAME).Where("telegram_id = ?", telegramID).Delete(&PostgresLinkClick{}).Error; err != nil {
+			return fmt.Errorf("failed to delete link clicks: %w", err)
+		}
+		if err := tx.Table(DRIP_ENROLLMENTS_TABLE_NAME).Where("telegram_id = ?", telegramID).Delete(&PostgresDripEnrollment{}).Error; err != nil {
+			return fmt.Errorf("failed to delete drip enrollments: %w", err)
+		}
+		if err := tx.Table(BROADCAST_RESPONSES_TABLE_NAME).Where("telegram_id = ?", telegramID).Delete(&PostgresBroadcastResponse{}).Error; err != nil {
+			return fmt.Errorf("failed to delete broadcast responses: %w", err)
+		}
+		if err := scrubAuditLog(tx, telegramID, postgresUser); err != nil {
+			return fmt.Errorf("failed to scrub audit log: %w", err)
+		}
+
+		erasure.UserID = postgresUser.ID
+		postgresErasure = NewPostgresUserErasure(erasure)
+		if err := tx.Table(USER_ERASURES_TABLE_NAME).Create(&postgresErasure).Error; err != nil {
+			return fmt.Errorf("failed to create erasure audit entry: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to erase user %d: %w", telegramID, err)
+	}
+
+	return postgresErasure.ToDomain(), nil
+}
+
+// scrubAuditLog replaces the Telegram ID of the user with the reference to the internal ID in the audit entries
+// of the user actions and in the requests mentioning it, as well as the names of the user in those requests
+func scrubAuditLog(tx *gorm.DB, telegramID int64, user PostgresUser) error {
+	reference := domain.ErasedUserReference(user.ID)
+	idPattern := fmt.Sprintf(`\m%d\M`, telegramID)
+
+	request := gorm.Expr("regexp_replace(request, ?, ?, 'g')", idPattern, reference)
+	for _, name := range []string{user.Username, user.FirstName} {
+		if name != "" {
+			request = gorm.Expr("regexp_replace(?, ?, ?, 'g')", request, `\m`+regexp.QuoteMeta(name)+`\M`, domain.ErasedName)
+		}
+	}
+
+	target := strconv.FormatInt(telegramID, 10)
+
+	return tx.Table(AUDIT_LOG_TABLE_NAME).
+		Where("(action LIKE 'user.%' AND target = ?) OR request ~ ?", target, idPattern).
+		Updates(map[string]interface{}{
+			"target":  gorm.Expr("CASE WHEN action LIKE 'user.%' AND target = ? THEN ? ELSE target END", target, reference),
+			"request": request,
+		}).Error
+}
+
+func (r *UserRepository) GetAll(ctx context.Context) ([]*domain.User, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	var postgresUsers []PostgresUser
 
-	if err := r.db.Table(USERS_TABLE_NAME).Find(&postgresUsers).Error; err != nil {
+	if err := r.db.WithContext(ctx).Table(USERS_TABLE_NAME).Find(&postgresUsers).Error; err != nil {
 		return nil, fmt.Errorf("failed to get all users: %w", err)
 	}
 
@@ -104,24 +355,149 @@ func (r *UserRepository) GetAll() ([]*domain.User, error) {
 	return users, nil
 }
 
-func (r *UserRepository) GetAllWithChannel() ([]*domain.UserWithChannel, error) {
-	var users []*domain.UserWithChannel
+var userKeyset = keyset[*domain.UserWithChannel]{
+	idColumn: "users.id",
+	id:       func(u *domain.UserWithChannel) int { return u.ID },
+	columns: map[string]sortColumn[*domain.UserWithChannel]{
+		"created_at": {
+			column: "users.created_at",
+			cast:   "timestamptz",
+			value:  func(u *domain.UserWithChannel) string { return u.CreatedAt.Format(time.RFC3339Nano) },
+		},
+		"username": {
+			column: "users.username",
+			cast:   "text",
+			value:  func(u *domain.UserWithChannel) string { return u.Username },
+		},
+		"telegram_id": {
+			column: "users.telegram_id",
+			cast:   "bigint",
+			value:  func(u *domain.UserWithChannel) string { return strconv.FormatInt(u.TelegramID, 10) },
+		},
+	},
+	defaultSortBy: "created_at",
+}
 
-	err := r.db.Table(USERS_TABLE_NAME).
-		Select("users.*, channels.name as channel_name").
-		Joins("LEFT JOIN channels ON users.channel_id = channels.id").
-		Order("users.created_at DESC").Scan(&users).Error
+// GetPageWithChannel returns a page of users with channel names matching the filter
+func (r *UserRepository) GetPageWithChannel(
+	ctx context.Context,
+	filter domain.UserFilter,
+	page domain.PageRequest,
+) (*domain.Page[*domain.UserWithChannel], error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	var total int64
+	if err := r.filtered(ctx, filter).Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	query := r.filtered(ctx, filter).
+		Select(userWithChannelColumns).
+		Joins("LEFT JOIN channels ON users.channel_id = channels.id")
+
+	users, nextCursor, err := userKeyset.fetch(query, page)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get all users with channel names: %w", err)
+		return nil, fmt.Errorf("failed to get page of users with channel names: %w", err)
 	}
 
-	return users, nil
+	return &domain.Page[*domain.UserWithChannel]{
+		Items:      users,
+		TotalCount: total,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// GetAllWithChannelInBatches iterates over users with channel names matching the filter in batches ordered by ID
+func (r *UserRepository) GetAllWithChannelInBatches(
+	ctx context.Context,
+	filter domain.UserFilter,
+	batchSize int,
+	callback func([]*domain.UserWithChannel) error,
+) error {
+	lastID := 0
+
+	for batch := 1; ; batch++ {
+		var users []*domain.UserWithChannel
+
+		// the timeout applies to each batch, the whole iteration is bounded by ctx only
+		batchCtx, cancel := queryContext(ctx)
+		err := r.filtered(batchCtx, filter).
+			Select(userWithChannelColumns).
+			Joins("LEFT JOIN channels ON users.channel_id = channels.id").
+			Where("users.id > ?", lastID).
+			Order("users.id").
+			Limit(batchSize).
+			Scan(&users).Error
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to get batch %d of users with channel names: %w", batch, err)
+		}
+
+		if len(users) == 0 {
+			return nil
+		}
+
+		if err := callback(users); err != nil {
+			return fmt.Errorf("callback error in batch %d: %w", batch, err)
+		}
+
+		if len(users) < batchSize {
+			return nil
+		}
+
+		lastID = users[len(users)-1].ID
+	}
 }
 
-func (r *UserRepository) GetByChannel(channelID int) ([]*domain.User, error) {
+// filtered returns a new users query with the filter applied
+func (r *UserRepository) filtered(ctx context.Context, filter domain.UserFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Table(USERS_TABLE_NAME)
+
+	if filter.ChannelID != nil {
+		query = query.Where("users.channel_id = ?", *filter.ChannelID)
+	}
+
+	if filter.CreatedAt.From != nil {
+		query = query.Where("users.created_at >= ?", *filter.CreatedAt.From)
+	}
+
+	if filter.CreatedAt.To != nil {
+		query = query.Where("users.created_at < ?", *filter.CreatedAt.To)
+	}
+
+	if filter.Username != "" {
+		query = query.Where("users.username ILIKE ?", likePattern(filter.Username))
+	}
+
+	if filter.Status != "" {
+		query = query.Where("users.status = ?", filter.Status)
+	}
+
+	return query
+}
+
+func (r *UserRepository) UpdateStatus(ctx context.Context, telegramID int64, status string) error {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
+	err := r.db.WithContext(ctx).Table(USERS_TABLE_NAME).
+		Where("telegram_id = ?", telegramID).
+		Updates(map[string]interface{}{"status": status, "updated_at": time.Now()}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update status of user %d: %w", telegramID, err)
+	}
+
+	return nil
+}
+
+func (r *UserRepository) GetByChannel(ctx context.Context, channelID int) ([]*domain.User, error) {
+	ctx, cancel := queryContext(ctx)
+	defer cancel()
+
 	var postgresUsers []PostgresUser
 
-	if err := r.db.Table(USERS_TABLE_NAME).Where("channel_id = ?", channelID).Find(&postgresUsers).Error; err != nil {
+	if err := r.db.WithContext(ctx).Table(USERS_TABLE_NAME).Where("channel_id = ?", channelID).Find(&postgresUsers).Error; err != nil {
 		return nil, fmt.Errorf("failed to get users by channel %d: %w", channelID, err)
 	}
 
@@ -133,21 +509,40 @@ func (r *UserRepository) GetByChannel(channelID int) ([]*domain.User, error) {
 	return users, nil
 }
 
-func (r *UserRepository) GetAllInBatches(batchSize int, callback func([]*domain.User) error) error {
-	var postgresUsers []PostgresUser
+// GetAllInBatches iterates over all users except erased ones with channel names in batches ordered by ID.
+// The query timeout applies to each batch, broadcasts take longer than a query, the iteration is bounded by ctx only.
+func (r *UserRepository) GetAllInBatches(ctx context.Context, batchSize int, callback func([]*domain.UserWithChannel) error) error {
+	lastID := 0
+
+	for batch := 1; ; batch++ {
+		var users []*domain.UserWithChannel
+
+		batchCtx, cancel := queryContext(ctx)
+		err := r.db.WithContext(batchCtx).Table(USERS_TABLE_NAME).
+			Select(userWithChannelColumns).
+			Joins("LEFT JOIN channels ON users.channel_id = channels.id").
+			Where("users.status <> ?", domain.UserStatusErased).
+			Where("users.id > ?", lastID).
+			Order("users.id").
+			Limit(batchSize).
+			Scan(&users).Error
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to get batch %d of users: %w", batch, err)
+		}
 
-	result := r.db.Table(USERS_TABLE_NAME).FindInBatches(&postgresUsers, batchSize, func(tx *gorm.DB, batch int) error {
-		var users []*domain.User
-		for _, pu := range postgresUsers {
-			users = append(users, pu.ToDomain())
+		if len(users) == 0 {
+			return nil
 		}
 
 		if err := callback(users); err != nil {
 			return fmt.Errorf("callback error in batch %d: %w", batch, err)
 		}
 
-		return nil
-	})
+		if len(users) < batchSize {
+			return nil
+		}
 
-	return result.Error
+		lastID = users[len(users)-1].ID
+	}
 }
@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hr-server/config"
+	"hr-server/internal/domain"
+	"hr-server/internal/logger"
+	"hr-server/internal/repository"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	adminTokenType         = "Bearer"
+	adminAccessTokenIssuer = "hr-server"
+	telegramLoginMaxAge    = 24 * time.Hour
+	telegramLoginMaxSkew   = 5 * time.Minute // auth_date may be ahead of the server clock by this much
+)
+
+var (
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrInvalidAdminToken   = errors.New("invalid admin token")
+	ErrAdminAuthDisabled   = errors.New("admin authentication is disabled, ADMIN_JWT_SECRET is not set")
+	ErrAdminNotFound       = errors.New("admin not found")
+	ErrAdminExists         = errors.New("admin with this username or Telegram ID already exists")
+	ErrInvalidTelegramData = errors.New("invalid Telegram login data")
+	ErrScopeNotHeld        = errors.New("scope can't be granted, the caller doesn't hold it")
+)
+
+// dummyPasswordHash is compared against when the admin doesn't exist or has no password,
+// so the response time doesn't reveal usernames. It never logs anyone in.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+type adminClaims struct {
+	Username string   `json:"username"`
+	Scopes   []string `json:"scopes"`
+	jwt.RegisteredClaims
+}
+
+type AdminService struct {
+	adminRepo       AdminRepository
+	jwtSecret       []byte
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+	botToken        string
+}
+
+func NewAdminService(cfg *config.Config, adminRepo AdminRepository) *AdminService {
+	return &AdminService{
+		adminRepo:       adminRepo,
+		jwtSecret:       []byte(cfg.Admin.JWTSecret),
+		accessTokenTTL:  cfg.Admin.AccessTokenTTL,
+		refreshTokenTTL: cfg.Admin.RefreshTokenTTL,
+		botToken:        cfg.TgBot.Token,
+	}
+}
+
+// CreateAdmin creates an admin account, an empty password allows only the Telegram login.
+// The scopes must be held by the caller, whose scopes are grantorScopes.
+func (s *AdminService) CreateAdmin(ctx context.Context, username, password string, telegramID *int64, scopes, grantorScopes []string) (*domain.Admin, error) {
+	if err := checkScopesHeld(scopes, grantorScopes); err != nil {
+		return nil, err
+	}
+
+	admin := &domain.Admin{
+		Username:   username,
+		TelegramID: telegramID,
+		Scopes:     scopes,
+	}
+
+	if password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash password: %w", err)
+		}
+		admin.PasswordHash = string(hash)
+	}
+
+	// the unique indexes reject concurrent creates of the same admin
+	admin, err := s.adminRepo.Create(ctx, admin)
+	if repository.IsDuplicate(err) {
+		return nil, ErrAdminExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create admin: %w", err)
+	}
+
+	return admin, nil
+}
+
+func (s *AdminService) GetAll(ctx context.Context) ([]*domain.Admin, error) {
+	return s.adminRepo.GetAll(ctx)
+}
+
+// DisableAdmin disables the admin and ends all sessions, issued access tokens are rejected from the next request
+func (s *AdminService) DisableAdmin(ctx context.Context, id int) error {
+	found, err := s.adminRepo.Disable(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to disable admin: %w", err)
+	}
+	if !found {
+		return ErrAdminNotFound
+	}
+
+	return nil
+}
+
+func (s *AdminService) Login(ctx context.Context, username, password string) (*domain.AdminTokens, error) {
+	if len(s.jwtSecret) == 0 {
+		return nil, ErrAdminAuthDisabled
+	}
+
+	admin, err := s.adminRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get admin: %w", err)
+	}
+
+	// Admins without a password log in with Telegram only, the dummy hash just takes the same time
+	if admin == nil || admin.PasswordHash == "" {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(admin.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.login(ctx, admin)
+}
+
+// LoginWithTelegram verifies the Telegram Login Widget data signed with the bot token
+// and logs in the admin with this Telegram ID
+func (s *AdminService) LoginWithTelegram(ctx context.Context, data *domain.TelegramLoginData) (*domain.AdminTokens, error) {
+	if len(s.jwtSecret) == 0 {
+		return nil, ErrAdminAuthDisabled
+	}
+
+	if err := s.verifyTelegramLogin(data, time.Now()); err != nil {
+		return nil, err
+	}
+
+	admin, err := s.adminRepo.GetByTelegramID(ctx, data.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get admin: %w", err)
+	}
+
+	if admin == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.login(ctx, admin)
+}
+
+// Refresh exchanges a refresh token for new tokens, the old refresh token is revoked
+func (s *AdminService) Refresh(ctx context.Context, refreshToken string) (*domain.AdminTokens, error) {
+	if len(s.jwtSecret) == 0 {
+		return nil, ErrAdminAuthDisabled
+	}
+
+	token, err := s.adminRepo.RevokeRefreshToken(ctx, hashAPIKey(refreshToken))
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	if token == nil || !time.Now().Before(token.ExpiresAt) {
+		return nil, ErrInvalidAdminToken
+	}
+
+	admin, err := s.adminRepo.GetByID(ctx, token.AdminID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get admin: %w", err)
+	}
+
+	if admin == nil {
+		return nil, ErrInvalidAdminToken
+	}
+
+	return s.login(ctx, admin)
+}
+
+// Logout revokes the refresh token, unknown tokens are ignored
+func (s *AdminService) Logout(ctx context.Context, refreshToken string) error {
+	if _, err := s.adminRepo.RevokeRefreshToken(ctx, hashAPIKey(refreshToken)); err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// AuthenticateAccessToken returns the admin of a valid access token. The admin is loaded on each request,
+// so disabling the admin or changing the scopes takes effect before the token expires.
+func (s *AdminService) AuthenticateAccessToken(ctx context.Context, accessToken string) (*domain.Admin, error) {
+	if len(s.jwtSecret) == 0 {
+		return nil, ErrAdminAuthDisabled
+	}
+
+	claims := &adminClaims{}
+	_, err := jwt.ParseWithClaims(accessToken, claims, func(*jwt.Token) (interface{}, error) {
+		return s.jwtSecret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(adminAccessTokenIssuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, ErrInvalidAdminToken
+	}
+
+	id, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return nil, ErrInvalidAdminToken
+	}
+
+	admin, err := s.adminRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get admin: %w", err)
+	}
+
+	if admin == nil || admin.DisabledAt != nil {
+		return nil, ErrInvalidAdminToken
+	}
+
+	return admin, nil
+}
+
+// login issues new tokens for an active admin
+func (s *AdminService) login(ctx context.Context, admin *domain.Admin) (*domain.AdminTokens, error) {
+	if admin.DisabledAt != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	now := time.Now()
+	accessExpiresAt := now.Add(s.accessTokenTTL)
+
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, adminClaims{
+		Username: admin.Username,
+		Scopes:   admin.Scopes,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    adminAccessTokenIssuer,
+			Subject:   strconv.Itoa(admin.ID),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(accessExpiresAt),
+		},
+	}).SignedString(s.jwtSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	refreshToken, err := randomHex(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	refreshExpiresAt := now.Add(s.refreshTokenTTL)
+	err = s.adminRepo.CreateRefreshToken(ctx, &domain.AdminRefreshToken{
+		AdminID:   admin.ID,
+		Hash:      hashAPIKey(refreshToken),
+		ExpiresAt: refreshExpiresAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	if err := s.adminRepo.UpdateLastLogin(ctx, admin.ID); err != nil {
+		logger.FromContext(ctx).Error("error while update admin last login: ", err)
+	}
+
+	return &domain.AdminTokens{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+		TokenType:        adminTokenType,
+	}, nil
+}
+
+// verifyTelegramLogin checks the widget data hash as described in https://core.telegram.org/widgets/login
+func (s *AdminService) verifyTelegramLogin(data *domain.TelegramLoginData, now time.Time) error {
+	fields := map[string]string{
+		"id":        strconv.FormatInt(data.ID, 10),
+		"auth_date": strconv.FormatInt(data.AuthDate, 10),
+	}
+	for key, value := range map[string]string{
+		"first_name": data.FirstName,
+		"last_name":  data.LastName,
+		"username":   data.Username,
+		"photo_url":  data.PhotoURL,
+	} {
+		if value != "" {
+			fields[key] = value
+		}
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var dataCheckString []byte
+	for i, key := range keys {
+		if i > 0 {
+			dataCheckString = append(dataCheckString, '\n')
+		}
+		dataCheckString = append(dataCheckString, key+"="+fields[key]...)
+	}
+
+	secretKey := sha256.Sum256([]byte(s.botToken))
+	mac := hmac.New(sha256.New, secretKey[:])
+	mac.Write(dataCheckString)
+	expectedHash := hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expectedHash), []byte(data.Hash)) {
+		return ErrInvalidTelegramData
+	}
+
+	age := now.Sub(time.Unix(data.AuthDate, 0))
+	if age > telegramLoginMaxAge {
+		return fmt.Errorf("%w: auth_date is too old", ErrInvalidTelegramData)
+	}
+	if age < -telegramLoginMaxSkew {
+		return fmt.Errorf("%w: auth_date is in the future", ErrInvalidTelegramData)
+	}
+
+	return nil
+}
+
+// checkScopesHeld returns ErrScopeNotHeld if a scope isn't held by the grantor, so nobody grants more than they have
+func checkScopesHeld(scopes, grantorScopes []string) error {
+	for _, scope := range scopes {
+		if !slices.Contains(grantorScopes, scope) {
+			return fmt.Errorf("%w: %s", ErrScopeNotHeld, scope)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hr-server/config"
+	"hr-server/internal/domain"
+	"hr-server/internal/logger"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	apiKeyTokenPrefix      = "hrk_"
+	apiKeyLastUsedInterval = time.Minute
+)
+
+var (
+	ErrInvalidAPIKey  = errors.New("invalid API key")
+	ErrAPIKeyNotFound = errors.New("API key not found")
+)
+
+type APIKeyService struct {
+	apiKeyRepo  APIKeyRepository
+	legacyToken string
+}
+
+func NewAPIKeyService(cfg *config.Config, apiKeyRepo APIKeyRepository) *APIKeyService {
+	if cfg.AuthToken != "" {
+		logrus.Warn("AUTH_TOKEN is deprecated and will be removed, create API keys and unset it")
+	}
+
+	return &APIKeyService{
+		apiKeyRepo:  apiKeyRepo,
+		legacyToken: cfg.AuthToken,
+	}
+}
+
+// CreateAPIKey creates a key and returns it with the plaintext token, which is never stored.
+// The scopes must be held by the caller, whose scopes are grantorScopes.
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, name string, scopes, grantorScopes []string, expiresAt *time.Time) (*domain.APIKey, string, error) {
+	if err := checkScopesHeld(scopes, grantorScopes); err != nil {
+		return nil, "", err
+	}
+
+	prefix, err := randomHex(6)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate key prefix: %w", err)
+	}
+
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate key secret: %w", err)
+	}
+
+	token := apiKeyTokenPrefix + prefix + "_" + secret
+
+	key, err := s.apiKeyRepo.Create(ctx, &domain.APIKey{
+		Name:      name,
+		Prefix:    prefix,
+		Hash:      hashAPIKey(token),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return key, token, nil
+}
+
+func (s *APIKeyService) GetAll(ctx context.Context) ([]*domain.APIKey, error) {
+	return s.apiKeyRepo.GetAll(ctx)
+}
+
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, id int) error {
+	revoked, err := s.apiKeyRepo.Revoke(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if !revoked {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// Authenticate returns the key of the token or ErrInvalidAPIKey.
+// The deprecated AUTH_TOKEN, if configured, is accepted as a key with domain.LegacyTokenScopes.
+func (s *APIKeyService) Authenticate(ctx context.Context, token string) (*domain.APIKey, error) {
+	if token == "" {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if s.legacyToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.legacyToken)) == 1 {
+		return &domain.APIKey{Name: "AUTH_TOKEN", Scopes: domain.LegacyTokenScopes}, nil
+	}
+
+	prefix, _, ok := strings.Cut(strings.TrimPrefix(token, apiKeyTokenPrefix), "_")
+	if !ok || !strings.HasPrefix(token, apiKeyTokenPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.apiKeyRepo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	if key == nil || subtle.ConstantTimeCompare([]byte(hashAPIKey(token)), []byte(key.Hash)) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if !key.IsActive(time.Now()) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if err := s.apiKeyRepo.TouchLastUsed(ctx, key.ID, apiKeyLastUsedInterval); err != nil {
+		logger.FromContext(ctx).Error("error while touch API key: ", err)
+	}
+
+	return key, nil
+}
+
+func hashAPIKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	bytes := make([]byte, n)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"hr-server/internal/domain"
+)
+
+type AuditService struct {
+	auditRepo AuditRepository
+}
+
+func NewAuditService(auditRepo AuditRepository) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+	}
+}
+
+// Record stores the audit entry, the result is derived from the response status
+func (s *AuditService) Record(ctx context.Context, entry *domain.AuditEntry) error {
+	entry.Result = domain.AuditResultSuccess
+	if entry.Status >= 400 {
+		entry.Result = domain.AuditResultFailure
+	}
+
+	return s.auditRepo.Create(ctx, entry)
+}
+
+func (s *AuditService) GetAuditPage(ctx context.Context, filter domain.AuditFilter, page domain.PageRequest) (*domain.Page[*domain.AuditEntry], error) {
+	return s.auditRepo.GetPage(ctx, filter, page)
+}
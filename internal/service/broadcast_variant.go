@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"hr-server/internal/domain"
+	"hr-server/internal/logger"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"text/template"
+
+	"github.com/sirupsen/logrus"
+)
+
+// broadcastCallbackPrefix starts the callback data of the broadcast buttons: "broadcast:<id>:<variant>"
+const broadcastCallbackPrefix = "broadcast:"
+
+var ErrUnknownCallback = errors.New("unknown callback data")
+
+// broadcastVariant is a version of the broadcast message with its own counters
+type broadcastVariant struct {
+	index      int
+	name       string
+	weight     int
+	buttonText string
+	buttonURL  string
+	message    string
+	template   *template.Template
+	links      []*domain.TrackedLink // tracked links of the message and the button, the longest first
+
+	sent      atomic.Int64
+	failed    atomic.Int64
+	responses atomic.Int64 // recipients who pressed the button, each one is counted once by the repository
+}
+
+// newBroadcastVariants parses the messages of the variants, a notification without variants has a single one
+func newBroadcastVariants(data *domain.NotificationData) ([]*broadcastVariant, error) {
+	variants := data.Variants
+	if len(variants) == 0 {
+		variants = []domain.NotificationVariant{{
+			Message:    data.Message,
+			Weight:     1,
+			ButtonText: data.ButtonText,
+			ButtonURL:  data.ButtonURL,
+		}}
+	}
+
+	result := make([]*broadcastVariant, 0, len(variants))
+	for i, variant := range variants {
+		name := variantName(i)
+
+		tmpl, err := parseMessageTemplate(variant.Message)
+		if err != nil {
+			return nil, fmt.Errorf("variant %s: %w", name, err)
+		}
+
+		result = append(result, &broadcastVariant{
+			index:      i,
+			name:       name,
+			weight:     max(variant.Weight, 1),
+			buttonText: variant.ButtonText,
+			buttonURL:  variant.ButtonURL,
+			message:    variant.Message,
+			template:   tmpl,
+		})
+	}
+
+	return result, nil
+}
+
+// variantFor assigns the recipient to a variant by the hash of the broadcast ID and the Telegram ID,
+// so a recipient always gets the same variant of the broadcast, but the groups differ between broadcasts
+func (b *broadcast) variantFor(telegramID int64) *broadcastVariant {
+	if len(b.variants) == 1 {
+		return b.variants[0]
+	}
+
+	totalWeight := 0
+	for _, variant := range b.variants {
+		totalWeight += variant.weight
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(b.info.ID))
+	_ = binary.Write(hash, binary.BigEndian, telegramID)
+	point := int(hash.Sum32() % uint32(totalWeight))
+
+	for _, variant := range b.variants {
+		if point < variant.weight {
+			return variant
+		}
+		point -= variant.weight
+	}
+
+	return b.variants[len(b.variants)-1]
+}
+
+// urls returns the links of the message and the button to track
+func (v *broadcastVariant) urls() []string {
+	urls := extractURLs(v.message)
+	if v.buttonURL != "" && !slices.Contains(urls, v.buttonURL) {
+		urls = append(urls, v.buttonURL)
+	}
+
+	return urls
+}
+
+// track replaces the tracked links in the rendered message and the button link with the redirects for the recipient
+func (v *broadcastVariant) track(linkService *LinkService, message string, telegramID int64) (string, string) {
+	buttonURL := v.buttonURL
+	if len(v.links) == 0 {
+		return message, buttonURL
+	}
+
+	replacements := make([]string, 0, 2*len(v.links))
+	for _, link := range v.links {
+		trackedURL := linkService.TrackedURL(link.ID, telegramID)
+		replacements = append(replacements, link.URL, trackedURL)
+		if link.URL == v.buttonURL {
+			buttonURL = trackedURL
+		}
+	}
+
+	return strings.NewReplacer(replacements...).Replace(message), buttonURL
+}
+
+func (v *broadcastVariant) stats() domain.BroadcastVariant {
+	stats := domain.BroadcastVariant{
+		Name:      v.name,
+		Weight:    v.weight,
+		Sent:      v.sent.Load(),
+		Failed:    v.failed.Load(),
+		Responses: v.responses.Load(),
+	}
+	stats.ResponseRate = responseRate(stats.Responses, stats.Sent)
+
+	return stats
+}
+
+// variantStats returns the names and the weights of the variants to store
+func variantStats(variants []*broadcastVariant) []domain.BroadcastVariant {
+	stats := make([]domain.BroadcastVariant, 0, len(variants))
+	for _, variant := range variants {
+		stats = append(stats, domain.BroadcastVariant{Name: variant.name, Weight: variant.weight})
+	}
+
+	return stats
+}
+
+func variantName(index int) string {
+	return string(rune('A' + index))
+}
+
+func responseRate(responses, sent int64) float64 {
+	if sent == 0 {
+		return 0
+	}
+
+	return float64(responses) / float64(sent)
+}
+
+func broadcastCallbackData(broadcastID string, variant int) string {
+	return broadcastCallbackPrefix + broadcastID + ":" + strconv.Itoa(variant)
+}
+
+// HandleCallback records the press of a broadcast button by the recipient, repeated presses are counted once.
+// The presses are stored in the database, so they are recorded after the broadcast is forgotten or the app is restarted.
+// Returns ErrUnknownCallback if the data isn't of a broadcast button.
+func (s *NotificationService) HandleCallback(ctx context.Context, telegramID int64, data string) error {
+	rest, ok := strings.CutPrefix(data, broadcastCallbackPrefix)
+	if !ok {
+		return ErrUnknownCallback
+	}
+
+	id, index, ok := strings.Cut(rest, ":")
+	if !ok {
+		return ErrUnknownCallback
+	}
+
+	variantIndex, err := strconv.Atoi(index)
+	if err != nil {
+		return ErrUnknownCallback
+	}
+
+	// The forgotten broadcasts are checked by their stored variants
+	variantCount := 0
+	b, err := s.getBroadcast(id)
+	switch {
+	case err == nil:
+		variantCount = len(b.variants)
+	case errors.Is(err, ErrBroadcastNotFound):
+		stored, err := s.broadcastRepo.GetVariants(ctx, id)
+		if err != nil {
+			return err
+		}
+		if len(stored) == 0 {
+			return ErrBroadcastNotFound
+		}
+		variantCount = len(stored)
+	default:
+		return err
+	}
+
+	if variantIndex < 0 || variantIndex >= variantCount {
+		return ErrUnknownCallback
+	}
+	name := variantName(variantIndex)
+
+	recorded, err := s.broadcastRepo.CreateResponse(ctx, id, name, telegramID)
+	if err != nil {
+		return err
+	}
+	if recorded && b != nil {
+		b.variants[variantIndex].responses.Add(1)
+	}
+
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		logger.BroadcastIDField: id,
+		"variant":               name,
+		"telegram_id":           telegramID,
+		"repeated":              !recorded,
+	}).Debug("broadcast response recorded")
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hr-server/internal/domain"
+	"hr-server/internal/repository"
+)
+
+var ErrCampaignExists = errors.New("campaign with this name already exists")
+
+type CampaignService struct {
+	campaignRepo CampaignRepository
+}
+
+func NewCampaignService(campaignRepo CampaignRepository) *CampaignService {
+	return &CampaignService{
+		campaignRepo: campaignRepo,
+	}
+}
+
+func (s *CampaignService) CreateCampaign(ctx context.Context, name string) (*domain.Campaign, error) {
+	campaign, err := s.campaignRepo.Create(ctx, name)
+	if repository.IsDuplicate(err) {
+		return nil, ErrCampaignExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create campaign: %w", err)
+	}
+
+	return campaign, nil
+}
+
+func (s *CampaignService) GetAll(ctx context.Context) ([]*domain.Campaign, error) {
+	return s.campaignRepo.GetAll(ctx)
+}
+
+func (s *CampaignService) GetStats(ctx context.Context) ([]*domain.GroupStats, error) {
+	return s.campaignRepo.GetStats(ctx)
+}
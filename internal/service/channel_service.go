@@ -1,27 +1,55 @@
 package service
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"hr-server/config"
 	"hr-server/internal/domain"
-	"hr-server/internal/repository"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCampaignNotFound = errors.New("campaign not found")
+	ErrSourceNotFound   = errors.New("source not found")
+	ErrChannelExpired   = errors.New("channel code is expired")
+	ErrChannelFull      = errors.New("channel reached its usage cap")
+	ErrChannelAmbiguous = errors.New("several channels have this name")
 )
 
 type ChannelService struct {
-	channelRepo   *repository.ChannelRepository
-	tgBotStartURL string
+	channelRepo  ChannelRepository
+	campaignRepo CampaignRepository
+	sourceRepo   SourceRepository
+	tgBotURL     string
 }
 
-func NewChannelService(cfg *config.Config, channelRepo *repository.ChannelRepository) *ChannelService {
+func NewChannelService(
+	cfg *config.Config,
+	channelRepo ChannelRepository,
+	campaignRepo CampaignRepository,
+	sourceRepo SourceRepository,
+) *ChannelService {
 	return &ChannelService{
-		channelRepo:   channelRepo,
-		tgBotStartURL: cfg.TgBot.URL + "?start=",
+		channelRepo:  channelRepo,
+		campaignRepo: campaignRepo,
+		sourceRepo:   sourceRepo,
+		tgBotURL:     cfg.TgBot.URL,
 	}
 }
 
-func (s *ChannelService) GenerateChannel(channelName string) (*domain.Channel, error) {
+func (s *ChannelService) GenerateChannel(ctx context.Context, channelName string, attrs domain.ChannelAttributes) (*domain.Channel, error) {
+	if attrs.LinkType == "" {
+		attrs.LinkType = domain.ChannelLinkTypeStart
+	}
+
+	if err := s.checkGrouping(ctx, attrs.CampaignID, attrs.SourceID); err != nil {
+		return nil, err
+	}
+
 	// Generate unique channel code
 	code, err := s.generateUniqueCode()
 	if err != nil {
@@ -29,21 +57,33 @@ func (s *ChannelService) GenerateChannel(channelName string) (*domain.Channel, e
 	}
 
 	// Create channel with channel code in database
-	channel, err := s.channelRepo.Create(channelName, code)
+	channel, err := s.channelRepo.Create(ctx, &domain.Channel{
+		Name:       channelName,
+		Code:       code,
+		LinkType:   attrs.LinkType,
+		CampaignID: attrs.CampaignID,
+		SourceID:   attrs.SourceID,
+		Tags:       normalizeTags(attrs.Tags),
+		ExpiresAt:  attrs.ExpiresAt,
+		MaxUsers:   attrs.MaxUsers,
+		Timezone:   attrs.Timezone,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create channel: %w", err)
 	}
 
-	channel.Link = s.tgBotStartURL + channel.Code
+	if err := s.fill(ctx, channel); err != nil {
+		return nil, err
+	}
 
 	return channel, nil
 }
 
-func (s *ChannelService) GenerateBulkChannel(channelNames []string) ([]*domain.Channel, error) {
+func (s *ChannelService) GenerateBulkChannel(ctx context.Context, channelNames []string, attrs domain.ChannelAttributes) ([]*domain.Channel, error) {
 	var channels []*domain.Channel
 
 	for i, channelName := range channelNames {
-		channel, err := s.GenerateChannel(channelName)
+		channel, err := s.GenerateChannel(ctx, channelName, attrs)
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate channel code for '%s' at index %d: %w", channelName, i+1, err)
 		}
@@ -53,16 +93,208 @@ func (s *ChannelService) GenerateBulkChannel(channelNames []string) ([]*domain.C
 	return channels, nil
 }
 
-func (s *ChannelService) GetChannelByCode(code string) (*domain.Channel, error) {
-	return s.channelRepo.GetByCode(code)
+// UpdateChannel updates the name, grouping, expiry, usage cap and timezone of the channel, returns nil if the channel doesn't exist
+func (s *ChannelService) UpdateChannel(ctx context.Context, code, name string, attrs domain.ChannelAttributes) (*domain.Channel, error) {
+	channel, err := s.channelRepo.GetByCode(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel by code '%s': %w", code, err)
+	}
+
+	if channel == nil {
+		return nil, nil
+	}
+
+	if err := s.checkGrouping(ctx, attrs.CampaignID, attrs.SourceID); err != nil {
+		return nil, err
+	}
+
+	if name != "" {
+		channel.Name = name
+	}
+	channel.CampaignID = attrs.CampaignID
+	channel.SourceID = attrs.SourceID
+	channel.Tags = normalizeTags(attrs.Tags)
+	channel.ExpiresAt = attrs.ExpiresAt
+	channel.MaxUsers = attrs.MaxUsers
+	channel.Timezone = attrs.Timezone
+
+	if err := s.channelRepo.Update(ctx, channel); err != nil {
+		return nil, fmt.Errorf("failed to update channel: %w", err)
+	}
+
+	return s.GetChannelByCode(ctx, code)
 }
 
-func (s *ChannelService) GetAll() ([]*domain.Channel, error) {
-	return s.channelRepo.GetAll()
+func (s *ChannelService) GetChannelByCode(ctx context.Context, code string) (*domain.Channel, error) {
+	channel, err := s.channelRepo.GetByCode(ctx, code)
+	if err != nil || channel == nil {
+		return nil, err
+	}
+
+	if err := s.fill(ctx, channel); err != nil {
+		return nil, err
+	}
+
+	return channel, nil
 }
 
-func (s *ChannelService) GetChannelByID(id int) (*domain.Channel, error) {
-	return s.channelRepo.GetByID(id)
+func (s *ChannelService) GetChannelsPage(
+	ctx context.Context,
+	filter domain.ChannelFilter,
+	page domain.PageRequest,
+) (*domain.Page[*domain.Channel], error) {
+	filter.Tags = normalizeTags(filter.Tags)
+
+	channels, err := s.channelRepo.GetPage(ctx, filter, page)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.fill(ctx, channels.Items...); err != nil {
+		return nil, err
+	}
+
+	return channels, nil
+}
+
+func (s *ChannelService) GetChannelByID(ctx context.Context, id int) (*domain.Channel, error) {
+	channel, err := s.channelRepo.GetByID(ctx, id)
+	if err != nil || channel == nil {
+		return nil, err
+	}
+
+	if err := s.fill(ctx, channel); err != nil {
+		return nil, err
+	}
+
+	return channel, nil
+}
+
+// ResolveChannel finds a channel by its code or, failing that, by its unique name.
+// Returns nil if nothing matches and ErrChannelAmbiguous if the name is not unique.
+func (s *ChannelService) ResolveChannel(ctx context.Context, ref string) (*domain.Channel, error) {
+	channel, err := s.channelRepo.GetByCode(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	if channel != nil {
+		return channel, nil
+	}
+
+	channels, err := s.channelRepo.GetByName(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(channels) {
+	case 0:
+		return nil, nil
+	case 1:
+		return channels[0], nil
+	default:
+		return nil, fmt.Errorf("channel '%s': %w", ref, ErrChannelAmbiguous)
+	}
+}
+
+// CheckAvailability returns ErrChannelExpired or ErrChannelFull if the channel can't attribute new users
+func (s *ChannelService) CheckAvailability(channel *domain.Channel) error {
+	if channel.IsExpired(time.Now()) {
+		return ErrChannelExpired
+	}
+
+	if channel.IsFull() {
+		return ErrChannelFull
+	}
+
+	return nil
+}
+
+// BuildLink builds a bot deep link for the channel code, e.g. https://t.me/bot?start=<code>
+func (s *ChannelService) BuildLink(code, linkType string) string {
+	if linkType == "" {
+		linkType = domain.ChannelLinkTypeStart
+	}
+
+	return s.tgBotURL + "?" + linkType + "=" + code
+}
+
+// fill sets the link, users count, remaining capacity and status of the channels in place
+func (s *ChannelService) fill(ctx context.Context, channels ...*domain.Channel) error {
+	ids := make([]int, 0, len(channels))
+	for _, channel := range channels {
+		ids = append(ids, channel.ID)
+	}
+
+	counts, err := s.channelRepo.CountUsers(ctx, ids...)
+	if err != nil {
+		return fmt.Errorf("failed to count channel users: %w", err)
+	}
+
+	now := time.Now()
+	for _, channel := range channels {
+		channel.Link = s.BuildLink(channel.Code, channel.LinkType)
+		channel.UsersCount = counts[channel.ID]
+		channel.RemainingCapacity = nil
+
+		if channel.MaxUsers != nil {
+			remaining := max(int64(*channel.MaxUsers)-channel.UsersCount, 0)
+			channel.RemainingCapacity = &remaining
+		}
+
+		switch {
+		case channel.IsExpired(now):
+			channel.Status = domain.ChannelStatusExpired
+		case channel.IsFull():
+			channel.Status = domain.ChannelStatusFull
+		default:
+			channel.Status = domain.ChannelStatusActive
+		}
+	}
+
+	return nil
+}
+
+// checkGrouping checks that the referenced campaign and source exist
+func (s *ChannelService) checkGrouping(ctx context.Context, campaignID, sourceID *int) error {
+	if campaignID != nil {
+		campaign, err := s.campaignRepo.GetByID(ctx, *campaignID)
+		if err != nil {
+			return fmt.Errorf("failed to get campaign: %w", err)
+		}
+		if campaign == nil {
+			return fmt.Errorf("campaign %d: %w", *campaignID, ErrCampaignNotFound)
+		}
+	}
+
+	if sourceID != nil {
+		source, err := s.sourceRepo.GetByID(ctx, *sourceID)
+		if err != nil {
+			return fmt.Errorf("failed to get source: %w", err)
+		}
+		if source == nil {
+			return fmt.Errorf("source %d: %w", *sourceID, ErrSourceNotFound)
+		}
+	}
+
+	return nil
+}
+
+// normalizeTags trims and lowercases the tags and drops empty and duplicate ones
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	return normalized
 }
 
 func (s *ChannelService) generateUniqueCode() (string, error) {
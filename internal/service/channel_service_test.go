@@ -0,0 +1,181 @@
+package service_test
+
+import (
+	"hr-server/internal/domain"
+	"hr-server/internal/service"
+	"hr-server/internal/service/servicetest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelService_GenerateChannel(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+
+	campaign, err := s.Campaign.CreateCampaign(t.Context(), "spring-2026")
+	require.NoError(t, err)
+
+	channel, err := s.Channel.GenerateChannel(t.Context(), "hh.ru", domain.ChannelAttributes{
+		CampaignID: &campaign.ID,
+		Tags:       []string{" Backend ", "backend", "", "Go"},
+	})
+	require.NoError(t, err)
+	assert.Len(t, channel.Code, 32)
+	assert.Equal(t, domain.ChannelLinkTypeStart, channel.LinkType)
+	assert.Equal(t, servicetest.BotURL+"?start="+channel.Code, channel.Link)
+	assert.Equal(t, []string{"backend", "go"}, channel.Tags)
+	assert.Equal(t, domain.ChannelStatusActive, channel.Status)
+
+	t.Run("startapp link", func(t *testing.T) {
+		channel, err := s.Channel.GenerateChannel(t.Context(), "vk", domain.ChannelAttributes{LinkType: domain.ChannelLinkTypeStartApp})
+		require.NoError(t, err)
+		assert.Equal(t, servicetest.BotURL+"?startapp="+channel.Code, channel.Link)
+	})
+
+	t.Run("missing campaign", func(t *testing.T) {
+		missing := 100
+		_, err := s.Channel.GenerateChannel(t.Context(), "vk", domain.ChannelAttributes{CampaignID: &missing})
+		assert.ErrorIs(t, err, service.ErrCampaignNotFound)
+	})
+
+	t.Run("missing source", func(t *testing.T) {
+		missing := 100
+		_, err := s.Channel.GenerateChannel(t.Context(), "vk", domain.ChannelAttributes{SourceID: &missing})
+		assert.ErrorIs(t, err, service.ErrSourceNotFound)
+	})
+}
+
+func TestChannelService_GenerateBulkChannel(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+
+	channels, err := s.Channel.GenerateBulkChannel(t.Context(), []string{"hh.ru", "vk", "tg"}, domain.ChannelAttributes{})
+	require.NoError(t, err)
+	require.Len(t, channels, 3)
+	assert.NotEqual(t, channels[0].Code, channels[1].Code)
+
+	missing := 100
+	_, err = s.Channel.GenerateBulkChannel(t.Context(), []string{"hh.ru"}, domain.ChannelAttributes{SourceID: &missing})
+	assert.ErrorIs(t, err, service.ErrSourceNotFound)
+}
+
+func TestChannelService_UpdateChannel(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+
+	channel, err := s.Channel.GenerateChannel(t.Context(), "hh.ru", domain.ChannelAttributes{Tags: []string{"old"}})
+	require.NoError(t, err)
+
+	source, err := s.Source.CreateSource(t.Context(), "job boards")
+	require.NoError(t, err)
+
+	maxUsers := 10
+	updated, err := s.Channel.UpdateChannel(t.Context(), channel.Code, "hh", domain.ChannelAttributes{
+		SourceID: &source.ID,
+		Tags:     []string{"new"},
+		MaxUsers: &maxUsers,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "hh", updated.Name)
+	assert.Equal(t, &source.ID, updated.SourceID)
+	assert.Equal(t, []string{"new"}, updated.Tags)
+	require.NotNil(t, updated.RemainingCapacity)
+	assert.EqualValues(t, 10, *updated.RemainingCapacity)
+
+	updated, err = s.Channel.UpdateChannel(t.Context(), "missing", "hh", domain.ChannelAttributes{})
+	require.NoError(t, err)
+	assert.Nil(t, updated)
+}
+
+func TestChannelService_Status(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+
+	past := time.Now().Add(-time.Hour)
+	expired, err := s.Channel.GenerateChannel(t.Context(), "expired", domain.ChannelAttributes{ExpiresAt: &past})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChannelStatusExpired, expired.Status)
+	assert.ErrorIs(t, s.Channel.CheckAvailability(expired), service.ErrChannelExpired)
+
+	maxUsers := 1
+	full, err := s.Channel.GenerateChannel(t.Context(), "full", domain.ChannelAttributes{MaxUsers: &maxUsers})
+	require.NoError(t, err)
+	s.AddUser(t, 1, "alice", &full.ID)
+
+	full, err = s.Channel.GetChannelByCode(t.Context(), full.Code)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChannelStatusFull, full.Status)
+	assert.EqualValues(t, 1, full.UsersCount)
+	assert.EqualValues(t, 0, *full.RemainingCapacity)
+	assert.ErrorIs(t, s.Channel.CheckAvailability(full), service.ErrChannelFull)
+
+	active, err := s.Channel.GenerateChannel(t.Context(), "active", domain.ChannelAttributes{})
+	require.NoError(t, err)
+	assert.NoError(t, s.Channel.CheckAvailability(active))
+
+	for status, name := range map[string]string{
+		domain.ChannelStatusExpired: "expired",
+		domain.ChannelStatusFull:    "full",
+		domain.ChannelStatusActive:  "active",
+	} {
+		page, err := s.Channel.GetChannelsPage(t.Context(), domain.ChannelFilter{Status: status}, domain.PageRequest{})
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1, status)
+		assert.Equal(t, name, page.Items[0].Name)
+	}
+}
+
+func TestChannelService_GetChannelsPage(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+
+	for _, attrs := range []struct {
+		name string
+		tags []string
+	}{
+		{"hh.ru", []string{"backend", "go"}},
+		{"vk", []string{"backend"}},
+		{"tg", []string{"go"}},
+	} {
+		_, err := s.Channel.GenerateChannel(t.Context(), attrs.name, domain.ChannelAttributes{Tags: attrs.tags})
+		require.NoError(t, err)
+	}
+
+	page, err := s.Channel.GetChannelsPage(t.Context(), domain.ChannelFilter{Tags: []string{"GO", "backend"}}, domain.PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "hh.ru", page.Items[0].Name)
+
+	page, err = s.Channel.GetChannelsPage(t.Context(), domain.ChannelFilter{}, domain.PageRequest{SortBy: "name", SortOrder: domain.SortOrderAsc, Limit: 2})
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, page.TotalCount)
+	require.Len(t, page.Items, 2)
+	assert.Equal(t, "hh.ru", page.Items[0].Name)
+	assert.NotEmpty(t, page.NextCursor)
+
+	_, err = s.Channel.GetChannelsPage(t.Context(), domain.ChannelFilter{}, domain.PageRequest{Cursor: page.NextCursor})
+	assert.ErrorIs(t, err, domain.ErrInvalidCursor, "cursor of another sort field")
+}
+
+func TestChannelService_ResolveChannel(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+
+	channel, err := s.Channel.GenerateChannel(t.Context(), "hh.ru", domain.ChannelAttributes{})
+	require.NoError(t, err)
+
+	resolved, err := s.Channel.ResolveChannel(t.Context(), channel.Code)
+	require.NoError(t, err)
+	assert.Equal(t, channel.ID, resolved.ID)
+
+	resolved, err = s.Channel.ResolveChannel(t.Context(), "hh.ru")
+	require.NoError(t, err)
+	assert.Equal(t, channel.ID, resolved.ID)
+
+	resolved, err = s.Channel.ResolveChannel(t.Context(), "unknown")
+	require.NoError(t, err)
+	assert.Nil(t, resolved)
+
+	_, err = s.Channel.GenerateChannel(t.Context(), "hh.ru", domain.ChannelAttributes{})
+	require.NoError(t, err)
+
+	_, err = s.Channel.ResolveChannel(t.Context(), "hh.ru")
+	assert.ErrorIs(t, err, service.ErrChannelAmbiguous)
+}
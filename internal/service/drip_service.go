@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hr-server/config"
+	"hr-server/internal/domain"
+	"hr-server/internal/logger"
+	"hr-server/internal/metrics"
+	"net/http"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrDripSequenceNotFound = errors.New("drip sequence not found")
+
+// dripClaimLease is how long the claimed enrollments are skipped by the other runs, a step left by a crash
+// is retried after it. It's much longer than sending a batch takes.
+const dripClaimLease = 10 * time.Minute
+
+// DripService enrolls the new users into the drip sequences and sends the due steps.
+// Run polls for the due steps, an enrollment wakes it up so that the steps without a delay are sent right away.
+// The steps due in the quiet hours of the user are sent when the hours end.
+type DripService struct {
+	dripRepo        DripRepository
+	userRepo        UserRepository
+	channelService  *ChannelService
+	telegramService TelegramSender
+	quietHours      *QuietHours
+
+	pollInterval    time.Duration
+	batchSize       int
+	messageInterval time.Duration
+
+	wake chan struct{}
+}
+
+func NewDripService(
+	cfg *config.Config,
+	dripRepo DripRepository,
+	userRepo UserRepository,
+	channelService *ChannelService,
+	telegramService TelegramSender,
+) *DripService {
+	return &DripService{
+		dripRepo:        dripRepo,
+		userRepo:        userRepo,
+		channelService:  channelService,
+		telegramService: telegramService,
+		quietHours:      NewQuietHours(cfg),
+		pollInterval:    cfg.Drip.PollInterval,
+		batchSize:       cfg.Drip.BatchSize,
+		messageInterval: cfg.Notifications.MessageInterval,
+		wake:            make(chan struct{}, 1),
+	}
+}
+
+// CreateSequence creates an active sequence. The messages are templates like the broadcast messages,
+// an invalid one is rejected with ErrInvalidMessageTemplate.
+func (s *DripService) CreateSequence(ctx context.Context, sequence *domain.DripSequence) (*domain.DripSequence, error) {
+	for i, step := range sequence.Steps {
+		if _, err := parseMessageTemplate(step.Message); err != nil {
+			return nil, fmt.Errorf("step %d: %w", i, err)
+		}
+	}
+
+	if sequence.ChannelID != nil {
+		channel, err := s.channelService.GetChannelByID(ctx, *sequence.ChannelID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get channel: %w", err)
+		}
+		if channel == nil {
+			return nil, fmt.Errorf("channel %d: %w", *sequence.ChannelID, ErrChannelNotFound)
+		}
+	}
+
+	sequence.Active = true
+
+	return s.dripRepo.CreateSequence(ctx, sequence)
+}
+
+func (s *DripService) GetSequences(ctx context.Context) ([]*domain.DripSequence, error) {
+	return s.dripRepo.GetSequences(ctx)
+}
+
+func (s *DripService) GetSequence(ctx context.Context, id int) (*domain.DripSequence, error) {
+	sequence, err := s.dripRepo.GetSequence(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sequence == nil {
+		return nil, ErrDripSequenceNotFound
+	}
+
+	return sequence, nil
+}
+
+// SetSequenceActive enables or disables the sequence. The enrollments of a disabled sequence are kept
+// and their overdue steps are sent once it's enabled again.
+func (s *DripService) SetSequenceActive(ctx context.Context, id int, active bool) (*domain.DripSequence, error) {
+	found, err := s.dripRepo.SetSequenceActive(ctx, id, active)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrDripSequenceNotFound
+	}
+
+	return s.GetSequence(ctx, id)
+}
+
+// DeleteSequence deletes the sequence with its enrollments
+func (s *DripService) DeleteSequence(ctx context.Context, id int) error {
+	found, err := s.dripRepo.DeleteSequence(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrDripSequenceNotFound
+	}
+
+	return nil
+}
+
+// Enroll enrolls the new user into the active sequences of the channel and the ones without a channel
+func (s *DripService) Enroll(ctx context.Context, telegramID int64, channelID *int) error {
+	enrolled, err := s.dripRepo.Enroll(ctx, telegramID, channelID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if enrolled > 0 {
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"telegram_id": telegramID,
+			"sequences":   enrolled,
+		}).Debug("user enrolled into drip sequences")
+
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// RecordReply marks the active enrollments of the user as replied, for the step conditions
+func (s *DripService) RecordReply(ctx context.Context, telegramID int64) error {
+	return s.dripRepo.RecordReply(ctx, telegramID, time.Now())
+}
+
+// Run sends the due steps every poll interval and on enrollments until ctx is cancelled
+func (s *DripService) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	logrus.WithField("poll_interval", s.pollInterval).Info("drip scheduler started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			logrus.Info("drip scheduler stopped")
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+
+		if _, err := s.SendDue(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			logrus.Error("error while send due drip steps: ", err)
+		}
+	}
+}
+
+// SendDue claims and sends the steps due by now in batches and returns the number of processed steps.
+// Each step is sent, skipped by its condition or failed once, a failed step isn't retried.
+// A step due in the quiet hours of the user is postponed to their end.
+func (s *DripService) SendDue(ctx context.Context, now time.Time) (int, error) {
+	sequences := make(map[int]*domain.DripSequence)
+	processed := 0
+
+	for {
+		enrollments, err := s.dripRepo.ClaimDueEnrollments(ctx, now, now.Add(dripClaimLease), s.batchSize)
+		if err != nil {
+			return processed, err
+		}
+
+		for _, enrollment := range enrollments {
+			if err := ctx.Err(); err != nil {
+				return processed, err
+			}
+
+			sent, err := s.processStep(ctx, enrollment, sequences, now)
+			if err != nil {
+				return processed, err
+			}
+			processed++
+
+			// Rate limiting like the broadcast workers
+			if sent {
+				select {
+				case <-ctx.Done():
+					return processed, ctx.Err()
+				case <-time.After(s.messageInterval):
+				}
+			}
+		}
+
+		if len(enrollments) < s.batchSize {
+			return processed, nil
+		}
+	}
+}
+
+// processStep sends or skips the next step of the enrollment and moves it to the following step,
+// returns true if a message was sent. The sequences are cached for the run.
+func (s *DripService) processStep(ctx context.Context, enrollment *domain.DripEnrollment, sequences map[int]*domain.DripSequence, now time.Time) (bool, error) {
+	sequence, ok := sequences[enrollment.SequenceID]
+	if !ok {
+		var err error
+		sequence, err = s.dripRepo.GetSequence(ctx, enrollment.SequenceID)
+		if err != nil {
+			return false, err
+		}
+		sequences[enrollment.SequenceID] = sequence
+	}
+
+	position := enrollment.NextStep
+	if sequence == nil || position >= len(sequence.Steps) {
+		enrollment.Status = domain.DripEnrollmentCompleted
+		enrollment.NextRunAt = nil
+		return false, s.dripRepo.UpdateEnrollment(ctx, enrollment, position, "")
+	}
+	step := sequence.Steps[position]
+
+	log := logger.FromContext(ctx).WithFields(logrus.Fields{
+		"sequence_id": sequence.ID,
+		"step":        position,
+		"telegram_id": enrollment.TelegramID,
+	})
+
+	user, err := s.userRepo.GetWithChannelByTelegramID(ctx, enrollment.TelegramID)
+	if err != nil {
+		return false, err
+	}
+
+	// The messages can't be delivered to blocked, erased and deleted users
+	if user == nil || user.Status != domain.UserStatusActive {
+		enrollment.Status = domain.DripEnrollmentCancelled
+		enrollment.NextRunAt = nil
+		return false, s.dripRepo.UpdateEnrollment(ctx, enrollment, position, "")
+	}
+
+	outcome := domain.DripStepSkipped
+	if dripStepApplies(step, enrollment) {
+		if opensAt := s.quietHours.WindowOpensAt(user, now); opensAt.After(now) {
+			enrollment.NextRunAt = &opensAt
+			return false, s.dripRepo.UpdateEnrollment(ctx, enrollment, position, "")
+		}
+
+		outcome = s.sendStep(ctx, step, user, log)
+	}
+	metrics.DripStepProcessed(outcome)
+
+	enrollment.NextStep++
+	switch {
+	case outcome == domain.DripStepFailed && user.Status == domain.UserStatusBlocked:
+		enrollment.Status = domain.DripEnrollmentCancelled
+		enrollment.NextRunAt = nil
+	case enrollment.NextStep >= len(sequence.Steps):
+		enrollment.Status = domain.DripEnrollmentCompleted
+		enrollment.NextRunAt = nil
+	default:
+		nextRunAt := enrollment.EnrolledAt.Add(sequence.Steps[enrollment.NextStep].Delay())
+		enrollment.NextRunAt = &nextRunAt
+	}
+
+	return outcome == domain.DripStepSent, s.dripRepo.UpdateEnrollment(ctx, enrollment, position, outcome)
+}
+
+// sendStep renders and sends the step, a user who blocked the bot is marked as blocked
+func (s *DripService) sendStep(ctx context.Context, step domain.DripStep, user *domain.UserWithChannel, log *logrus.Entry) string {
+	tmpl, err := parseMessageTemplate(step.Message)
+	if err != nil {
+		log.Error("error while parse drip message: ", err)
+		return domain.DripStepFailed
+	}
+
+	message, err := renderMessage(tmpl, user)
+	if err != nil {
+		log.Error("error while render drip message: ", err)
+		return domain.DripStepFailed
+	}
+
+	msg := tgbotapi.NewMessage(user.TelegramID, message)
+	msg.ParseMode = "Markdown"
+	if err := s.telegramService.SendMessage(ctx, user.TelegramID, msg); err != nil {
+		log.Error("error while send drip message: ", err)
+
+		// Telegram responds with 403 when the user blocked the bot or deleted the account
+		var tgErr *tgbotapi.Error
+		if errors.As(err, &tgErr) && tgErr.Code == http.StatusForbidden {
+			if err := s.userRepo.UpdateStatus(ctx, user.TelegramID, domain.UserStatusBlocked); err != nil {
+				log.Error("error while mark user as blocked: ", err)
+			} else {
+				user.Status = domain.UserStatusBlocked
+			}
+		}
+
+		return domain.DripStepFailed
+	}
+
+	return domain.DripStepSent
+}
+
+// dripStepApplies reports whether the condition of the step holds for the enrollment
+func dripStepApplies(step domain.DripStep, enrollment *domain.DripEnrollment) bool {
+	switch step.Condition {
+	case domain.DripConditionNoReply:
+		return enrollment.RepliedAt == nil
+	case domain.DripConditionReplied:
+		return enrollment.RepliedAt != nil
+	default:
+		return true
+	}
+}
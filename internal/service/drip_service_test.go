@@ -0,0 +1,208 @@
+package service_test
+
+import (
+	"hr-server/internal/domain"
+	"hr-server/internal/service"
+	"hr-server/internal/service/servicetest"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDripServices(t *testing.T) *servicetest.Services {
+	t.Helper()
+
+	cfg := servicetest.Config()
+	cfg.Notifications.MessageInterval = time.Millisecond
+	// The tests send at arbitrary times of day
+	cfg.QuietHours.Start = "00:00"
+	cfg.QuietHours.End = "00:00"
+
+	return servicetest.NewServices(cfg)
+}
+
+func TestDripService_SendDue(t *testing.T) {
+	s := newDripServices(t)
+
+	channel, err := s.Channel.GenerateChannel(t.Context(), "hh.ru", domain.ChannelAttributes{})
+	require.NoError(t, err)
+
+	onboarding, err := s.Drip.CreateSequence(t.Context(), &domain.DripSequence{
+		Name: "onboarding",
+		Steps: []domain.DripStep{
+			{DelayMinutes: 0, Message: `Hi, {{.FirstName | default "friend"}}`, Condition: domain.DripConditionAlways},
+			{DelayMinutes: 24 * 60, Message: "Day 1", Condition: domain.DripConditionNoReply},
+			{DelayMinutes: 3 * 24 * 60, Message: "Day 3", Condition: domain.DripConditionReplied},
+		},
+	})
+	require.NoError(t, err)
+	assert.True(t, onboarding.Active)
+
+	_, err = s.Drip.CreateSequence(t.Context(), &domain.DripSequence{
+		Name:      "hh.ru",
+		ChannelID: &channel.ID,
+		Steps:     []domain.DripStep{{DelayMinutes: 60, Message: "Welcome from hh.ru", Condition: domain.DripConditionAlways}},
+	})
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, _, err = s.User.CreateUser(t.Context(), 1, "alice", "Alice", nil)
+	require.NoError(t, err)
+	require.NoError(t, s.Drip.Enroll(t.Context(), 1, nil))
+	_, _, err = s.User.CreateUser(t.Context(), 2, "bob", "", &channel.ID)
+	require.NoError(t, err)
+	require.NoError(t, s.Drip.Enroll(t.Context(), 2, &channel.ID))
+	require.NoError(t, s.Drip.Enroll(t.Context(), 2, &channel.ID), "enrolled once")
+
+	textsAt := func(now time.Time) map[int64][]string {
+		t.Helper()
+
+		before := len(s.Telegram.Sent())
+		_, err := s.Drip.SendDue(t.Context(), now)
+		require.NoError(t, err)
+
+		texts := make(map[int64][]string)
+		for _, message := range s.Telegram.Sent()[before:] {
+			texts[message.ChatID] = append(texts[message.ChatID], message.Text)
+		}
+		return texts
+	}
+
+	assert.Equal(t, map[int64][]string{1: {"Hi, Alice"}, 2: {"Hi, friend"}}, textsAt(start.Add(time.Second)))
+	assert.Empty(t, textsAt(start.Add(time.Second)), "steps are sent once")
+	assert.Equal(t, map[int64][]string{2: {"Welcome from hh.ru"}}, textsAt(start.Add(2*time.Hour)))
+
+	require.NoError(t, s.Drip.RecordReply(t.Context(), 1))
+
+	assert.Equal(t, map[int64][]string{2: {"Day 1"}}, textsAt(start.Add(25*time.Hour)), "replied users skip no_reply steps")
+	assert.Equal(t, map[int64][]string{1: {"Day 3"}}, textsAt(start.Add(4*24*time.Hour)), "only replied users get replied steps")
+
+	result, err := s.Drip.GetSequence(t.Context(), onboarding.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DripEnrollmentCounts{Completed: 2}, result.Enrollments)
+	require.Len(t, result.Steps, 3)
+	assert.Equal(t, int64(2), result.Steps[0].Sent)
+	assert.Equal(t, int64(1), result.Steps[1].Sent)
+	assert.Equal(t, int64(1), result.Steps[1].Skipped)
+	assert.Equal(t, int64(1), result.Steps[2].Skipped)
+
+	t.Run("blocked users are cancelled", func(t *testing.T) {
+		s.AddUser(t, 3, "carol", nil)
+		require.NoError(t, s.Drip.Enroll(t.Context(), 3, nil))
+		s.Telegram.FailFor(3, &tgbotapi.Error{Code: http.StatusForbidden, Message: "Forbidden: bot was blocked by the user"})
+
+		_, err := s.Drip.SendDue(t.Context(), time.Now().Add(time.Second))
+		require.NoError(t, err)
+
+		user, err := s.User.GetUser(t.Context(), 3)
+		require.NoError(t, err)
+		assert.Equal(t, domain.UserStatusBlocked, user.Status)
+
+		result, err := s.Drip.GetSequence(t.Context(), onboarding.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), result.Enrollments.Cancelled)
+		assert.Equal(t, int64(1), result.Steps[0].Failed)
+	})
+
+	t.Run("inactive sequences don't enroll nor send", func(t *testing.T) {
+		_, err := s.Drip.SetSequenceActive(t.Context(), onboarding.ID, false)
+		require.NoError(t, err)
+
+		s.AddUser(t, 4, "dave", nil)
+		require.NoError(t, s.Drip.Enroll(t.Context(), 4, nil))
+		assert.Empty(t, textsAt(time.Now().Add(time.Second)))
+	})
+}
+
+func TestDripService_CreateSequence(t *testing.T) {
+	s := newDripServices(t)
+
+	_, err := s.Drip.CreateSequence(t.Context(), &domain.DripSequence{
+		Name:  "invalid",
+		Steps: []domain.DripStep{{Message: "ok"}, {Message: "Hi, {{.Nme}}"}},
+	})
+	assert.ErrorIs(t, err, service.ErrInvalidMessageTemplate)
+	assert.ErrorContains(t, err, "step 1")
+
+	channelID := 42
+	_, err = s.Drip.CreateSequence(t.Context(), &domain.DripSequence{
+		Name:      "unknown channel",
+		ChannelID: &channelID,
+		Steps:     []domain.DripStep{{Message: "ok"}},
+	})
+	assert.ErrorIs(t, err, service.ErrChannelNotFound)
+
+	_, err = s.Drip.GetSequence(t.Context(), 1)
+	assert.ErrorIs(t, err, service.ErrDripSequenceNotFound)
+	assert.ErrorIs(t, s.Drip.DeleteSequence(t.Context(), 1), service.ErrDripSequenceNotFound)
+}
+
+func TestDripService_SendDueClaimsEnrollments(t *testing.T) {
+	s := newDripServices(t)
+
+	_, err := s.Drip.CreateSequence(t.Context(), &domain.DripSequence{
+		Name:  "welcome",
+		Steps: []domain.DripStep{{Message: "Welcome", Condition: domain.DripConditionAlways}},
+	})
+	require.NoError(t, err)
+
+	for id := int64(1); id <= 10; id++ {
+		s.AddUser(t, id, "user", nil)
+		require.NoError(t, s.Drip.Enroll(t.Context(), id, nil))
+	}
+
+	// Overlapping runs, like two replicas, send each step once
+	now := time.Now().Add(time.Second)
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.Drip.SendDue(t.Context(), now)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, s.Telegram.Sent(), 10)
+}
+
+func TestDripService_QuietHours(t *testing.T) {
+	cfg := servicetest.Config()
+	cfg.Notifications.MessageInterval = time.Millisecond
+	cfg.QuietHours.Start = "21:00"
+	cfg.QuietHours.End = "09:00"
+	cfg.QuietHours.DefaultTimezone = "UTC"
+	s := servicetest.NewServices(cfg)
+
+	_, err := s.Drip.CreateSequence(t.Context(), &domain.DripSequence{
+		Name:  "welcome",
+		Steps: []domain.DripStep{{Message: "Welcome", Condition: domain.DripConditionAlways}},
+	})
+	require.NoError(t, err)
+
+	s.AddUser(t, 1, "alice", nil)
+	require.NoError(t, s.Drip.Enroll(t.Context(), 1, nil))
+
+	// 03:00 UTC after the enrollment
+	night := time.Now().UTC().Truncate(24 * time.Hour).Add(27 * time.Hour)
+	morning := night.Add(6 * time.Hour)
+
+	processed, err := s.Drip.SendDue(t.Context(), night)
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	assert.Empty(t, s.Telegram.Sent(), "held until the quiet hours end")
+
+	_, err = s.Drip.SendDue(t.Context(), morning.Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, s.Telegram.Sent())
+
+	_, err = s.Drip.SendDue(t.Context(), morning)
+	require.NoError(t, err)
+	assert.Len(t, s.Telegram.Sent(), 1)
+}
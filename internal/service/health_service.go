@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"hr-server/internal/domain"
+	"time"
+)
+
+const (
+	healthComponentDatabase   = "database"
+	healthComponentTelegram   = "telegram"
+	healthComponentBroadcasts = "broadcasts"
+
+	DefaultHealthCheckTimeout = 2 * time.Second
+)
+
+// DatabasePinger is the connection pool checked for readiness, implemented by *sql.DB
+type DatabasePinger interface {
+	PingContext(ctx context.Context) error
+	Stats() sql.DBStats
+}
+
+// BotStateReporter reports the state of the updates loop, implemented by TelegramService
+type BotStateReporter interface {
+	State() domain.BotState
+}
+
+// BroadcastStateReporter reports the state of the notification workers, implemented by NotificationService
+type BroadcastStateReporter interface {
+	State() domain.BroadcastState
+}
+
+type HealthService struct {
+	db                  DatabasePinger
+	telegramService     BotStateReporter
+	notificationService BroadcastStateReporter
+}
+
+func NewHealthService(
+	db DatabasePinger,
+	telegramService BotStateReporter,
+	notificationService BroadcastStateReporter,
+) *HealthService {
+	return &HealthService{
+		db:                  db,
+		telegramService:     telegramService,
+		notificationService: notificationService,
+	}
+}
+
+// CheckReadiness checks every component, the service is ready only if all of them are
+func (s *HealthService) CheckReadiness(ctx context.Context) *domain.HealthReport {
+	report := &domain.HealthReport{
+		Status: domain.HealthStatusOK,
+		Components: map[string]*domain.ComponentHealth{
+			healthComponentDatabase:   s.checkDatabase(ctx),
+			healthComponentTelegram:   s.checkTelegram(),
+			healthComponentBroadcasts: s.checkBroadcasts(),
+		},
+		CheckedAt: time.Now(),
+	}
+
+	for _, component := range report.Components {
+		if component.Status != domain.HealthStatusOK {
+			report.Status = domain.HealthStatusUnavailable
+		}
+	}
+
+	return report
+}
+
+func (s *HealthService) checkDatabase(ctx context.Context) *domain.ComponentHealth {
+	ctx, cancel := context.WithTimeout(ctx, DefaultHealthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := s.db.PingContext(ctx)
+	latency := time.Since(start)
+
+	stats := s.db.Stats()
+	component := &domain.ComponentHealth{
+		Status: domain.HealthStatusOK,
+		Details: map[string]interface{}{
+			"latency_ms":       latency.Milliseconds(),
+			"open_connections": stats.OpenConnections,
+			"in_use":           stats.InUse,
+		},
+	}
+
+	if err != nil {
+		component.Status = domain.HealthStatusUnavailable
+		component.Error = err.Error()
+	}
+
+	return component
+}
+
+// checkTelegram reports whether the updates loop is running, no updates for a long time
+// is not a failure since the bot may simply have no new users
+func (s *HealthService) checkTelegram() *domain.ComponentHealth {
+	state := s.telegramService.State()
+
+	component := &domain.ComponentHealth{
+		Status: domain.HealthStatusOK,
+		Details: map[string]interface{}{
+			"running":        state.Running,
+			"started_at":     state.StartedAt,
+			"last_update_at": state.LastUpdateAt,
+		},
+	}
+
+	if !state.Running {
+		component.Status = domain.HealthStatusUnavailable
+		component.Error = "telegram updates loop is not running"
+	}
+
+	return component
+}
+
+// checkBroadcasts reports whether the notification workers are running, so new broadcasts would be sent
+func (s *HealthService) checkBroadcasts() *domain.ComponentHealth {
+	state := s.notificationService.State()
+
+	component := &domain.ComponentHealth{
+		Status: domain.HealthStatusOK,
+		Details: map[string]interface{}{
+			"running":            state.Running,
+			"running_broadcasts": state.RunningBroadcasts,
+			"active_workers":     state.ActiveWorkers,
+			"queued_jobs":        state.QueuedJobs,
+		},
+	}
+
+	if !state.Running {
+		component.Status = domain.HealthStatusUnavailable
+		component.Error = "notification workers are not running"
+	}
+
+	return component
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"hr-server/config"
+	"hr-server/internal/domain"
+	"hr-server/internal/logger"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrLinkTrackingDisabled = errors.New("link tracking is disabled, TRACKING_BASE_URL and TRACKING_SECRET are not set")
+	ErrInvalidLinkToken     = errors.New("invalid link token")
+)
+
+// linkTokenMACSize is the length of the truncated HMAC closing the token
+const linkTokenMACSize = 8
+
+// maxUserAgentLength fits the user agent into the column of the clicks
+const maxUserAgentLength = 512
+
+// linkTokenEncoding has neither padding nor underscores, so the tokens survive in Markdown messages
+var linkTokenEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// urlPattern finds the links in messages, Markdown brackets and quotes end a link
+var urlPattern = regexp.MustCompile("https?://[^\\s<>()\\[\\]\"'`]+")
+
+// LinkService replaces the links of the broadcasts with tracked redirects and records the clicks on them.
+// The token of a redirect is the link ID and the Telegram ID of the recipient signed by TRACKING_SECRET,
+// so only the links are stored when a broadcast starts.
+type LinkService struct {
+	baseURL  string
+	secret   []byte
+	linkRepo LinkRepository
+}
+
+func NewLinkService(cfg *config.Config, linkRepo LinkRepository) *LinkService {
+	return &LinkService{
+		baseURL:  strings.TrimRight(cfg.Tracking.BaseURL, "/"),
+		secret:   []byte(cfg.Tracking.Secret),
+		linkRepo: linkRepo,
+	}
+}
+
+// Enabled reports whether TRACKING_BASE_URL and TRACKING_SECRET are set
+func (s *LinkService) Enabled() bool {
+	return s.baseURL != "" && len(s.secret) > 0
+}
+
+// TrackLinks stores the links of the broadcast variant and returns them sorted longest first,
+// so a link is never replaced inside a longer one
+func (s *LinkService) TrackLinks(ctx context.Context, broadcastID, variant string, urls []string) ([]*domain.TrackedLink, error) {
+	if !s.Enabled() {
+		return nil, ErrLinkTrackingDisabled
+	}
+
+	links := make([]*domain.TrackedLink, 0, len(urls))
+	for _, url := range urls {
+		links = append(links, &domain.TrackedLink{BroadcastID: broadcastID, Variant: variant, URL: url})
+	}
+
+	if err := s.linkRepo.CreateLinks(ctx, links); err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(links, func(a, b *domain.TrackedLink) int {
+		return cmp.Compare(len(b.URL), len(a.URL))
+	})
+
+	return links, nil
+}
+
+// TrackedURL returns the redirect to the link for the recipient
+func (s *LinkService) TrackedURL(linkID int, telegramID int64) string {
+	payload := binary.AppendUvarint(nil, uint64(linkID))
+	payload = binary.AppendVarint(payload, telegramID)
+
+	return s.baseURL + "/r/" + linkTokenEncoding.EncodeToString(append(payload, s.sign(payload)...))
+}
+
+// Click records the click on the tracked link and returns the URL to redirect to. A click that fails to be recorded
+// is logged, the recipient is still redirected. Returns ErrInvalidLinkToken if the token isn't signed by TRACKING_SECRET
+// or its link doesn't exist.
+func (s *LinkService) Click(ctx context.Context, token, userAgent string) (string, error) {
+	linkID, telegramID, err := s.parseToken(token)
+	if err != nil {
+		return "", err
+	}
+
+	link, err := s.linkRepo.GetByID(ctx, linkID)
+	if err != nil {
+		return "", err
+	}
+	if link == nil {
+		return "", ErrInvalidLinkToken
+	}
+
+	if agent := []rune(userAgent); len(agent) > maxUserAgentLength {
+		userAgent = string(agent[:maxUserAgentLength])
+	}
+
+	click := &domain.LinkClick{
+		LinkID:     link.ID,
+		TelegramID: telegramID,
+		UserAgent:  userAgent,
+	}
+	if err := s.linkRepo.CreateClick(ctx, click); err != nil {
+		logger.FromContext(ctx).Error("error while record link click: ", err)
+	}
+
+	return link.URL, nil
+}
+
+// GetBroadcastClicks returns the clicks on the tracked links of the broadcast, zeros if it has none
+func (s *LinkService) GetBroadcastClicks(ctx context.Context, broadcastID string) (*domain.BroadcastClickStats, error) {
+	return s.linkRepo.GetBroadcastStats(ctx, broadcastID)
+}
+
+func (s *LinkService) parseToken(token string) (int, int64, error) {
+	if !s.Enabled() {
+		return 0, 0, ErrInvalidLinkToken
+	}
+
+	data, err := linkTokenEncoding.DecodeString(token)
+	if err != nil || len(data) <= linkTokenMACSize {
+		return 0, 0, ErrInvalidLinkToken
+	}
+
+	payload, mac := data[:len(data)-linkTokenMACSize], data[len(data)-linkTokenMACSize:]
+	if !hmac.Equal(mac, s.sign(payload)) {
+		return 0, 0, ErrInvalidLinkToken
+	}
+
+	linkID, n := binary.Uvarint(payload)
+	if n <= 0 {
+		return 0, 0, ErrInvalidLinkToken
+	}
+	telegramID, m := binary.Varint(payload[n:])
+	if m <= 0 || n+m != len(payload) {
+		return 0, 0, ErrInvalidLinkToken
+	}
+
+	return int(linkID), telegramID, nil
+}
+
+func (s *LinkService) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)[:linkTokenMACSize]
+}
+
+// extractURLs returns the distinct links of the message in the order of appearance.
+// Links with placeholders differ for each recipient and aren't tracked.
+func extractURLs(message string) []string {
+	var urls []string
+	for _, url := range urlPattern.FindAllString(message, -1) {
+		// Punctuation after a link ends the sentence
+		url = strings.TrimRight(url, ".,;:!?")
+		if strings.Contains(url, "{{") || slices.Contains(urls, url) {
+			continue
+		}
+		urls = append(urls, url)
+	}
+
+	return urls
+}
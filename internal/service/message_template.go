@@ -0,0 +1,59 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"hr-server/internal/domain"
+	"io"
+	"strings"
+	"text/template"
+)
+
+var ErrInvalidMessageTemplate = errors.New("invalid message template")
+
+// markdownEscaper escapes the characters of the Telegram Markdown, so names like "john_doe" don't break the message
+var markdownEscaper = strings.NewReplacer("_", "\\_", "*", "\\*", "`", "\\`", "[", "\\[")
+
+var messageTemplateFuncs = template.FuncMap{
+	// default returns the fallback if the value is blank: {{.FirstName | default "друг"}}
+	"default": func(fallback, value string) string {
+		if strings.TrimSpace(value) == "" {
+			return fallback
+		}
+		return value
+	},
+}
+
+// parseMessageTemplate parses the message and renders it for an empty recipient,
+// so that unknown placeholders and wrong function arguments are rejected before the broadcast starts
+func parseMessageTemplate(message string) (*template.Template, error) {
+	tmpl, err := template.New("message").Funcs(messageTemplateFuncs).Parse(message)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMessageTemplate, err)
+	}
+
+	if err := tmpl.Execute(io.Discard, domain.NotificationRecipient{}); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMessageTemplate, err)
+	}
+
+	return tmpl, nil
+}
+
+// renderMessage renders the message for the user, the values are escaped for Markdown
+func renderMessage(tmpl *template.Template, user *domain.UserWithChannel) (string, error) {
+	recipient := domain.NotificationRecipient{
+		TelegramID: user.TelegramID,
+		Username:   markdownEscaper.Replace(user.Username),
+		FirstName:  markdownEscaper.Replace(user.FirstName),
+	}
+	if user.ChannelName != nil {
+		recipient.ChannelName = markdownEscaper.Replace(*user.ChannelName)
+	}
+
+	var message strings.Builder
+	if err := tmpl.Execute(&message, recipient); err != nil {
+		return "", fmt.Errorf("failed to render message for user %d: %w", user.TelegramID, err)
+	}
+
+	return message.String(), nil
+}
@@ -1,97 +1,753 @@
 package service
 
 import (
-	"log"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"hr-server/config"
 	"hr-server/internal/domain"
-	"hr-server/internal/repository"
+	"hr-server/internal/logger"
+	"hr-server/internal/metrics"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
 )
 
-const (
-	DefaultMessageInterval = 100 * time.Millisecond
-	DefaultBatchSize       = 20
-	DefaultWorkerCount     = 5
+// finishedBroadcastsKept limits the finished broadcasts reported by the API, the oldest ones are forgotten
+const finishedBroadcastsKept = 100
+
+var (
+	ErrNotificationsStopped = errors.New("notifications are stopped, the service is shutting down")
+	ErrBroadcastNotFound    = errors.New("broadcast not found")
+	ErrBroadcastNotActive   = errors.New("broadcast is already finished or cancelled")
 )
 
+// NotificationService dispatches the broadcasts to a single pool of workers started by Run,
+// so the send rate doesn't grow with the number of running broadcasts
 type NotificationService struct {
-	userRepo        *repository.UserRepository
-	telegramService *TelegramService
+	userRepo        UserRepository
+	broadcastRepo   BroadcastRepository
+	telegramService TelegramSender
+	linkService     *LinkService
+	quietHours      *QuietHours
+
+	workerCount     int
+	batchSize       int
+	messageInterval time.Duration
+
+	// The workers take the transactional jobs before the marketing ones
+	transactional chan NotificationJob
+	marketing     chan NotificationJob
+
+	// stopCtx is cancelled by Run when the app context is cancelled, it stops the running broadcasts.
+	// mu makes sure that no broadcast is started once it's cancelled, Run waits for the running ones.
+	mu         sync.Mutex
+	stopCtx    context.Context
+	stop       context.CancelFunc
+	running    sync.WaitGroup
+	broadcasts map[string]*broadcast
+	finished   []string // IDs of the finished broadcasts, the oldest first
+
+	dispatching       atomic.Bool // set by Run while the workers take new broadcasts
+	runningBroadcasts atomic.Int64
+	activeWorkers     atomic.Int64
+	queuedJobs        atomic.Int64
 }
 
+// NotificationJob is a message of the broadcast to the user, rendered by the worker
 type NotificationJob struct {
-	User     *domain.User
-	Message  string
-	ImageURL *string
+	User *domain.UserWithChannel
+
+	broadcast *broadcast
+}
+
+// broadcast is the state of a broadcast shared by its producer, the workers and the API
+type broadcast struct {
+	ctx      context.Context // cancelled by Cancel and on shutdown, carries the logger with the broadcast ID
+	cancel   context.CancelFunc
+	data     domain.NotificationData
+	variants []*broadcastVariant // a single one without an A/B test
+
+	slots   chan struct{}  // limits the queued and sending jobs to the max concurrency, nil without a limit
+	pending sync.WaitGroup // queued and sending jobs
+
+	sent   atomic.Int64
+	failed atomic.Int64
+	held   atomic.Int64 // recipients waiting for the end of their quiet hours
+
+	mu      sync.Mutex
+	info    domain.Broadcast
+	resumed chan struct{} // closed on resume, nil while not paused
 }
 
 func NewNotificationService(
-	userRepo *repository.UserRepository,
-	telegramService *TelegramService,
+	cfg *config.Config,
+	userRepo UserRepository,
+	broadcastRepo BroadcastRepository,
+	telegramService TelegramSender,
+	linkService *LinkService,
 ) *NotificationService {
+	stopCtx, stop := context.WithCancel(context.Background())
+
 	return &NotificationService{
 		userRepo:        userRepo,
+		broadcastRepo:   broadcastRepo,
 		telegramService: telegramService,
+		linkService:     linkService,
+		quietHours:      NewQuietHours(cfg),
+		workerCount:     cfg.Notifications.WorkerCount,
+		batchSize:       cfg.Notifications.BatchSize,
+		messageInterval: cfg.Notifications.MessageInterval,
+		transactional:   make(chan NotificationJob, cfg.Notifications.BatchSize),
+		marketing:       make(chan NotificationJob, cfg.Notifications.BatchSize),
+		stopCtx:         stopCtx,
+		stop:            stop,
+		broadcasts:      make(map[string]*broadcast),
 	}
 }
 
-// SendNotification sends notification to ALL users without any exceptions or filters
-func (s *NotificationService) SendNotification(data *domain.NotificationData) error {
-	// Create jobs channel with reasonable capacity
-	jobs := make(chan NotificationJob, DefaultBatchSize)
+// Run starts the workers, stops the broadcasts when the app context is cancelled and waits until they are stopped
+func (s *NotificationService) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	var workers sync.WaitGroup
+	for w := 1; w <= s.workerCount; w++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			s.worker(workersCtx)
+		}()
+	}
+
+	s.dispatching.Store(true)
+	logrus.WithField("workers", s.workerCount).Info("notification workers started")
+
+	<-ctx.Done()
+	s.dispatching.Store(false)
+
+	s.mu.Lock()
+	s.stop()
+	s.mu.Unlock()
+
+	// The workers drop the jobs left by the stopped broadcasts, so they are stopped once the broadcasts are
+	s.running.Wait()
+	stopWorkers()
+	workers.Wait()
+
+	logrus.Info("notification workers stopped")
+}
+
+// SendNotification sends notification to ALL users without any exceptions or filters.
+// The broadcast outlives the request and runs until it's finished, cancelled or the app context is cancelled,
+// it keeps the request logger with a broadcast ID added. The messages are templates, an invalid one is rejected
+// with ErrInvalidMessageTemplate before the broadcast starts. Tracking links without TRACKING_BASE_URL and
+// TRACKING_SECRET fails with ErrLinkTrackingDisabled. With the local_window delivery the recipients in their
+// quiet hours are held in memory until the hours end, the broadcast runs until the last of them is sent.
+// The held recipients are counted as failed if the app is stopped before their hours end.
+func (s *NotificationService) SendNotification(ctx context.Context, data *domain.NotificationData) (*domain.Broadcast, error) {
+	variants, err := newBroadcastVariants(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Checked before anything of the broadcast is stored, and again once it's registered
+	if s.stopCtx.Err() != nil {
+		return nil, ErrNotificationsStopped
+	}
+
+	id := newBroadcastID()
+	if err := s.broadcastRepo.CreateVariants(ctx, id, variantStats(variants)); err != nil {
+		return nil, err
+	}
 
-	// Start workers
-	for w := 1; w <= DefaultWorkerCount; w++ {
-		go s.worker(jobs)
+	if data.TrackLinks {
+		for _, variant := range variants {
+			variant.links, err = s.linkService.TrackLinks(ctx, id, variant.name, variant.urls())
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	// Start a goroutine to load users in batches and send jobs
+	s.mu.Lock()
+	if s.stopCtx.Err() != nil {
+		s.mu.Unlock()
+		return nil, ErrNotificationsStopped
+	}
+	s.running.Add(1)
+	b := s.newBroadcast(ctx, id, data, variants)
+	s.broadcasts[b.info.ID] = b
+	s.mu.Unlock()
+
+	stopOnShutdown := context.AfterFunc(s.stopCtx, b.cancel)
+	s.runningBroadcasts.Add(1)
+
+	logger.FromContext(b.ctx).WithFields(logrus.Fields{
+		"priority":        b.info.Priority,
+		"max_concurrency": b.info.MaxConcurrency,
+		"delivery":        b.info.Delivery,
+	}).Info("broadcast started")
+
 	go func() {
-		defer close(jobs)
-
-		// Load ALL users in batches - no filters, no exceptions
-		err := s.userRepo.GetAllInBatches(DefaultBatchSize, func(batch []*domain.User) error {
-			for _, user := range batch {
-				// Send to ALL users without any filters
-				jobs <- NotificationJob{
-					User:     user,
-					Message:  data.Message,
-					ImageURL: data.ImageURL,
+		defer s.running.Done()
+
+		s.queue(b)
+
+		// The broadcast is over when the workers have taken all of its jobs
+		b.pending.Wait()
+
+		stopOnShutdown()
+		s.finish(b)
+		b.cancel()
+		s.runningBroadcasts.Add(-1)
+	}()
+
+	return b.snapshot(), nil
+}
+
+// GetBroadcasts returns the active and recently finished broadcasts, the latest first
+func (s *NotificationService) GetBroadcasts() []*domain.Broadcast {
+	s.mu.Lock()
+	result := make([]*domain.Broadcast, 0, len(s.broadcasts))
+	for _, b := range s.broadcasts {
+		result = append(result, b.snapshot())
+	}
+	s.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].StartedAt.After(result[j].StartedAt)
+	})
+
+	return result
+}
+
+func (s *NotificationService) GetBroadcast(id string) (*domain.Broadcast, error) {
+	b, err := s.getBroadcast(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.snapshot(), nil
+}
+
+// PauseBroadcast stops queuing the messages of the broadcast, the already queued ones are still sent
+func (s *NotificationService) PauseBroadcast(ctx context.Context, id string) (*domain.Broadcast, error) {
+	b, err := s.getBroadcast(id)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	switch b.info.Status {
+	case domain.BroadcastStatusRunning:
+		b.info.Status = domain.BroadcastStatusPaused
+		b.resumed = make(chan struct{})
+	case domain.BroadcastStatusPaused:
+	default:
+		b.mu.Unlock()
+		return nil, ErrBroadcastNotActive
+	}
+	b.mu.Unlock()
+
+	logger.FromContext(ctx).WithField(logger.BroadcastIDField, id).Info("broadcast paused")
+
+	return b.snapshot(), nil
+}
+
+func (s *NotificationService) ResumeBroadcast(ctx context.Context, id string) (*domain.Broadcast, error) {
+	b, err := s.getBroadcast(id)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	switch b.info.Status {
+	case domain.BroadcastStatusPaused:
+		b.info.Status = domain.BroadcastStatusRunning
+		close(b.resumed)
+		b.resumed = nil
+	case domain.BroadcastStatusRunning:
+	default:
+		b.mu.Unlock()
+		return nil, ErrBroadcastNotActive
+	}
+	b.mu.Unlock()
+
+	logger.FromContext(ctx).WithField(logger.BroadcastIDField, id).Info("broadcast resumed")
+
+	return b.snapshot(), nil
+}
+
+// CancelBroadcast stops the broadcast, the queued messages are dropped
+func (s *NotificationService) CancelBroadcast(ctx context.Context, id string) (*domain.Broadcast, error) {
+	b, err := s.getBroadcast(id)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	if !b.info.IsActive() {
+		b.mu.Unlock()
+		return nil, ErrBroadcastNotActive
+	}
+	b.info.Status = domain.BroadcastStatusCancelled
+	b.mu.Unlock()
+
+	b.cancel()
+
+	logger.FromContext(ctx).WithField(logger.BroadcastIDField, id).Info("broadcast cancelled")
+
+	return b.snapshot(), nil
+}
+
+// GetBroadcastVariants returns the stored counters of the broadcast variants, kept after the broadcast is forgotten.
+// Returns ErrBroadcastNotFound if the broadcast has no stored variants.
+func (s *NotificationService) GetBroadcastVariants(ctx context.Context, id string) ([]domain.BroadcastVariant, error) {
+	variants, err := s.broadcastRepo.GetVariants(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(variants) == 0 {
+		return nil, ErrBroadcastNotFound
+	}
+
+	for i := range variants {
+		variants[i].ResponseRate = responseRate(variants[i].Responses, variants[i].Sent)
+	}
+
+	return variants, nil
+}
+
+// GetBroadcastClicks returns the clicks on the tracked links of the broadcast, kept after the broadcast is forgotten
+func (s *NotificationService) GetBroadcastClicks(ctx context.Context, id string) (*domain.BroadcastClickStats, error) {
+	return s.linkService.GetBroadcastClicks(ctx, id)
+}
+
+// State returns the state of the notification workers for readiness checks
+func (s *NotificationService) State() domain.BroadcastState {
+	return domain.BroadcastState{
+		Running:           s.dispatching.Load(),
+		RunningBroadcasts: s.runningBroadcasts.Load(),
+		ActiveWorkers:     s.activeWorkers.Load(),
+		QueuedJobs:        s.queuedJobs.Load(),
+	}
+}
+
+func (s *NotificationService) newBroadcast(ctx context.Context, id string, data *domain.NotificationData, variants []*broadcastVariant) *broadcast {
+	priority := data.Priority
+	if priority == "" {
+		priority = domain.NotificationPriorityMarketing
+	}
+
+	delivery := data.Delivery
+	if delivery == "" {
+		delivery = domain.NotificationDeliveryImmediate
+	}
+
+	b := &broadcast{
+		data:     *data,
+		variants: variants,
+		info: domain.Broadcast{
+			ID:             id,
+			Priority:       priority,
+			MaxConcurrency: data.MaxConcurrency,
+			Delivery:       delivery,
+			Status:         domain.BroadcastStatusRunning,
+			StartedAt:      time.Now(),
+		},
+	}
+	b.ctx, b.cancel = context.WithCancel(logger.With(context.WithoutCancel(ctx), logrus.Fields{logger.BroadcastIDField: id}))
+
+	// A limit of the worker count or more is the same as no limit
+	if data.MaxConcurrency > 0 && data.MaxConcurrency < s.workerCount {
+		b.slots = make(chan struct{}, data.MaxConcurrency)
+	}
+
+	return b
+}
+
+func (s *NotificationService) getBroadcast(id string) (*broadcast, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.broadcasts[id]
+	if !ok {
+		return nil, ErrBroadcastNotFound
+	}
+
+	return b, nil
+}
+
+// queue loads ALL users in batches and queues a job for each of them by the priority of the broadcast.
+// With the local_window delivery the users in their quiet hours are queued once the hours end.
+func (s *NotificationService) queue(b *broadcast) {
+	log := logger.FromContext(b.ctx)
+
+	queue := s.marketing
+	if b.info.Priority == domain.NotificationPriorityTransactional {
+		queue = s.transactional
+	}
+
+	// Held users by the time their window opens, the users of the same UTC offset share it
+	held := make(map[time.Time][]*domain.UserWithChannel)
+
+	// Load ALL users in batches - no filters, no exceptions
+	err := s.userRepo.GetAllInBatches(b.ctx, s.batchSize, func(batch []*domain.UserWithChannel) error {
+		for _, user := range batch {
+			if b.info.Delivery == domain.NotificationDeliveryLocalWindow {
+				now := time.Now()
+				if opensAt := s.quietHours.WindowOpensAt(user, now); opensAt.After(now) {
+					held[opensAt.UTC()] = append(held[opensAt.UTC()], user)
+					b.held.Add(1)
+					continue
 				}
 			}
-			return nil
-		})
 
-		if err != nil {
-			log.Printf("error loading users in batches: %v", err)
+			// Send to ALL users without any filters
+			if err := s.enqueue(b, queue, user); err != nil {
+				return err
+			}
 		}
-	}()
+		return nil
+	})
+
+	if b.ctx.Err() != nil {
+		return
+	}
+
+	if err != nil {
+		log.Error("error while load users in batches: ", err)
+		return
+	}
+
+	if len(held) == 0 {
+		log.Info("broadcast queued to all users")
+		return
+	}
+
+	log.WithField("held", b.held.Load()).Info("broadcast queued to the users outside of their quiet hours")
+
+	for _, opensAt := range slices.SortedFunc(maps.Keys(held), time.Time.Compare) {
+		timer := time.NewTimer(time.Until(opensAt))
+		select {
+		case <-b.ctx.Done():
+			timer.Stop()
+			s.dropHeld(b, held)
+			return
+		case <-timer.C:
+		}
+
+		for i, user := range held[opensAt] {
+			if err := s.enqueue(b, queue, user); err != nil {
+				held[opensAt] = held[opensAt][i:]
+				s.dropHeld(b, held)
+				return
+			}
+			b.held.Add(-1)
+		}
+		delete(held, opensAt)
+
+		log.WithField("window_opened_at", opensAt).Info("held users queued")
+	}
+
+	log.Info("broadcast queued to all users")
+}
+
+// dropHeld forgets the held users of the stopped broadcast. A cancelled broadcast drops them like the queued
+// messages, on shutdown they are counted as failed, since the held users aren't stored and never get the message.
+func (s *NotificationService) dropHeld(b *broadcast, held map[time.Time][]*domain.UserWithChannel) {
+	b.held.Store(0)
+
+	b.mu.Lock()
+	cancelled := b.info.Status == domain.BroadcastStatusCancelled
+	b.mu.Unlock()
+	if cancelled {
+		return
+	}
+
+	failed := make(map[*broadcastVariant]int64)
+	var total int64
+	for _, users := range held {
+		for _, user := range users {
+			failed[b.variantFor(user.TelegramID)]++
+			total++
+		}
+	}
+
+	ctx := context.WithoutCancel(b.ctx)
+	for variant, count := range failed {
+		variant.failed.Add(count)
+		b.failed.Add(count)
+		if err := s.broadcastRepo.IncrementVariant(ctx, b.info.ID, variant.name, 0, count); err != nil {
+			logger.FromContext(ctx).Error("error while count broadcast delivery: ", err)
+		}
+	}
+
+	logger.FromContext(ctx).WithField("held", total).Warn("held users are counted as failed, the broadcast is stopped")
+}
+
+// enqueue queues the job of the user once the broadcast is resumed and has a free slot,
+// returns an error if the broadcast is stopped
+func (s *NotificationService) enqueue(b *broadcast, queue chan NotificationJob, user *domain.UserWithChannel) error {
+	if err := b.waitResumed(); err != nil {
+		return err
+	}
+
+	if b.slots != nil {
+		select {
+		case <-b.ctx.Done():
+			return b.ctx.Err()
+		case b.slots <- struct{}{}:
+		}
+	}
+
+	job := NotificationJob{
+		User:      user,
+		broadcast: b,
+	}
+
+	b.pending.Add(1)
+	select {
+	case <-b.ctx.Done():
+		job.done()
+		return b.ctx.Err()
+	case queue <- job:
+		metrics.NotificationQueued()
+		s.queuedJobs.Add(1)
+	}
 
 	return nil
 }
 
-// worker processes notification jobs
-func (s *NotificationService) worker(jobs <-chan NotificationJob) {
-	for job := range jobs {
-		var err error
-		if job.ImageURL != nil && *job.ImageURL != "" {
-			photo := tgbotapi.NewPhoto(job.User.TelegramID, tgbotapi.FileURL(*job.ImageURL))
-			photo.Caption = job.Message
-			photo.ParseMode = "Markdown"
-			err = s.telegramService.SendMessage(job.User.TelegramID, photo)
-		} else {
-			msg := tgbotapi.NewMessage(job.User.TelegramID, job.Message)
-			msg.ParseMode = "Markdown"
-			err = s.telegramService.SendMessage(job.User.TelegramID, msg)
+// finish records the final status of the broadcast and forgets the oldest finished broadcasts
+func (s *NotificationService) finish(b *broadcast) {
+	now := time.Now()
+
+	b.mu.Lock()
+	b.info.FinishedAt = &now
+	switch {
+	case b.info.Status == domain.BroadcastStatusCancelled:
+	case b.ctx.Err() != nil:
+		b.info.Status = domain.BroadcastStatusStopped
+	default:
+		b.info.Status = domain.BroadcastStatusFinished
+	}
+	status := b.info.Status
+	b.mu.Unlock()
+
+	log := logger.FromContext(b.ctx).WithFields(logrus.Fields{
+		"sent":   b.sent.Load(),
+		"failed": b.failed.Load(),
+	})
+	if status == domain.BroadcastStatusFinished {
+		log.Info("broadcast finished")
+	} else {
+		log.Warn("broadcast " + status)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.finished = append(s.finished, b.info.ID)
+	if len(s.finished) > finishedBroadcastsKept {
+		delete(s.broadcasts, s.finished[0])
+		s.finished = s.finished[1:]
+	}
+}
+
+// worker processes notification jobs of all broadcasts until ctx is cancelled
+func (s *NotificationService) worker(ctx context.Context) {
+	metrics.WorkerStarted()
+	s.activeWorkers.Add(1)
+	defer func() {
+		metrics.WorkerStopped()
+		s.activeWorkers.Add(-1)
+	}()
+
+	for {
+		job, ok := s.next(ctx)
+		if !ok {
+			return
 		}
 
-		if err != nil {
-			log.Printf("failed to send to user %d: %v", job.User.TelegramID, err)
+		metrics.NotificationDequeued()
+		s.queuedJobs.Add(-1)
+
+		sent := s.send(job)
+		job.done()
+
+		// Jobs of the stopped broadcasts are dropped without waiting
+		if !sent {
+			continue
 		}
 
 		// Rate limiting to avoid hitting Telegram API limits
-		time.Sleep(DefaultMessageInterval)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.messageInterval):
+		}
+	}
+}
+
+// next waits for a job, transactional jobs are taken first
+func (s *NotificationService) next(ctx context.Context) (NotificationJob, bool) {
+	select {
+	case job := <-s.transactional:
+		return job, true
+	default:
 	}
+
+	select {
+	case <-ctx.Done():
+		return NotificationJob{}, false
+	case job := <-s.transactional:
+		return job, true
+	case job := <-s.marketing:
+		return job, true
+	}
+}
+
+// send renders and sends the job unless its broadcast is stopped, returns false if the job is dropped
+func (s *NotificationService) send(job NotificationJob) bool {
+	b := job.broadcast
+	if b.ctx.Err() != nil {
+		return false
+	}
+
+	variant := b.variantFor(job.User.TelegramID)
+	log := logger.FromContext(b.ctx).WithFields(logrus.Fields{
+		"telegram_id": job.User.TelegramID,
+		"variant":     variant.name,
+	})
+
+	message, err := renderMessage(variant.template, job.User)
+	if err != nil {
+		s.countDelivery(b, variant, false)
+		log.Error("error while render notification: ", err)
+		return false
+	}
+
+	message, buttonURL := variant.track(s.linkService, message, job.User.TelegramID)
+
+	var replyMarkup interface{}
+	switch {
+	case variant.buttonText != "" && buttonURL != "":
+		replyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonURL(variant.buttonText, buttonURL),
+		))
+	case variant.buttonText != "":
+		replyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(variant.buttonText, broadcastCallbackData(b.info.ID, variant.index)),
+		))
+	}
+
+	if b.data.ImageURL != nil && *b.data.ImageURL != "" {
+		photo := tgbotapi.NewPhoto(job.User.TelegramID, tgbotapi.FileURL(*b.data.ImageURL))
+		photo.Caption = message
+		photo.ParseMode = "Markdown"
+		photo.ReplyMarkup = replyMarkup
+		err = s.telegramService.SendMessage(b.ctx, job.User.TelegramID, photo)
+	} else {
+		msg := tgbotapi.NewMessage(job.User.TelegramID, message)
+		msg.ParseMode = "Markdown"
+		msg.ReplyMarkup = replyMarkup
+		err = s.telegramService.SendMessage(b.ctx, job.User.TelegramID, msg)
+	}
+
+	s.countDelivery(b, variant, err == nil)
+	if err == nil {
+		return true
+	}
+
+	log.Error("error while send notification: ", err)
+
+	// Telegram responds with 403 when the user blocked the bot or deleted the account
+	var tgErr *tgbotapi.Error
+	if errors.As(err, &tgErr) && tgErr.Code == http.StatusForbidden {
+		if err := s.userRepo.UpdateStatus(b.ctx, job.User.TelegramID, domain.UserStatusBlocked); err != nil {
+			log.Error("error while mark user as blocked: ", err)
+		}
+	}
+
+	return true
+}
+
+// countDelivery counts the sent or failed message of the variant in memory and in the database,
+// the stored counter is updated even if the broadcast is stopped meanwhile
+func (s *NotificationService) countDelivery(b *broadcast, variant *broadcastVariant, sent bool) {
+	var sentCount, failedCount int64
+	if sent {
+		variant.sent.Add(1)
+		b.sent.Add(1)
+		sentCount = 1
+	} else {
+		variant.failed.Add(1)
+		b.failed.Add(1)
+		failedCount = 1
+	}
+
+	ctx := context.WithoutCancel(b.ctx)
+	if err := s.broadcastRepo.IncrementVariant(ctx, b.info.ID, variant.name, sentCount, failedCount); err != nil {
+		logger.FromContext(ctx).Error("error while count broadcast delivery: ", err)
+	}
+}
+
+// done releases the job from its broadcast
+func (j NotificationJob) done() {
+	if j.broadcast.slots != nil {
+		<-j.broadcast.slots
+	}
+	j.broadcast.pending.Done()
+}
+
+// waitResumed blocks while the broadcast is paused, returns an error if it's stopped
+func (b *broadcast) waitResumed() error {
+	b.mu.Lock()
+	resumed := b.resumed
+	b.mu.Unlock()
+
+	if resumed != nil {
+		select {
+		case <-b.ctx.Done():
+		case <-resumed:
+		}
+	}
+
+	return b.ctx.Err()
+}
+
+func (b *broadcast) snapshot() *domain.Broadcast {
+	b.mu.Lock()
+	info := b.info
+	b.mu.Unlock()
+
+	info.Sent = b.sent.Load()
+	info.Failed = b.failed.Load()
+	info.Held = b.held.Load()
+
+	info.Variants = make([]domain.BroadcastVariant, 0, len(b.variants))
+	for _, variant := range b.variants {
+		info.Variants = append(info.Variants, variant.stats())
+	}
+
+	return &info
+}
+
+func newBroadcastID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
 }
@@ -0,0 +1,593 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"hr-server/internal/domain"
+	"hr-server/internal/repository"
+	"hr-server/internal/service"
+	"hr-server/internal/service/servicetest"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationService_SendNotification(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+	s.RunNotifications(t)
+
+	for id := int64(1); id <= 7; id++ {
+		s.AddUser(t, id, "user", nil)
+	}
+	_, err := s.User.EraseUser(t.Context(), 7, domain.ErasureModeAnonymize, "request", "admin")
+	require.NoError(t, err)
+
+	s.Telegram.FailFor(2, &tgbotapi.Error{Code: http.StatusForbidden, Message: "Forbidden: bot was blocked by the user"})
+	s.Telegram.FailFor(3, errors.New("connection reset"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	broadcast, err := s.Notification.SendNotification(ctx, &domain.NotificationData{Message: "hello"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.NotificationPriorityMarketing, broadcast.Priority)
+	assert.Equal(t, domain.BroadcastStatusRunning, broadcast.Status)
+
+	// The broadcast outlives the request
+	cancel()
+
+	s.WaitForBroadcasts(t, 5*time.Second)
+
+	sent := s.Telegram.Sent()
+	chatIDs := make([]int64, 0, len(sent))
+	for _, message := range sent {
+		assert.Equal(t, "hello", message.Text)
+		chatIDs = append(chatIDs, message.ChatID)
+	}
+	assert.ElementsMatch(t, []int64{1, 4, 5, 6}, chatIDs, "erased and failed users are not in the sent messages")
+
+	blocked, err := s.User.GetUser(t.Context(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, domain.UserStatusBlocked, blocked.Status, "403 marks the user as blocked")
+
+	failed, err := s.User.GetUser(t.Context(), 3)
+	require.NoError(t, err)
+	assert.Equal(t, domain.UserStatusActive, failed.Status, "other errors keep the status")
+
+	finished, err := s.Notification.GetBroadcast(broadcast.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.BroadcastStatusFinished, finished.Status)
+	assert.Equal(t, int64(4), finished.Sent)
+	assert.Equal(t, int64(2), finished.Failed)
+	assert.NotNil(t, finished.FinishedAt)
+
+	state := s.Notification.State()
+	assert.Equal(t, int64(5), state.ActiveWorkers, "the workers outlive the broadcast")
+	assert.Zero(t, state.RunningBroadcasts)
+	assert.Zero(t, state.QueuedJobs)
+}
+
+func TestNotificationService_SendNotificationWithImage(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+	s.RunNotifications(t)
+	s.AddUser(t, 1, "user", nil)
+
+	imageURL := "https://example.com/image.png"
+	_, err := s.Notification.SendNotification(context.Background(), &domain.NotificationData{Message: "caption", ImageURL: &imageURL})
+	require.NoError(t, err)
+
+	s.WaitForBroadcasts(t, 5*time.Second)
+
+	sent := s.Telegram.Sent()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "caption", sent[0].Text)
+	assert.Equal(t, imageURL, sent[0].PhotoURL)
+}
+
+func TestNotificationService_StopsOnAppShutdown(t *testing.T) {
+	cfg := servicetest.Config()
+	cfg.Tracking.BaseURL = servicetest.TrackingBaseURL
+	cfg.Tracking.Secret = servicetest.TrackingSecret
+	s := servicetest.NewServices(cfg)
+	for id := int64(1); id <= 200; id++ {
+		s.AddUser(t, id, "user", nil)
+	}
+
+	appCtx, shutdown := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go s.Notification.Run(appCtx, &wg)
+
+	broadcast, err := s.Notification.SendNotification(t.Context(), &domain.NotificationData{Message: "hello"})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return len(s.Telegram.Sent()) > 0 }, 5*time.Second, 10*time.Millisecond)
+	shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification workers are not stopped")
+	}
+
+	assert.Less(t, len(s.Telegram.Sent()), 200, "the broadcast is interrupted")
+	assert.Equal(t, domain.BroadcastState{}, s.Notification.State())
+
+	interrupted, err := s.Notification.GetBroadcast(broadcast.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.BroadcastStatusStopped, interrupted.Status)
+
+	_, err = s.Notification.SendNotification(t.Context(), &domain.NotificationData{Message: "https://example.com", TrackLinks: true})
+	assert.ErrorIs(t, err, service.ErrNotificationsStopped)
+
+	links, err := s.Link.TrackLinks(t.Context(), "next", "A", []string{"https://example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, links[0].ID, "the links of the rejected broadcast aren't stored")
+}
+
+func TestNotificationService_TransactionalGoesFirst(t *testing.T) {
+	cfg := servicetest.Config()
+	cfg.Notifications.WorkerCount = 1
+	cfg.Notifications.MessageInterval = 5 * time.Millisecond
+	s := servicetest.NewServices(cfg)
+	s.RunNotifications(t)
+
+	for id := int64(1); id <= 40; id++ {
+		s.AddUser(t, id, "user", nil)
+	}
+
+	_, err := s.Notification.SendNotification(t.Context(), &domain.NotificationData{Message: "marketing"})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(s.Telegram.Sent()) > 0 }, 5*time.Second, time.Millisecond)
+
+	_, err = s.Notification.SendNotification(t.Context(), &domain.NotificationData{
+		Message:  "transactional",
+		Priority: domain.NotificationPriorityTransactional,
+	})
+	require.NoError(t, err)
+
+	s.WaitForBroadcasts(t, 10*time.Second)
+
+	lastByText := make(map[string]int)
+	for i, message := range s.Telegram.Sent() {
+		lastByText[message.Text] = i
+	}
+	require.Len(t, s.Telegram.Sent(), 80)
+	assert.Less(t, lastByText["transactional"], lastByText["marketing"], "the transactional broadcast overtakes the marketing one")
+}
+
+func TestNotificationService_MaxConcurrency(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+	s.Telegram.SetDelay(20 * time.Millisecond)
+	s.RunNotifications(t)
+
+	for id := int64(1); id <= 10; id++ {
+		s.AddUser(t, id, "user", nil)
+	}
+
+	broadcast, err := s.Notification.SendNotification(t.Context(), &domain.NotificationData{Message: "hello", MaxConcurrency: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 2, broadcast.MaxConcurrency)
+
+	s.WaitForBroadcasts(t, 10*time.Second)
+
+	assert.Len(t, s.Telegram.Sent(), 10)
+	assert.Equal(t, 2, s.Telegram.MaxConcurrent())
+}
+
+func TestNotificationService_PauseResumeCancel(t *testing.T) {
+	cfg := servicetest.Config()
+	cfg.Notifications.MessageInterval = 5 * time.Millisecond
+	s := servicetest.NewServices(cfg)
+	s.RunNotifications(t)
+
+	for id := int64(1); id <= 50; id++ {
+		s.AddUser(t, id, "user", nil)
+	}
+
+	// A single job of the broadcast is queued or sent at once, so the pause takes effect after it
+	broadcast, err := s.Notification.SendNotification(t.Context(), &domain.NotificationData{Message: "hello", MaxConcurrency: 1})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(s.Telegram.Sent()) > 0 }, 5*time.Second, time.Millisecond)
+
+	paused, err := s.Notification.PauseBroadcast(t.Context(), broadcast.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.BroadcastStatusPaused, paused.Status)
+
+	time.Sleep(20 * time.Millisecond)
+	sentWhilePaused := len(s.Telegram.Sent())
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, sentWhilePaused, len(s.Telegram.Sent()), "nothing is sent while paused")
+
+	resumed, err := s.Notification.ResumeBroadcast(t.Context(), broadcast.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.BroadcastStatusRunning, resumed.Status)
+	require.Eventually(t, func() bool { return len(s.Telegram.Sent()) > sentWhilePaused }, 5*time.Second, time.Millisecond)
+
+	cancelled, err := s.Notification.CancelBroadcast(t.Context(), broadcast.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.BroadcastStatusCancelled, cancelled.Status)
+
+	s.WaitForBroadcasts(t, 5*time.Second)
+	assert.Less(t, len(s.Telegram.Sent()), 50, "the broadcast is interrupted")
+
+	finished, err := s.Notification.GetBroadcast(broadcast.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.BroadcastStatusCancelled, finished.Status)
+	assert.NotNil(t, finished.FinishedAt)
+
+	_, err = s.Notification.ResumeBroadcast(t.Context(), broadcast.ID)
+	assert.ErrorIs(t, err, service.ErrBroadcastNotActive)
+
+	_, err = s.Notification.PauseBroadcast(t.Context(), "unknown")
+	assert.ErrorIs(t, err, service.ErrBroadcastNotFound)
+
+	assert.Len(t, s.Notification.GetBroadcasts(), 1)
+}
+
+func TestNotificationService_PersonalizedMessage(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+	s.RunNotifications(t)
+
+	channel, err := s.Channel.GenerateChannel(t.Context(), "hh.ru", domain.ChannelAttributes{})
+	require.NoError(t, err)
+
+	_, _, err = s.User.CreateUser(t.Context(), 1, "john_doe", "John", &channel.ID)
+	require.NoError(t, err)
+	_, _, err = s.User.CreateUser(t.Context(), 2, "", "", nil)
+	require.NoError(t, err)
+
+	_, err = s.Notification.SendNotification(t.Context(), &domain.NotificationData{
+		Message: `Hi, {{.FirstName | default "friend"}} ({{.Username}}) from {{.ChannelName | default "nowhere"}}`,
+	})
+	require.NoError(t, err)
+
+	s.WaitForBroadcasts(t, 5*time.Second)
+
+	texts := make(map[int64]string)
+	for _, message := range s.Telegram.Sent() {
+		texts[message.ChatID] = message.Text
+	}
+	assert.Equal(t, `Hi, John (john\_doe) from hh.ru`, texts[1], "values are escaped for Markdown")
+	assert.Equal(t, "Hi, friend () from nowhere", texts[2])
+
+	for name, message := range map[string]string{
+		"unknown placeholder": "Hi, {{.Usernme}}",
+		"unclosed action":     "Hi, {{.Username",
+		"missing fallback":    "Hi, {{.FirstName | default}}",
+	} {
+		_, err := s.Notification.SendNotification(t.Context(), &domain.NotificationData{Message: message})
+		assert.ErrorIs(t, err, service.ErrInvalidMessageTemplate, name)
+	}
+	assert.Len(t, s.Notification.GetBroadcasts(), 1, "invalid templates don't start broadcasts")
+}
+
+func TestNotificationService_Variants(t *testing.T) {
+	cfg := servicetest.Config()
+	cfg.Notifications.MessageInterval = time.Millisecond
+	s := servicetest.NewServices(cfg)
+	s.RunNotifications(t)
+
+	for id := int64(1); id <= 300; id++ {
+		s.AddUser(t, id, "user", nil)
+	}
+
+	data := &domain.NotificationData{Variants: []domain.NotificationVariant{
+		{Message: "short", Weight: 1, ButtonText: "Play"},
+		{Message: "long", Weight: 2, ButtonText: "Play now"},
+	}}
+	broadcast, err := s.Notification.SendNotification(t.Context(), data)
+	require.NoError(t, err)
+	s.WaitForBroadcasts(t, 10*time.Second)
+
+	textByChat := make(map[int64]string)
+	callbackByText := make(map[string]string)
+	for _, message := range s.Telegram.Sent() {
+		textByChat[message.ChatID] = message.Text
+		callbackByText[message.Text] = message.CallbackData
+	}
+	require.Len(t, textByChat, 300)
+	require.NotEmpty(t, callbackByText["short"])
+	require.NotEqual(t, callbackByText["short"], callbackByText["long"])
+
+	// Responses are counted once per recipient, unknown data is rejected
+	var respondedToShort int64
+	for chatID, text := range textByChat {
+		if text == "short" && respondedToShort < 10 {
+			require.NoError(t, s.Notification.HandleCallback(t.Context(), chatID, callbackByText[text]))
+			require.NoError(t, s.Notification.HandleCallback(t.Context(), chatID, callbackByText[text]))
+			respondedToShort++
+		}
+	}
+	assert.ErrorIs(t, s.Notification.HandleCallback(t.Context(), 1, "something"), service.ErrUnknownCallback)
+	assert.ErrorIs(t, s.Notification.HandleCallback(t.Context(), 1, "broadcast:unknown:0"), service.ErrBroadcastNotFound)
+
+	result, err := s.Notification.GetBroadcast(broadcast.ID)
+	require.NoError(t, err)
+	require.Len(t, result.Variants, 2)
+
+	short, long := result.Variants[0], result.Variants[1]
+	assert.Equal(t, "A", short.Name)
+	assert.Equal(t, "B", long.Name)
+	assert.Equal(t, int64(300), short.Sent+long.Sent)
+	assert.InDelta(t, 100, short.Sent, 30, "recipients are split by weights")
+	assert.Equal(t, int64(10), short.Responses)
+	assert.InDelta(t, 10/float64(short.Sent), short.ResponseRate, 0.0001)
+	assert.Zero(t, long.Responses)
+
+	t.Run("counts are stored", func(t *testing.T) {
+		stored, err := s.Notification.GetBroadcastVariants(t.Context(), broadcast.ID)
+		require.NoError(t, err)
+		assert.Equal(t, result.Variants, stored)
+
+		_, err = s.Notification.GetBroadcastVariants(t.Context(), "unknown")
+		assert.ErrorIs(t, err, service.ErrBroadcastNotFound)
+	})
+
+	t.Run("responses are recorded after a restart", func(t *testing.T) {
+		restarted := service.NewNotificationService(cfg, repository.NewUserMemoryRepository(s.DB), repository.NewBroadcastMemoryRepository(s.DB), s.Telegram, s.Link)
+		_, err := restarted.GetBroadcast(broadcast.ID)
+		require.ErrorIs(t, err, service.ErrBroadcastNotFound, "the broadcast is forgotten")
+
+		for chatID, text := range textByChat {
+			if text == "long" {
+				require.NoError(t, restarted.HandleCallback(t.Context(), chatID, callbackByText[text]))
+				break
+			}
+		}
+		assert.ErrorIs(t, restarted.HandleCallback(t.Context(), 1, broadcastCallbackData(broadcast.ID, 2)), service.ErrUnknownCallback)
+
+		stored, err := restarted.GetBroadcastVariants(t.Context(), broadcast.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), stored[0].Responses)
+		assert.Equal(t, int64(1), stored[1].Responses)
+	})
+
+	t.Run("groups differ between broadcasts", func(t *testing.T) {
+		_, err := s.Notification.SendNotification(t.Context(), data)
+		require.NoError(t, err)
+		s.WaitForBroadcasts(t, 10*time.Second)
+
+		changed := 0
+		for _, message := range s.Telegram.Sent()[300:] {
+			if textByChat[message.ChatID] != message.Text {
+				changed++
+			}
+		}
+		assert.Positive(t, changed, "the assignment is salted with the broadcast ID")
+	})
+
+	t.Run("invalid variant template", func(t *testing.T) {
+		_, err := s.Notification.SendNotification(t.Context(), &domain.NotificationData{Variants: []domain.NotificationVariant{
+			{Message: "hi", Weight: 1},
+			{Message: "hi, {{.Nme}}", Weight: 1},
+		}})
+		assert.ErrorIs(t, err, service.ErrInvalidMessageTemplate)
+		assert.ErrorContains(t, err, "variant B")
+	})
+}
+
+func TestNotificationService_TrackLinks(t *testing.T) {
+	cfg := servicetest.Config()
+	cfg.Tracking.BaseURL = servicetest.TrackingBaseURL
+	cfg.Tracking.Secret = servicetest.TrackingSecret
+	s := servicetest.NewServices(cfg)
+	s.RunNotifications(t)
+
+	s.AddUser(t, 1, "alice", nil)
+	s.AddUser(t, 2, "bob", nil)
+
+	broadcast, err := s.Notification.SendNotification(t.Context(), &domain.NotificationData{
+		Message:    "Read https://example.com/jobs and [the FAQ](https://example.com/jobs/faq). Hi {{.Username}}, https://example.com/u/{{.Username}}",
+		ButtonText: "Apply",
+		ButtonURL:  "https://example.com/apply?ref=bot",
+		TrackLinks: true,
+	})
+	require.NoError(t, err)
+	s.WaitForBroadcasts(t, 5*time.Second)
+
+	sent := s.Telegram.Sent()
+	require.Len(t, sent, 2)
+
+	tokens := regexp.MustCompile(regexp.QuoteMeta(servicetest.TrackingBaseURL) + `/r/([a-z2-7]+)`)
+	messages := make(map[int64]servicetest.SentMessage)
+	for _, message := range sent {
+		messages[message.ChatID] = message
+		assert.NotContains(t, message.Text, "https://example.com/jobs", "links are replaced")
+		assert.Contains(t, message.Text, "https://example.com/u/", "links with placeholders aren't tracked")
+		assert.Len(t, tokens.FindAllString(message.Text, -1), 2)
+		assert.Regexp(t, tokens, message.ButtonURL)
+	}
+	assert.NotEqual(t, messages[1].Text, messages[2].Text, "each recipient has own tokens")
+
+	token := func(url string) string { return tokens.FindStringSubmatch(url)[1] }
+	faq := token(tokens.FindAllString(messages[1].Text, -1)[1])
+
+	url, err := s.Link.Click(t.Context(), faq, "Mozilla/5.0")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/jobs/faq", url)
+	_, err = s.Link.Click(t.Context(), faq, "Mozilla/5.0")
+	require.NoError(t, err)
+
+	url, err = s.Link.Click(t.Context(), token(messages[2].ButtonURL), "TelegramBot")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/apply?ref=bot", url)
+
+	tampered := "a" + faq[1:]
+	if faq[0] == 'a' {
+		tampered = "b" + faq[1:]
+	}
+	_, err = s.Link.Click(t.Context(), tampered, "")
+	assert.ErrorIs(t, err, service.ErrInvalidLinkToken, "tampered token")
+	_, err = s.Link.Click(t.Context(), "not-a-token", "")
+	assert.ErrorIs(t, err, service.ErrInvalidLinkToken)
+
+	stats, err := s.Notification.GetBroadcastClicks(t.Context(), broadcast.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.Clicks)
+	assert.Equal(t, int64(2), stats.UniqueUsers)
+	require.Len(t, stats.Links, 3)
+	for _, link := range stats.Links {
+		assert.Equal(t, "A", link.Variant)
+		switch link.URL {
+		case "https://example.com/jobs/faq":
+			assert.Equal(t, int64(2), link.Clicks)
+			assert.Equal(t, int64(1), link.UniqueUsers)
+		case "https://example.com/apply?ref=bot":
+			assert.Equal(t, int64(1), link.Clicks)
+		default:
+			assert.Zero(t, link.Clicks, link.URL)
+		}
+	}
+
+	t.Run("erasure deletes the clicks", func(t *testing.T) {
+		_, err := s.User.EraseUser(t.Context(), 1, domain.ErasureModeDelete, "request", "test")
+		require.NoError(t, err)
+
+		stats, err := s.Notification.GetBroadcastClicks(t.Context(), broadcast.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), stats.Clicks)
+	})
+
+	t.Run("disabled tracking", func(t *testing.T) {
+		s := servicetest.NewServices(servicetest.Config())
+
+		_, err := s.Notification.SendNotification(t.Context(), &domain.NotificationData{Message: "https://example.com", TrackLinks: true})
+		assert.ErrorIs(t, err, service.ErrLinkTrackingDisabled)
+	})
+}
+
+func TestNotificationService_LocalWindowDelivery(t *testing.T) {
+	// The quiet hours are now in UTC and 12 hours later in UTC+12
+	now := time.Now().UTC()
+	cfg := servicetest.Config()
+	cfg.QuietHours.Start = now.Add(-2 * time.Hour).Format("15:04")
+	cfg.QuietHours.End = now.Add(2 * time.Hour).Format("15:04")
+	cfg.QuietHours.DefaultTimezone = "UTC"
+	s := servicetest.NewServices(cfg)
+	s.RunNotifications(t)
+
+	channel, err := s.Channel.GenerateChannel(t.Context(), "far east", domain.ChannelAttributes{Timezone: "Etc/GMT-12"})
+	require.NoError(t, err)
+
+	setTimezone := func(telegramID int64, timezone string) {
+		t.Helper()
+		_, err := s.User.UpdateUser(t.Context(), telegramID, domain.UserUpdate{Timezone: &timezone})
+		require.NoError(t, err)
+	}
+
+	s.AddUser(t, 1, "explicit", nil)
+	setTimezone(1, "Etc/GMT-12")
+	s.AddUser(t, 2, "channel", &channel.ID)
+	s.AddUser(t, 3, "explicit over channel", &channel.ID)
+	setTimezone(3, "UTC")
+	s.AddUser(t, 4, "default", nil)
+
+	broadcast, err := s.Notification.SendNotification(t.Context(), &domain.NotificationData{
+		Message:  "hello",
+		Delivery: domain.NotificationDeliveryLocalWindow,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.NotificationDeliveryLocalWindow, broadcast.Delivery)
+
+	require.Eventually(t, func() bool {
+		b, err := s.Notification.GetBroadcast(broadcast.ID)
+		return err == nil && b.Sent == 2 && b.Held == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	recipients := make(map[int64]bool)
+	for _, message := range s.Telegram.Sent() {
+		recipients[message.ChatID] = true
+	}
+	assert.Equal(t, map[int64]bool{1: true, 2: true}, recipients)
+
+	held, err := s.Notification.GetBroadcast(broadcast.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.BroadcastStatusRunning, held.Status, "the broadcast waits for the held users")
+
+	_, err = s.Notification.CancelBroadcast(t.Context(), broadcast.ID)
+	require.NoError(t, err)
+	s.WaitForBroadcasts(t, 5*time.Second)
+	assert.Len(t, s.Telegram.Sent(), 2)
+
+	cancelled, err := s.Notification.GetBroadcast(broadcast.ID)
+	require.NoError(t, err)
+	assert.Zero(t, cancelled.Held)
+	assert.Zero(t, cancelled.Failed, "the held users of a cancelled broadcast are dropped")
+}
+
+func TestNotificationService_LocalWindowDeliveryShutdown(t *testing.T) {
+	now := time.Now().UTC()
+	cfg := servicetest.Config()
+	cfg.QuietHours.Start = now.Add(-2 * time.Hour).Format("15:04")
+	cfg.QuietHours.End = now.Add(2 * time.Hour).Format("15:04")
+	cfg.QuietHours.DefaultTimezone = "UTC"
+	s := servicetest.NewServices(cfg)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go s.Notification.Run(ctx, &wg)
+
+	s.AddUser(t, 1, "alice", nil)
+	s.AddUser(t, 2, "bob", nil)
+
+	broadcast, err := s.Notification.SendNotification(t.Context(), &domain.NotificationData{
+		Message:  "hello",
+		Delivery: domain.NotificationDeliveryLocalWindow,
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		b, err := s.Notification.GetBroadcast(broadcast.ID)
+		return err == nil && b.Held == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	wg.Wait()
+
+	stopped, err := s.Notification.GetBroadcast(broadcast.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.BroadcastStatusStopped, stopped.Status)
+	assert.Zero(t, stopped.Held)
+	assert.Equal(t, int64(2), stopped.Failed, "the lost held users are failed")
+
+	variants, err := s.Notification.GetBroadcastVariants(t.Context(), broadcast.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), variants[0].Failed)
+	assert.Empty(t, s.Telegram.Sent())
+}
+
+func TestNotificationService_ImmediateDeliveryIgnoresQuietHours(t *testing.T) {
+	now := time.Now().UTC()
+	cfg := servicetest.Config()
+	cfg.QuietHours.Start = now.Add(-2 * time.Hour).Format("15:04")
+	cfg.QuietHours.End = now.Add(2 * time.Hour).Format("15:04")
+	cfg.QuietHours.DefaultTimezone = "UTC"
+	s := servicetest.NewServices(cfg)
+	s.RunNotifications(t)
+
+	s.AddUser(t, 1, "alice", nil)
+
+	broadcast, err := s.Notification.SendNotification(t.Context(), &domain.NotificationData{Message: "hello"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.NotificationDeliveryImmediate, broadcast.Delivery)
+
+	s.WaitForBroadcasts(t, 5*time.Second)
+	assert.Len(t, s.Telegram.Sent(), 1)
+}
+
+func broadcastCallbackData(broadcastID string, variant int) string {
+	return "broadcast:" + broadcastID + ":" + strconv.Itoa(variant)
+}
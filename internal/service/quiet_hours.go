@@ -0,0 +1,100 @@
+package service
+
+import (
+	"hr-server/config"
+	"hr-server/internal/domain"
+	"sync"
+	"time"
+)
+
+// QuietHours tells when the delivery window of a recipient opens in their local time.
+// The timezone of a user is the explicit one, then the one of the channel, then DEFAULT_TIMEZONE.
+type QuietHours struct {
+	start, end      clock
+	defaultLocation *time.Location
+
+	locations sync.Map // timezone name -> *time.Location, nil for unknown names
+}
+
+// clock is a time of day in the local time of a recipient
+type clock struct {
+	hour, minute int
+}
+
+func NewQuietHours(cfg *config.Config) *QuietHours {
+	defaultLocation, err := time.LoadLocation(cfg.QuietHours.DefaultTimezone)
+	if err != nil {
+		defaultLocation = time.UTC
+	}
+
+	return &QuietHours{
+		start:           parseClock(cfg.QuietHours.Start),
+		end:             parseClock(cfg.QuietHours.End),
+		defaultLocation: defaultLocation,
+	}
+}
+
+// Location returns the timezone of the user, unknown names are ignored
+func (q *QuietHours) Location(user *domain.UserWithChannel) *time.Location {
+	for _, name := range []string{user.Timezone, user.ChannelTimezone} {
+		if name == "" {
+			continue
+		}
+		if location := q.load(name); location != nil {
+			return location
+		}
+	}
+
+	return q.defaultLocation
+}
+
+// WindowOpensAt returns now if it's outside of the quiet hours of the user, otherwise the end of the quiet hours
+func (q *QuietHours) WindowOpensAt(user *domain.UserWithChannel, now time.Time) time.Time {
+	if q.start == q.end {
+		return now
+	}
+
+	local := now.In(q.Location(user))
+	current := clock{local.Hour(), local.Minute()}
+
+	quiet := !current.before(q.start) && current.before(q.end)
+	if q.end.before(q.start) {
+		// The quiet hours span the midnight
+		quiet = !current.before(q.start) || current.before(q.end)
+	}
+	if !quiet {
+		return now
+	}
+
+	// In the evening part of the quiet hours the window opens the next day
+	year, month, day := local.Date()
+	if !current.before(q.end) {
+		day++
+	}
+
+	return time.Date(year, month, day, q.end.hour, q.end.minute, 0, 0, local.Location())
+}
+
+func (q *QuietHours) load(name string) *time.Location {
+	if cached, ok := q.locations.Load(name); ok {
+		return cached.(*time.Location)
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		location = nil
+	}
+	q.locations.Store(name, location)
+
+	return location
+}
+
+// parseClock parses the validated HH:MM of the config
+func parseClock(value string) clock {
+	t, _ := time.Parse(config.ClockFormat, value)
+	return clock{t.Hour(), t.Minute()}
+}
+
+func (c clock) before(other clock) bool {
+	return c.hour < other.hour || (c.hour == other.hour && c.minute < other.minute)
+}
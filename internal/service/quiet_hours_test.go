@@ -0,0 +1,57 @@
+package service_test
+
+import (
+	"hr-server/internal/domain"
+	"hr-server/internal/service"
+	"hr-server/internal/service/servicetest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuietHours_WindowOpensAt(t *testing.T) {
+	cfg := servicetest.Config()
+	cfg.QuietHours.Start = "21:00"
+	cfg.QuietHours.End = "09:00"
+	cfg.QuietHours.DefaultTimezone = "Europe/Moscow"
+	quietHours := service.NewQuietHours(cfg)
+
+	moscow, _ := time.LoadLocation("Europe/Moscow")
+	vladivostok, _ := time.LoadLocation("Asia/Vladivostok")
+
+	// 20:00 in Moscow is 03:00 of the next day in Vladivostok
+	now := time.Date(2026, 3, 10, 20, 0, 0, 0, moscow)
+
+	for name, tc := range map[string]struct {
+		user *domain.UserWithChannel
+		want time.Time
+	}{
+		"default timezone":              {&domain.UserWithChannel{}, now},
+		"channel timezone":              {&domain.UserWithChannel{ChannelTimezone: "Asia/Vladivostok"}, time.Date(2026, 3, 11, 9, 0, 0, 0, vladivostok)},
+		"explicit timezone":             {&domain.UserWithChannel{Timezone: "Europe/Moscow", ChannelTimezone: "Asia/Vladivostok"}, now},
+		"unknown is ignored":            {&domain.UserWithChannel{Timezone: "Mars/Olympus", ChannelTimezone: "Asia/Vladivostok"}, time.Date(2026, 3, 11, 9, 0, 0, 0, vladivostok)},
+		"evening opens on the next day": {&domain.UserWithChannel{Timezone: "Asia/Yekaterinburg"}, time.Date(2026, 3, 11, 9, 0, 0, 0, time.FixedZone("+05", 5*60*60))},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.True(t, tc.want.Equal(quietHours.WindowOpensAt(tc.user, now)), quietHours.WindowOpensAt(tc.user, now))
+		})
+	}
+
+	t.Run("quiet hours within a day", func(t *testing.T) {
+		cfg.QuietHours.Start = "13:00"
+		cfg.QuietHours.End = "14:30"
+		lunch := service.NewQuietHours(cfg)
+
+		user := &domain.UserWithChannel{}
+		assert.True(t, time.Date(2026, 3, 10, 14, 30, 0, 0, moscow).Equal(lunch.WindowOpensAt(user, time.Date(2026, 3, 10, 13, 15, 0, 0, moscow))))
+		at := time.Date(2026, 3, 10, 14, 30, 0, 0, moscow)
+		assert.True(t, at.Equal(lunch.WindowOpensAt(user, at)))
+	})
+
+	t.Run("equal start and end disable quiet hours", func(t *testing.T) {
+		cfg.QuietHours.Start = "00:00"
+		cfg.QuietHours.End = "00:00"
+		assert.True(t, now.Equal(service.NewQuietHours(cfg).WindowOpensAt(&domain.UserWithChannel{ChannelTimezone: "Asia/Vladivostok"}, now)))
+	})
+}
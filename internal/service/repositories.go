@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"hr-server/internal/domain"
+	"hr-server/internal/repository"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Storage the services depend on, implemented by the postgres and the in-memory repositories
+
+type UserRepository interface {
+	Upsert(ctx context.Context, telegramID int64, username, firstName string, channelID *int) (bool, bool, error)
+	GetByTelegramID(ctx context.Context, telegramID int64) (*domain.User, error)
+	GetWithChannelByTelegramID(ctx context.Context, telegramID int64) (*domain.UserWithChannel, error)
+	UpsertImported(ctx context.Context, user *domain.User) (bool, error)
+	Update(ctx context.Context, telegramID int64, update domain.UserUpdate) (bool, error)
+	Delete(ctx context.Context, telegramID int64) (bool, error)
+	Erase(ctx context.Context, telegramID int64, erasure *domain.UserErasure) (*domain.UserErasure, error)
+	GetAll(ctx context.Context) ([]*domain.User, error)
+	GetPageWithChannel(ctx context.Context, filter domain.UserFilter, page domain.PageRequest) (*domain.Page[*domain.UserWithChannel], error)
+	GetAllWithChannelInBatches(ctx context.Context, filter domain.UserFilter, batchSize int, callback func([]*domain.UserWithChannel) error) error
+	UpdateStatus(ctx context.Context, telegramID int64, status string) error
+	GetByChannel(ctx context.Context, channelID int) ([]*domain.User, error)
+	GetAllInBatches(ctx context.Context, batchSize int, callback func([]*domain.UserWithChannel) error) error
+}
+
+type ChannelRepository interface {
+	Create(ctx context.Context, channel *domain.Channel) (*domain.Channel, error)
+	Update(ctx context.Context, channel *domain.Channel) error
+	GetByCode(ctx context.Context, code string) (*domain.Channel, error)
+	GetByName(ctx context.Context, name string) ([]*domain.Channel, error)
+	GetByID(ctx context.Context, id int) (*domain.Channel, error)
+	GetPage(ctx context.Context, filter domain.ChannelFilter, page domain.PageRequest) (*domain.Page[*domain.Channel], error)
+	CountUsers(ctx context.Context, channelIDs ...int) (map[int]int64, error)
+}
+
+type CampaignRepository interface {
+	Create(ctx context.Context, name string) (*domain.Campaign, error)
+	GetByID(ctx context.Context, id int) (*domain.Campaign, error)
+	GetAll(ctx context.Context) ([]*domain.Campaign, error)
+	GetStats(ctx context.Context) ([]*domain.GroupStats, error)
+}
+
+type SourceRepository interface {
+	Create(ctx context.Context, name string) (*domain.Source, error)
+	GetByID(ctx context.Context, id int) (*domain.Source, error)
+	GetAll(ctx context.Context) ([]*domain.Source, error)
+	GetStats(ctx context.Context) ([]*domain.GroupStats, error)
+}
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *domain.APIKey) (*domain.APIKey, error)
+	GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error)
+	GetAll(ctx context.Context) ([]*domain.APIKey, error)
+	Revoke(ctx context.Context, id int) (bool, error)
+	TouchLastUsed(ctx context.Context, id int, interval time.Duration) error
+}
+
+type AdminRepository interface {
+	Create(ctx context.Context, admin *domain.Admin) (*domain.Admin, error)
+	GetByID(ctx context.Context, id int) (*domain.Admin, error)
+	GetByUsername(ctx context.Context, username string) (*domain.Admin, error)
+	GetByTelegramID(ctx context.Context, telegramID int64) (*domain.Admin, error)
+	GetAll(ctx context.Context) ([]*domain.Admin, error)
+	UpdateLastLogin(ctx context.Context, id int) error
+	Disable(ctx context.Context, id int) (bool, error)
+	CreateRefreshToken(ctx context.Context, token *domain.AdminRefreshToken) error
+	RevokeRefreshToken(ctx context.Context, hash string) (*domain.AdminRefreshToken, error)
+}
+
+type AuditRepository interface {
+	Create(ctx context.Context, entry *domain.AuditEntry) error
+	GetPage(ctx context.Context, filter domain.AuditFilter, page domain.PageRequest) (*domain.Page[*domain.AuditEntry], error)
+}
+
+type LinkRepository interface {
+	CreateLinks(ctx context.Context, links []*domain.TrackedLink) error
+	GetByID(ctx context.Context, id int) (*domain.TrackedLink, error)
+	CreateClick(ctx context.Context, click *domain.LinkClick) error
+	GetBroadcastStats(ctx context.Context, broadcastID string) (*domain.BroadcastClickStats, error)
+}
+
+type BroadcastRepository interface {
+	CreateVariants(ctx context.Context, broadcastID string, variants []domain.BroadcastVariant) error
+	IncrementVariant(ctx context.Context, broadcastID, variant string, sent, failed int64) error
+	CreateResponse(ctx context.Context, broadcastID, variant string, telegramID int64) (bool, error)
+	GetVariants(ctx context.Context, broadcastID string) ([]domain.BroadcastVariant, error)
+}
+
+type DripRepository interface {
+	CreateSequence(ctx context.Context, sequence *domain.DripSequence) (*domain.DripSequence, error)
+	GetSequences(ctx context.Context) ([]*domain.DripSequence, error)
+	GetSequence(ctx context.Context, id int) (*domain.DripSequence, error)
+	SetSequenceActive(ctx context.Context, id int, active bool) (bool, error)
+	DeleteSequence(ctx context.Context, id int) (bool, error)
+	Enroll(ctx context.Context, telegramID int64, channelID *int, enrolledAt time.Time) (int, error)
+	ClaimDueEnrollments(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*domain.DripEnrollment, error)
+	UpdateEnrollment(ctx context.Context, enrollment *domain.DripEnrollment, position int, outcome string) error
+	RecordReply(ctx context.Context, telegramID int64, at time.Time) error
+}
+
+// TelegramSender sends messages to Telegram chats, implemented by TelegramService
+type TelegramSender interface {
+	SendMessage(ctx context.Context, chatID int64, message tgbotapi.Chattable) error
+}
+
+var (
+	_ UserRepository      = (*repository.UserRepository)(nil)
+	_ UserRepository      = (*repository.UserMemoryRepository)(nil)
+	_ ChannelRepository   = (*repository.ChannelRepository)(nil)
+	_ ChannelRepository   = (*repository.ChannelMemoryRepository)(nil)
+	_ CampaignRepository  = (*repository.CampaignRepository)(nil)
+	_ CampaignRepository  = (*repository.CampaignMemoryRepository)(nil)
+	_ SourceRepository    = (*repository.SourceRepository)(nil)
+	_ SourceRepository    = (*repository.SourceMemoryRepository)(nil)
+	_ APIKeyRepository    = (*repository.APIKeyRepository)(nil)
+	_ APIKeyRepository    = (*repository.APIKeyMemoryRepository)(nil)
+	_ AdminRepository     = (*repository.AdminRepository)(nil)
+	_ AdminRepository     = (*repository.AdminMemoryRepository)(nil)
+	_ AuditRepository     = (*repository.AuditRepository)(nil)
+	_ AuditRepository     = (*repository.AuditMemoryRepository)(nil)
+	_ LinkRepository      = (*repository.LinkRepository)(nil)
+	_ LinkRepository      = (*repository.LinkMemoryRepository)(nil)
+	_ BroadcastRepository = (*repository.BroadcastRepository)(nil)
+	_ BroadcastRepository = (*repository.BroadcastMemoryRepository)(nil)
+	_ DripRepository      = (*repository.DripRepository)(nil)
+	_ DripRepository      = (*repository.DripMemoryRepository)(nil)
+	_ TelegramSender      = (*TelegramService)(nil)
+)
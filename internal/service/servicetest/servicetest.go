@@ -0,0 +1,296 @@
+// Package servicetest wires the services to the in-memory repositories and fakes of the external dependencies
+package servicetest
+
+import (
+	"context"
+	"database/sql"
+	"hr-server/config"
+	"hr-server/internal/domain"
+	"hr-server/internal/repository"
+	"hr-server/internal/service"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	AuthToken = "test-auth-token-0123456789"
+	JWTSecret = "test-jwt-secret-0123456789abcdef0123456789"
+	BotToken  = "123456:test-bot-token"
+	BotURL    = "https://t.me/test_bot"
+
+	// Link tracking is disabled by Config, tests enable it with these
+	TrackingBaseURL = "https://go.example.com"
+	TrackingSecret  = "test-tracking-secret-0123456789abcdef"
+)
+
+// Config returns a valid config for tests
+func Config() *config.Config {
+	cfg := &config.Config{}
+	cfg.Environment = "test"
+	cfg.AuthToken = AuthToken
+	cfg.Admin.JWTSecret = JWTSecret
+	cfg.Admin.AccessTokenTTL = 15 * time.Minute
+	cfg.Admin.RefreshTokenTTL = 24 * time.Hour
+	cfg.TgBot.Token = BotToken
+	cfg.TgBot.URL = BotURL
+	cfg.Logger.LOGLVL = "info"
+	cfg.Logger.MaxBodySize = 4096
+	cfg.Notifications.WorkerCount = 5
+	cfg.Notifications.BatchSize = 20
+	cfg.Notifications.MessageInterval = 100 * time.Millisecond
+	cfg.Drip.PollInterval = time.Minute
+	cfg.Drip.BatchSize = 100
+	cfg.QuietHours.Start = "21:00"
+	cfg.QuietHours.End = "09:00"
+	cfg.QuietHours.DefaultTimezone = "Europe/Moscow"
+
+	return cfg
+}
+
+// Services are the services of the app backed by a single in-memory database
+type Services struct {
+	DB       *repository.MemoryDB
+	Telegram *TelegramSender
+	Database *DatabasePinger
+	Bot      *BotState
+
+	User         *service.UserService
+	Channel      *service.ChannelService
+	Campaign     *service.CampaignService
+	Source       *service.SourceService
+	Notification *service.NotificationService
+	Link         *service.LinkService
+	Drip         *service.DripService
+	APIKey       *service.APIKeyService
+	Admin        *service.AdminService
+	Audit        *service.AuditService
+	Health       *service.HealthService
+}
+
+func NewServices(cfg *config.Config) *Services {
+	db := repository.NewMemoryDB()
+
+	userRepository := repository.NewUserMemoryRepository(db)
+	channelRepository := repository.NewChannelMemoryRepository(db)
+	campaignRepository := repository.NewCampaignMemoryRepository(db)
+	sourceRepository := repository.NewSourceMemoryRepository(db)
+
+	s := &Services{
+		DB:       db,
+		Telegram: &TelegramSender{},
+		Database: &DatabasePinger{},
+		Bot:      &BotState{},
+	}
+	s.Bot.SetRunning(true)
+
+	s.Channel = service.NewChannelService(cfg, channelRepository, campaignRepository, sourceRepository)
+	s.Campaign = service.NewCampaignService(campaignRepository)
+	s.Source = service.NewSourceService(sourceRepository)
+	s.APIKey = service.NewAPIKeyService(cfg, repository.NewAPIKeyMemoryRepository(db))
+	s.Admin = service.NewAdminService(cfg, repository.NewAdminMemoryRepository(db))
+	s.Audit = service.NewAuditService(repository.NewAuditMemoryRepository(db))
+	s.User = service.NewUserService(userRepository, s.Channel)
+	s.Link = service.NewLinkService(cfg, repository.NewLinkMemoryRepository(db))
+	s.Notification = service.NewNotificationService(cfg, userRepository, repository.NewBroadcastMemoryRepository(db), s.Telegram, s.Link)
+	s.Drip = service.NewDripService(cfg, repository.NewDripMemoryRepository(db), userRepository, s.Channel, s.Telegram)
+	s.Health = service.NewHealthService(s.Database, s.Bot, s.Notification)
+
+	return s
+}
+
+// AddUser registers the user as if they started the bot and fails the test on error
+func (s *Services) AddUser(t testing.TB, telegramID int64, username string, channelID *int) {
+	t.Helper()
+
+	if _, _, err := s.User.CreateUser(t.Context(), telegramID, username, "", channelID); err != nil {
+		t.Fatalf("failed to add user %d: %v", telegramID, err)
+	}
+}
+
+// RunNotifications starts the notification workers until the end of the test and waits until they are running
+func (s *Services) RunNotifications(t testing.TB) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go s.Notification.Run(ctx, &wg)
+
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	// The readiness check reports the workers once they are started
+	deadline := time.Now().Add(5 * time.Second)
+	for !s.Notification.State().Running {
+		if time.Now().After(deadline) {
+			t.Fatal("notification workers are not started in 5s")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// WaitForBroadcasts waits until the running broadcasts are finished and fails the test on timeout
+func (s *Services) WaitForBroadcasts(t testing.TB, timeout time.Duration) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for s.Notification.State().RunningBroadcasts > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("broadcasts are not finished in %s", timeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// SentMessage is a message passed to the fake Telegram sender
+type SentMessage struct {
+	ChatID   int64
+	Text     string // text of a message or caption of a photo
+	PhotoURL string
+
+	CallbackData string // data of the inline button, empty without one
+	ButtonURL    string // link of the inline button, empty without one
+}
+
+// TelegramSender records the sent messages instead of calling Telegram
+type TelegramSender struct {
+	mu     sync.Mutex
+	sent   []SentMessage
+	errors map[int64]error
+
+	delay         time.Duration
+	sending       int
+	maxConcurrent int
+}
+
+// SetDelay makes every send take the duration, so concurrent sends can be observed
+func (f *TelegramSender) SetDelay(delay time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.delay = delay
+}
+
+// MaxConcurrent returns the largest number of sends made at once
+func (f *TelegramSender) MaxConcurrent() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.maxConcurrent
+}
+
+// FailFor makes sending to the chat fail with the error
+func (f *TelegramSender) FailFor(chatID int64, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.errors == nil {
+		f.errors = make(map[int64]error)
+	}
+	f.errors[chatID] = err
+}
+
+func (f *TelegramSender) SendMessage(ctx context.Context, chatID int64, message tgbotapi.Chattable) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.sending++
+	f.maxConcurrent = max(f.maxConcurrent, f.sending)
+	delay := f.delay
+	f.mu.Unlock()
+
+	time.Sleep(delay)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.sending--
+	if err := f.errors[chatID]; err != nil {
+		return err
+	}
+
+	sent := SentMessage{ChatID: chatID}
+	var replyMarkup interface{}
+	switch message := message.(type) {
+	case tgbotapi.MessageConfig:
+		sent.Text = message.Text
+		replyMarkup = message.ReplyMarkup
+	case tgbotapi.PhotoConfig:
+		sent.Text = message.Caption
+		replyMarkup = message.ReplyMarkup
+		if url, ok := message.File.(tgbotapi.FileURL); ok {
+			sent.PhotoURL = string(url)
+		}
+	}
+	if keyboard, ok := replyMarkup.(tgbotapi.InlineKeyboardMarkup); ok {
+		button := keyboard.InlineKeyboard[0][0]
+		if button.CallbackData != nil {
+			sent.CallbackData = *button.CallbackData
+		}
+		if button.URL != nil {
+			sent.ButtonURL = *button.URL
+		}
+	}
+	f.sent = append(f.sent, sent)
+
+	return nil
+}
+
+// Sent returns the successfully sent messages
+func (f *TelegramSender) Sent() []SentMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]SentMessage{}, f.sent...)
+}
+
+// DatabasePinger reports the configured error on ping
+type DatabasePinger struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (f *DatabasePinger) SetError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.err = err
+}
+
+func (f *DatabasePinger) PingContext(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.err
+}
+
+func (f *DatabasePinger) Stats() sql.DBStats {
+	return sql.DBStats{}
+}
+
+// BotState reports the configured state of the updates loop
+type BotState struct {
+	mu    sync.Mutex
+	state domain.BotState
+}
+
+func (f *BotState) SetRunning(running bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.state.Running = running
+}
+
+func (f *BotState) State() domain.BotState {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.state
+}
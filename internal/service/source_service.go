@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hr-server/internal/domain"
+	"hr-server/internal/repository"
+)
+
+var ErrSourceExists = errors.New("source with this name already exists")
+
+type SourceService struct {
+	sourceRepo SourceRepository
+}
+
+func NewSourceService(sourceRepo SourceRepository) *SourceService {
+	return &SourceService{
+		sourceRepo: sourceRepo,
+	}
+}
+
+func (s *SourceService) CreateSource(ctx context.Context, name string) (*domain.Source, error) {
+	source, err := s.sourceRepo.Create(ctx, name)
+	if repository.IsDuplicate(err) {
+		return nil, ErrSourceExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create source: %w", err)
+	}
+
+	return source, nil
+}
+
+func (s *SourceService) GetAll(ctx context.Context) ([]*domain.Source, error) {
+	return s.sourceRepo.GetAll(ctx)
+}
+
+func (s *SourceService) GetStats(ctx context.Context) ([]*domain.GroupStats, error) {
+	return s.sourceRepo.GetStats(ctx)
+}
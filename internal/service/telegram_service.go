@@ -2,20 +2,53 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"hr-server/config"
+	"hr-server/internal/domain"
+	"hr-server/internal/metrics"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	defaultCodeExpiredMessage = "Срок действия этой ссылки истёк, но ты всё равно можешь играть!"
+	defaultCodeFullMessage    = "Лимит участников по этой ссылке исчерпан, но ты всё равно можешь играть!"
+	defaultWelcomeMessage     = "Жми на Играть, запускай игру и забирай приз!"
+	defaultWelcomeBackMessage = "С возвращением! Жми на Играть и продолжай игру!"
+)
+
+// CallbackHandler handles the presses of inline buttons, implemented by NotificationService
+type CallbackHandler interface {
+	HandleCallback(ctx context.Context, telegramID int64, data string) error
+}
+
+// DripEnroller enrolls the new users into the drip sequences and records their replies, implemented by DripService
+type DripEnroller interface {
+	Enroll(ctx context.Context, telegramID int64, channelID *int) error
+	RecordReply(ctx context.Context, telegramID int64) error
+}
+
 type TelegramService struct {
-	bot            *tgbotapi.BotAPI
-	userService    *UserService
-	channelService *ChannelService
-	webAppURL      string
+	bot                *tgbotapi.BotAPI
+	userService        *UserService
+	channelService     *ChannelService
+	callbackHandler    CallbackHandler
+	dripEnroller       DripEnroller
+	webAppURL          string
+	codeExpiredMessage string
+	codeFullMessage    string
+	welcomeMessage     string
+	welcomeBackMessage string
+
+	running      atomic.Bool
+	startedAt    atomic.Int64 // unix nanoseconds, 0 if the loop hasn't started
+	lastUpdateAt atomic.Int64 // unix nanoseconds, 0 if no update was received
 }
 
 func NewTelegramService(
@@ -28,17 +61,55 @@ func NewTelegramService(
 		return nil, fmt.Errorf("failed to create telegram bot: %w", err)
 	}
 
+	codeExpiredMessage := cfg.TgBot.CodeExpiredMessage
+	if codeExpiredMessage == "" {
+		codeExpiredMessage = defaultCodeExpiredMessage
+	}
+
+	codeFullMessage := cfg.TgBot.CodeFullMessage
+	if codeFullMessage == "" {
+		codeFullMessage = defaultCodeFullMessage
+	}
+
+	welcomeMessage := cfg.TgBot.WelcomeMessage
+	if welcomeMessage == "" {
+		welcomeMessage = defaultWelcomeMessage
+	}
+
+	welcomeBackMessage := cfg.TgBot.WelcomeBackMessage
+	if welcomeBackMessage == "" {
+		welcomeBackMessage = defaultWelcomeBackMessage
+	}
+
 	return &TelegramService{
-		bot:            bot,
-		userService:    userService,
-		channelService: channelService,
-		webAppURL:      cfg.TgBot.URL + "?startapp",
+		bot:                bot,
+		userService:        userService,
+		channelService:     channelService,
+		webAppURL:          cfg.TgBot.URL + "?startapp",
+		codeExpiredMessage: codeExpiredMessage,
+		codeFullMessage:    codeFullMessage,
+		welcomeMessage:     welcomeMessage,
+		welcomeBackMessage: welcomeBackMessage,
 	}, nil
 }
 
+// SetCallbackHandler sets the handler of the inline button presses, it must be called before Run
+func (t *TelegramService) SetCallbackHandler(handler CallbackHandler) {
+	t.callbackHandler = handler
+}
+
+// SetDripEnroller sets the enroller of the new users into the drip sequences, it must be called before Run
+func (t *TelegramService) SetDripEnroller(enroller DripEnroller) {
+	t.dripEnroller = enroller
+}
+
 func (t *TelegramService) Run(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
 
+	t.startedAt.Store(time.Now().UnixNano())
+	t.running.Store(true)
+	defer t.running.Store(false)
+
 	logrus.Info("telegram bot started")
 
 	u := tgbotapi.NewUpdate(0)
@@ -50,23 +121,61 @@ func (t *TelegramService) Run(ctx context.Context, wg *sync.WaitGroup) {
 		case <-ctx.Done():
 			logrus.Info("telegram bot stopped")
 			return
-		case update := <-updates:
+		case update, ok := <-updates:
+			if !ok {
+				logrus.Error("telegram updates channel closed, bot stopped")
+				return
+			}
+
+			t.lastUpdateAt.Store(time.Now().UnixNano())
+
+			if update.CallbackQuery != nil {
+				metrics.BotUpdateProcessed(metrics.BotUpdateCallback)
+				t.handleCallbackQuery(ctx, update.CallbackQuery)
+				continue
+			}
+
 			if update.Message == nil {
+				metrics.BotUpdateProcessed(metrics.BotUpdateOther)
 				continue
 			}
 
 			if update.Message.IsCommand() {
+				metrics.BotUpdateProcessed(metrics.BotUpdateCommand)
+			} else {
+				metrics.BotUpdateProcessed(metrics.BotUpdateMessage)
+				t.recordReply(ctx, update.Message.From)
+			}
+
+			log := logrus.WithFields(logrus.Fields{
+				"update_id": update.UpdateID,
+				"chat_id":   update.Message.Chat.ID,
+			})
+
+			if update.Message.IsCommand() {
+				// The greeting for new users is also sent if the registration failed
+				msgText := t.welcomeMessage
+
 				switch update.Message.Command() {
 				case "start":
-					if err := t.handleStartCommand(update.Message); err != nil {
-						logrus.Errorf("failed to handle start command: %v", err)
+					fallbackReply, created, err := t.handleStartCommand(ctx, update.Message)
+					if err != nil {
+						log.Errorf("failed to handle start command: %v", err)
+					} else if !created {
+						msgText = t.welcomeBackMessage
+					}
+
+					if fallbackReply != "" {
+						fallbackMsg := tgbotapi.NewMessage(update.Message.Chat.ID, fallbackReply)
+						if err := t.SendMessage(ctx, update.Message.Chat.ID, fallbackMsg); err != nil {
+							log.Errorf("failed to send fallback msg: %v", err)
+						}
 					}
 				default:
 					continue
 				}
 
 				chatID := update.Message.Chat.ID
-				msgText := "Жми на Играть, запускай игру и забирай приз!"
 				msg := tgbotapi.NewMessage(chatID, msgText)
 
 				if t.webAppURL != "" {
@@ -77,44 +186,129 @@ func (t *TelegramService) Run(ctx context.Context, wg *sync.WaitGroup) {
 					msg.ReplyMarkup = keyboard
 				}
 
-				if _, err := t.bot.Send(msg); err != nil {
-					logrus.Errorf("failed to send msg: %v", err)
+				if err := t.SendMessage(ctx, chatID, msg); err != nil {
+					log.Errorf("failed to send msg: %v", err)
 				}
 			}
 		}
 	}
 }
 
-func (t *TelegramService) handleStartCommand(message *tgbotapi.Message) error {
+// handleStartCommand registers the user and returns a fallback reply if the channel code of a new user
+// is expired or full and whether the user is new. Such users are registered without channel attribution.
+func (t *TelegramService) handleStartCommand(ctx context.Context, message *tgbotapi.Message) (string, bool, error) {
 	telegramID := message.From.ID
 	username := message.From.UserName
+	firstName := message.From.FirstName
 
 	args := strings.Fields(message.Text)
 	var channelID *int
+	var fallbackReply string
 
 	if len(args) > 1 {
 		channelCode := args[1]
 
 		if channelCode != "" {
-			channel, err := t.channelService.GetChannelByCode(channelCode)
+			channel, err := t.channelService.GetChannelByCode(ctx, channelCode)
 			if err != nil {
-				return fmt.Errorf("failed to get channel by code %s: %v", channelCode, err)
+				return "", false, fmt.Errorf("failed to get channel by code %s: %v", channelCode, err)
 			}
 
+			// The cap is also enforced by the registration, concurrent starts may fill the channel meanwhile
 			if channel != nil {
-				channelID = &channel.ID
+				switch err := t.channelService.CheckAvailability(channel); {
+				case errors.Is(err, ErrChannelExpired):
+					fallbackReply = t.codeExpiredMessage
+				case errors.Is(err, ErrChannelFull):
+					fallbackReply = t.codeFullMessage
+				default:
+					channelID = &channel.ID
+				}
 			}
 		}
 	}
 
-	if err := t.userService.CreateUser(telegramID, username, channelID); err != nil {
-		return fmt.Errorf("failed to create user %d: %v", telegramID, err)
+	created, full, err := t.userService.CreateUser(ctx, telegramID, username, firstName, channelID)
+	if err != nil {
+		return fallbackReply, false, fmt.Errorf("failed to create user %d: %v", telegramID, err)
+	}
+
+	// The registered users keep their attribution, so the code doesn't matter to them
+	if !created {
+		return "", false, nil
+	}
+	if full {
+		fallbackReply = t.codeFullMessage
+		channelID = nil
+	}
+
+	// Drip sequences start at the first /start only
+	if t.dripEnroller != nil {
+		if err := t.dripEnroller.Enroll(ctx, telegramID, channelID); err != nil {
+			return fallbackReply, true, fmt.Errorf("failed to enroll user %d into drip sequences: %v", telegramID, err)
+		}
+	}
+
+	return fallbackReply, true, nil
+}
+
+// handleCallbackQuery passes the button press to the handler and answers it, so the client stops the loading animation
+func (t *TelegramService) handleCallbackQuery(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	log := logrus.WithFields(logrus.Fields{
+		"callback_id": query.ID,
+		"telegram_id": query.From.ID,
+	})
+
+	if t.callbackHandler != nil {
+		if err := t.callbackHandler.HandleCallback(ctx, query.From.ID, query.Data); err != nil {
+			log.Warnf("failed to handle callback: %v", err)
+		}
+	}
+
+	t.recordReply(ctx, query.From)
+
+	if _, err := t.bot.Request(tgbotapi.NewCallback(query.ID, "")); err != nil {
+		log.Errorf("failed to answer callback: %v", err)
+	}
+}
+
+// recordReply passes a message or a button press of the user to the drip sequences conditions
+func (t *TelegramService) recordReply(ctx context.Context, from *tgbotapi.User) {
+	if t.dripEnroller == nil || from == nil {
+		return
+	}
+
+	if err := t.dripEnroller.RecordReply(ctx, from.ID); err != nil {
+		logrus.WithField("telegram_id", from.ID).Errorf("failed to record reply: %v", err)
 	}
+}
+
+// State returns the state of the updates loop for readiness checks
+func (t *TelegramService) State() domain.BotState {
+	return domain.BotState{
+		Running:      t.running.Load(),
+		StartedAt:    unixNanoTime(t.startedAt.Load()),
+		LastUpdateAt: unixNanoTime(t.lastUpdateAt.Load()),
+	}
+}
 
-	return nil
+func unixNanoTime(nanos int64) *time.Time {
+	if nanos == 0 {
+		return nil
+	}
+
+	t := time.Unix(0, nanos)
+	return &t
 }
 
-func (t *TelegramService) SendMessage(chatID int64, message tgbotapi.Chattable) error {
+// SendMessage sends the message unless the context is done, the bot API client doesn't take a context
+// so a send that has started isn't interrupted
+func (t *TelegramService) SendMessage(ctx context.Context, chatID int64, message tgbotapi.Chattable) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	_, err := t.bot.Send(message)
+	metrics.ObserveTelegramSend(err)
 	return err
 }
@@ -1,55 +1,325 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"hr-server/internal/domain"
-	"hr-server/internal/repository"
+	"hr-server/internal/metrics"
+	"strings"
+)
+
+var (
+	ErrUserNotFound    = errors.New("user not found")
+	ErrChannelNotFound = errors.New("channel not found")
 )
 
 type UserService struct {
-	userRepo *repository.UserRepository
+	userRepo       UserRepository
+	channelService *ChannelService
 }
 
-func NewUserService(userRepo *repository.UserRepository) *UserService {
+func NewUserService(userRepo UserRepository, channelService *ChannelService) *UserService {
 	return &UserService{
-		userRepo: userRepo,
+		userRepo:       userRepo,
+		channelService: channelService,
 	}
 }
 
+// CreateUser registers the user who started the bot and returns true if the user is new.
+// A returning user keeps the first channel attribution and is reactivated if blocked,
+// the user came back to the bot, so messages can be delivered again. A new user isn't attributed
+// to a channel that reached max_users, the second result reports it.
 func (s *UserService) CreateUser(
+	ctx context.Context,
 	telegramID int64,
 	username string,
+	firstName string,
 	channelID *int,
-) error {
-	existingUser, err := s.userRepo.GetByTelegramID(telegramID)
+) (bool, bool, error) {
+	created, full, err := s.userRepo.Upsert(ctx, telegramID, username, firstName, channelID)
 	if err != nil {
-		return fmt.Errorf("failed to check existing user: %w", err)
+		return false, false, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	if existingUser != nil {
-		return nil
+	if created {
+		metrics.UserSignedUp(channelID != nil && !full)
+	}
+
+	return created, full, nil
+}
+
+func (s *UserService) GetUser(ctx context.Context, telegramID int64) (*domain.User, error) {
+	return s.userRepo.GetByTelegramID(ctx, telegramID)
+}
+
+// GetUserWithChannel returns the user with channel information, nil if the user doesn't exist
+func (s *UserService) GetUserWithChannel(ctx context.Context, telegramID int64) (*domain.UserWithChannel, error) {
+	user, err := s.userRepo.GetWithChannelByTelegramID(ctx, telegramID)
+	if err != nil || user == nil {
+		return nil, err
 	}
 
-	err = s.userRepo.Create(telegramID, username, channelID)
+	s.withChannelLink(user)
+
+	return user, nil
+}
+
+// UpdateUser applies the admin changes to the user and returns the updated user
+func (s *UserService) UpdateUser(ctx context.Context, telegramID int64, update domain.UserUpdate) (*domain.UserWithChannel, error) {
+	if update.ChannelID != nil {
+		channel, err := s.channelService.GetChannelByID(ctx, *update.ChannelID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get channel: %w", err)
+		}
+		if channel == nil {
+			return nil, fmt.Errorf("channel %d: %w", *update.ChannelID, ErrChannelNotFound)
+		}
+	}
+
+	found, err := s.userRepo.Update(ctx, telegramID, update)
 	if err != nil {
-		return fmt.Errorf("failed to create user: %w", err)
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	if !found {
+		return nil, ErrUserNotFound
+	}
+
+	return s.GetUserWithChannel(ctx, telegramID)
+}
+
+func (s *UserService) DeleteUser(ctx context.Context, telegramID int64) error {
+	found, err := s.userRepo.Delete(ctx, telegramID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	if !found {
+		return ErrUserNotFound
 	}
 
 	return nil
 }
 
-func (s *UserService) GetUser(telegramID int64) (*domain.User, error) {
-	return s.userRepo.GetByTelegramID(telegramID)
+// EraseUser deletes or anonymizes the user and all related records on a right-to-erasure request
+func (s *UserService) EraseUser(ctx context.Context, telegramID int64, mode, reason, requestedBy string) (*domain.UserErasure, error) {
+	erasure, err := s.userRepo.Erase(ctx, telegramID, &domain.UserErasure{
+		Mode:        mode,
+		Reason:      reason,
+		RequestedBy: requestedBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to erase user: %w", err)
+	}
+	if erasure == nil {
+		return nil, ErrUserNotFound
+	}
+
+	return erasure, nil
+}
+
+func (s *UserService) GetAllUsers(ctx context.Context) ([]*domain.User, error) {
+	return s.userRepo.GetAll(ctx)
+}
+
+func (s *UserService) GetUsersByChannel(ctx context.Context, channelID int) ([]*domain.User, error) {
+	return s.userRepo.GetByChannel(ctx, channelID)
 }
 
-func (s *UserService) GetAllUsers() ([]*domain.User, error) {
-	return s.userRepo.GetAll()
+func (s *UserService) GetUsersPage(
+	ctx context.Context,
+	filter domain.UserFilter,
+	page domain.PageRequest,
+) (*domain.Page[*domain.UserWithChannel], error) {
+	users, err := s.userRepo.GetPageWithChannel(ctx, filter, page)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users.Items {
+		s.withChannelLink(user)
+	}
+
+	return users, nil
 }
 
-func (s *UserService) GetUsersByChannel(channelID int) ([]*domain.User, error) {
-	return s.userRepo.GetByChannel(channelID)
+// ExportUsers iterates over users with channel information matching the filter in batches
+func (s *UserService) ExportUsers(
+	ctx context.Context,
+	filter domain.UserFilter,
+	batchSize int,
+	callback func([]*domain.UserWithChannel) error,
+) error {
+	return s.userRepo.GetAllWithChannelInBatches(ctx, filter, batchSize, func(users []*domain.UserWithChannel) error {
+		for _, user := range users {
+			s.withChannelLink(user)
+		}
+
+		return callback(users)
+	})
 }
 
-func (s *UserService) GetAllUsersWithChannel() ([]*domain.UserWithChannel, error) {
-	return s.userRepo.GetAllWithChannel()
+// ImportUsers validates the rows, resolves their channels and upserts them by Telegram ID.
+// In dry-run mode nothing is written and the report tells what would happen.
+func (s *UserService) ImportUsers(ctx context.Context, rows []*domain.UserImportRow, dryRun bool) *domain.UserImportReport {
+	report := &domain.UserImportReport{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Rows:   make([]*domain.UserImportRowResult, 0, len(rows)),
+	}
+
+	resolver := newImportChannelResolver(s.channelService)
+	seen := make(map[int64]bool, len(rows))
+
+	for _, row := range rows {
+		result := &domain.UserImportRowResult{Row: row.Row, TelegramID: row.TelegramID}
+		report.Rows = append(report.Rows, result)
+
+		action, channelID, err := s.importRow(ctx, row, resolver, seen, dryRun)
+		if err != nil {
+			result.Action = domain.ImportActionFailed
+			result.Error = err.Error()
+			report.Failed++
+			continue
+		}
+
+		result.Action = action
+		result.ChannelID = channelID
+		seen[row.TelegramID] = true
+
+		if action == domain.ImportActionCreated {
+			report.Created++
+		} else {
+			report.Updated++
+		}
+	}
+
+	return report
+}
+
+func (s *UserService) importRow(
+	ctx context.Context,
+	row *domain.UserImportRow,
+	resolver *importChannelResolver,
+	seen map[int64]bool,
+	dryRun bool,
+) (string, *int, error) {
+	if len(row.ParseErrors) > 0 {
+		return "", nil, errors.New(strings.Join(row.ParseErrors, "; "))
+	}
+
+	channelID, err := resolver.resolve(ctx, row)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if dryRun {
+		if seen[row.TelegramID] {
+			return domain.ImportActionUpdated, channelID, nil
+		}
+
+		existingUser, err := s.userRepo.GetByTelegramID(ctx, row.TelegramID)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to check existing user: %w", err)
+		}
+
+		if existingUser != nil {
+			return domain.ImportActionUpdated, channelID, nil
+		}
+
+		return domain.ImportActionCreated, channelID, nil
+	}
+
+	user := &domain.User{
+		TelegramID: row.TelegramID,
+		Username:   row.Username,
+		ChannelID:  channelID,
+	}
+	if row.CreatedAt != nil {
+		user.CreatedAt = *row.CreatedAt
+	}
+
+	created, err := s.userRepo.UpsertImported(ctx, user)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if created {
+		return domain.ImportActionCreated, channelID, nil
+	}
+
+	return domain.ImportActionUpdated, channelID, nil
+}
+
+// importChannelResolver resolves and caches the channels referenced by import rows
+type importChannelResolver struct {
+	channelService *ChannelService
+	byID           map[int]bool
+	byRef          map[string]*int
+}
+
+func newImportChannelResolver(channelService *ChannelService) *importChannelResolver {
+	return &importChannelResolver{
+		channelService: channelService,
+		byID:           make(map[int]bool),
+		byRef:          make(map[string]*int),
+	}
+}
+
+func (r *importChannelResolver) resolve(ctx context.Context, row *domain.UserImportRow) (*int, error) {
+	if row.ChannelID != nil {
+		exists, ok := r.byID[*row.ChannelID]
+		if !ok {
+			channel, err := r.channelService.GetChannelByID(ctx, *row.ChannelID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get channel: %w", err)
+			}
+
+			exists = channel != nil
+			r.byID[*row.ChannelID] = exists
+		}
+
+		if !exists {
+			return nil, fmt.Errorf("channel %d: %w", *row.ChannelID, ErrChannelNotFound)
+		}
+
+		return row.ChannelID, nil
+	}
+
+	if row.ChannelRef == "" {
+		return nil, nil
+	}
+
+	channelID, ok := r.byRef[row.ChannelRef]
+	if !ok {
+		channel, err := r.channelService.ResolveChannel(ctx, row.ChannelRef)
+		if err != nil {
+			return nil, err
+		}
+
+		if channel != nil {
+			channelID = &channel.ID
+		}
+		r.byRef[row.ChannelRef] = channelID
+	}
+
+	if channelID == nil {
+		return nil, fmt.Errorf("channel '%s': %w", row.ChannelRef, ErrChannelNotFound)
+	}
+
+	return channelID, nil
+}
+
+// withChannelLink fills the channel link of the user if the user came from a channel
+func (s *UserService) withChannelLink(user *domain.UserWithChannel) {
+	if user.ChannelCode == nil {
+		return
+	}
+
+	linkType := ""
+	if user.ChannelLinkType != nil {
+		linkType = *user.ChannelLinkType
+	}
+
+	link := s.channelService.BuildLink(*user.ChannelCode, linkType)
+	user.ChannelLink = &link
 }
@@ -0,0 +1,324 @@
+package service_test
+
+import (
+	"hr-server/internal/domain"
+	"hr-server/internal/service"
+	"hr-server/internal/service/servicetest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserService_CreateUser(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+
+	channel, err := s.Channel.GenerateChannel(t.Context(), "hh.ru", domain.ChannelAttributes{})
+	require.NoError(t, err)
+
+	created, _, err := s.User.CreateUser(t.Context(), 1, "alice", "Alice", &channel.ID)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	user, err := s.User.GetUser(t.Context(), 1)
+	require.NoError(t, err)
+	require.NotNil(t, user)
+	assert.Equal(t, "alice", user.Username)
+	assert.Equal(t, "Alice", user.FirstName)
+	assert.Equal(t, &channel.ID, user.ChannelID)
+	assert.Equal(t, domain.UserStatusActive, user.Status)
+	require.NotNil(t, user.LastSeenAt)
+	firstSeenAt := *user.LastSeenAt
+
+	t.Run("existing user keeps the first attribution", func(t *testing.T) {
+		created, _, err := s.User.CreateUser(t.Context(), 1, "alice_new", "Alicia", nil)
+		require.NoError(t, err)
+		assert.False(t, created)
+
+		user, err := s.User.GetUser(t.Context(), 1)
+		require.NoError(t, err)
+		assert.Equal(t, &channel.ID, user.ChannelID)
+		assert.Equal(t, "alice_new", user.Username)
+		assert.Equal(t, "Alicia", user.FirstName)
+		require.NotNil(t, user.LastSeenAt)
+		assert.False(t, user.LastSeenAt.Before(firstSeenAt))
+	})
+
+	t.Run("blocked user is reactivated", func(t *testing.T) {
+		blocked := domain.UserStatusBlocked
+		_, err := s.User.UpdateUser(t.Context(), 1, domain.UserUpdate{Status: &blocked})
+		require.NoError(t, err)
+
+		created, _, err := s.User.CreateUser(t.Context(), 1, "alice", "Alice", nil)
+		require.NoError(t, err)
+		assert.False(t, created)
+
+		user, err := s.User.GetUser(t.Context(), 1)
+		require.NoError(t, err)
+		assert.Equal(t, domain.UserStatusActive, user.Status)
+	})
+
+	t.Run("concurrent starts create the user once", func(t *testing.T) {
+		var wg sync.WaitGroup
+		var createdCount atomic.Int64
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				created, _, err := s.User.CreateUser(t.Context(), 2, "bob", "", nil)
+				assert.NoError(t, err)
+				if created {
+					createdCount.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int64(1), createdCount.Load())
+	})
+}
+
+func TestUserService_CreateUserChannelCap(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+
+	maxUsers := 3
+	channel, err := s.Channel.GenerateChannel(t.Context(), "hh.ru", domain.ChannelAttributes{MaxUsers: &maxUsers})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	var fullCount atomic.Int64
+	for id := int64(1); id <= 10; id++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			created, full, err := s.User.CreateUser(t.Context(), id, "user", "", &channel.ID)
+			assert.NoError(t, err)
+			assert.True(t, created)
+			if full {
+				fullCount.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(7), fullCount.Load(), "concurrent starts don't exceed max_users")
+
+	attributed, err := s.User.GetUsersPage(t.Context(), domain.UserFilter{ChannelID: &channel.ID}, domain.PageRequest{})
+	require.NoError(t, err)
+	assert.EqualValues(t, maxUsers, attributed.TotalCount)
+
+	created, full, err := s.User.CreateUser(t.Context(), 1, "user", "", &channel.ID)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.False(t, full, "a registered user isn't reported full")
+}
+
+func TestUserService_GetUserWithChannel(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+
+	channel, err := s.Channel.GenerateChannel(t.Context(), "hh.ru", domain.ChannelAttributes{})
+	require.NoError(t, err)
+	s.AddUser(t, 1, "alice", &channel.ID)
+	s.AddUser(t, 2, "bob", nil)
+
+	user, err := s.User.GetUserWithChannel(t.Context(), 1)
+	require.NoError(t, err)
+	require.NotNil(t, user.ChannelName)
+	assert.Equal(t, "hh.ru", *user.ChannelName)
+	require.NotNil(t, user.ChannelLink)
+	assert.Equal(t, servicetest.BotURL+"?start="+channel.Code, *user.ChannelLink)
+
+	user, err = s.User.GetUserWithChannel(t.Context(), 2)
+	require.NoError(t, err)
+	assert.Nil(t, user.ChannelName)
+	assert.Nil(t, user.ChannelLink)
+
+	user, err = s.User.GetUserWithChannel(t.Context(), 3)
+	require.NoError(t, err)
+	assert.Nil(t, user)
+}
+
+func TestUserService_UpdateUser(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+
+	channel, err := s.Channel.GenerateChannel(t.Context(), "hh.ru", domain.ChannelAttributes{})
+	require.NoError(t, err)
+	s.AddUser(t, 1, "alice", nil)
+
+	user, err := s.User.UpdateUser(t.Context(), 1, domain.UserUpdate{ChannelID: &channel.ID})
+	require.NoError(t, err)
+	assert.Equal(t, &channel.ID, user.ChannelID)
+
+	user, err = s.User.UpdateUser(t.Context(), 1, domain.UserUpdate{ClearChannel: true})
+	require.NoError(t, err)
+	assert.Nil(t, user.ChannelID)
+
+	missingChannel := 100
+	_, err = s.User.UpdateUser(t.Context(), 1, domain.UserUpdate{ChannelID: &missingChannel})
+	assert.ErrorIs(t, err, service.ErrChannelNotFound)
+
+	_, err = s.User.UpdateUser(t.Context(), 2, domain.UserUpdate{ClearChannel: true})
+	assert.ErrorIs(t, err, service.ErrUserNotFound)
+}
+
+func TestUserService_DeleteUser(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+	s.AddUser(t, 1, "alice", nil)
+
+	require.NoError(t, s.User.DeleteUser(t.Context(), 1))
+	assert.ErrorIs(t, s.User.DeleteUser(t.Context(), 1), service.ErrUserNotFound)
+
+	user, err := s.User.GetUser(t.Context(), 1)
+	require.NoError(t, err)
+	assert.Nil(t, user)
+}
+
+func TestUserService_EraseUser(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+	channel, err := s.Channel.GenerateChannel(t.Context(), "personal invite", domain.ChannelAttributes{})
+	require.NoError(t, err)
+	s.AddUser(t, 1, "alice", &channel.ID)
+	s.AddUser(t, 2, "bob", nil)
+
+	erasure, err := s.User.EraseUser(t.Context(), 1, domain.ErasureModeAnonymize, "request", "admin")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ErasureModeAnonymize, erasure.Mode)
+
+	user, err := s.User.GetUser(t.Context(), 1)
+	require.NoError(t, err)
+	assert.Nil(t, user, "anonymized user can't be found by the Telegram ID")
+
+	erased, err := s.User.GetUsersPage(t.Context(), domain.UserFilter{Status: domain.UserStatusErased}, domain.PageRequest{})
+	require.NoError(t, err)
+	require.Len(t, erased.Items, 1)
+	assert.Empty(t, erased.Items[0].Username)
+	assert.Nil(t, erased.Items[0].ChannelID, "the channel attribution is removed")
+
+	_, err = s.User.EraseUser(t.Context(), 2, domain.ErasureModeDelete, "request", "admin")
+	require.NoError(t, err)
+
+	all, err := s.User.GetAllUsers(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+
+	_, err = s.User.EraseUser(t.Context(), 2, domain.ErasureModeDelete, "request", "admin")
+	assert.ErrorIs(t, err, service.ErrUserNotFound)
+}
+
+func TestUserService_GetUsersPage(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+
+	channel, err := s.Channel.GenerateChannel(t.Context(), "hh.ru", domain.ChannelAttributes{})
+	require.NoError(t, err)
+	for id, username := range []string{"alice", "bob", "carol", "dave", "erin"} {
+		var channelID *int
+		if id%2 == 0 {
+			channelID = &channel.ID
+		}
+		s.AddUser(t, int64(id+1), username, channelID)
+	}
+
+	t.Run("filters", func(t *testing.T) {
+		page, err := s.User.GetUsersPage(t.Context(), domain.UserFilter{ChannelID: &channel.ID}, domain.PageRequest{})
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, page.TotalCount)
+
+		page, err = s.User.GetUsersPage(t.Context(), domain.UserFilter{Username: "AR"}, domain.PageRequest{})
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		assert.Equal(t, "carol", page.Items[0].Username)
+	})
+
+	t.Run("cursor walks all pages", func(t *testing.T) {
+		request := domain.PageRequest{Limit: 2, SortBy: "username", SortOrder: domain.SortOrderAsc}
+
+		var usernames []string
+		for {
+			page, err := s.User.GetUsersPage(t.Context(), domain.UserFilter{}, request)
+			require.NoError(t, err)
+			assert.EqualValues(t, 5, page.TotalCount)
+
+			for _, user := range page.Items {
+				usernames = append(usernames, user.Username)
+			}
+
+			if page.NextCursor == "" {
+				break
+			}
+			request.Cursor = page.NextCursor
+		}
+
+		assert.Equal(t, []string{"alice", "bob", "carol", "dave", "erin"}, usernames)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		_, err := s.User.GetUsersPage(t.Context(), domain.UserFilter{}, domain.PageRequest{Cursor: "garbage"})
+		assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+	})
+}
+
+func TestUserService_ImportUsers(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+
+	channel, err := s.Channel.GenerateChannel(t.Context(), "hh.ru", domain.ChannelAttributes{})
+	require.NoError(t, err)
+	s.AddUser(t, 1, "alice", nil)
+
+	missingChannel := 100
+	rows := []*domain.UserImportRow{
+		{Row: 2, TelegramID: 1, Username: "alice", ChannelRef: "hh.ru"},
+		{Row: 3, TelegramID: 2, Username: "bob", ChannelRef: channel.Code},
+		{Row: 4, TelegramID: 3, Username: "carol", ChannelID: &missingChannel},
+		{Row: 5, TelegramID: 4, Username: "dave", ChannelRef: "unknown"},
+		{Row: 6, ParseErrors: []string{"telegram_id: must be a number"}},
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		report := s.User.ImportUsers(t.Context(), rows, true)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 1, report.Updated)
+		assert.Equal(t, 3, report.Failed)
+
+		user, err := s.User.GetUser(t.Context(), 2)
+		require.NoError(t, err)
+		assert.Nil(t, user, "dry run must not write")
+	})
+
+	t.Run("import", func(t *testing.T) {
+		report := s.User.ImportUsers(t.Context(), rows, false)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 1, report.Updated)
+		assert.Equal(t, 3, report.Failed)
+		assert.Equal(t, domain.ImportActionUpdated, report.Rows[0].Action)
+		assert.Equal(t, domain.ImportActionCreated, report.Rows[1].Action)
+		assert.Contains(t, report.Rows[2].Error, service.ErrChannelNotFound.Error())
+		assert.Contains(t, report.Rows[4].Error, "telegram_id")
+
+		user, err := s.User.GetUser(t.Context(), 1)
+		require.NoError(t, err)
+		assert.Equal(t, &channel.ID, user.ChannelID)
+
+		user, err = s.User.GetUser(t.Context(), 2)
+		require.NoError(t, err)
+		require.NotNil(t, user)
+		assert.Equal(t, "bob", user.Username)
+	})
+}
+
+func TestUserService_ExportUsers(t *testing.T) {
+	s := servicetest.NewServices(servicetest.Config())
+	for id := int64(1); id <= 5; id++ {
+		s.AddUser(t, id, "user", nil)
+	}
+
+	var batches []int
+	err := s.User.ExportUsers(t.Context(), domain.UserFilter{}, 2, func(users []*domain.UserWithChannel) error {
+		batches = append(batches, len(users))
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 2, 1}, batches)
+}